@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"noroi/internal/handler"
 	"noroi/internal/infrastructure/db"
@@ -26,8 +29,12 @@ func main() {
 
 	log.Println("Successfully connected to database")
 
+	// Background workers stop on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Initialize router
-	router := handler.NewRouter(dbConn)
+	router := handler.NewRouter(ctx, dbConn)
 
 	// Get port from environment variable
 	port := os.Getenv("PORT")
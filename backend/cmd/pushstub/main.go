@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"noroi/internal/infrastructure/webpush"
+)
+
+// pushstub is a local stand-in for a browser push service (FCM, autopush, ...).
+//
+// It plays both sides needed for manual testing of Web Push delivery:
+//
+//	GET  /subscriptions/new          create a subscription and print its PushSubscription JSON
+//	POST /push/:id                   receive a message, check the VAPID header and decrypt it
+//	POST /push/:id/status?code=410   make later deliveries to :id answer with the given status
+//
+// Run the API with WEBPUSH_ALLOW_INSECURE_ENDPOINTS=true so it accepts http://localhost endpoints.
+type subscription struct {
+	key        *ecdh.PrivateKey
+	authSecret []byte
+	status     int
+}
+
+type stub struct {
+	mu      sync.Mutex
+	baseURL string
+	subs    map[string]*subscription
+}
+
+func main() {
+	addr := flag.String("addr", ":8090", "listen address")
+	flag.Parse()
+
+	s := &stub{
+		baseURL: "http://" + *addr,
+		subs:    make(map[string]*subscription),
+	}
+	if strings.HasPrefix(*addr, ":") {
+		s.baseURL = "http://localhost" + *addr
+	}
+
+	http.HandleFunc("/subscriptions/new", s.newSubscription)
+	http.HandleFunc("/push/", s.push)
+
+	log.Printf("Push service stub listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		log.Fatalf("Failed to start push stub: %v", err)
+	}
+}
+
+func (s *stub) newSubscription(w http.ResponseWriter, r *http.Request) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	authSecret := make([]byte, 16)
+	if _, err := rand.Read(authSecret); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id := base64.RawURLEncoding.EncodeToString(authSecret[:8])
+
+	s.mu.Lock()
+	s.subs[id] = &subscription{key: key, authSecret: authSecret, status: http.StatusCreated}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = fmt.Fprintf(w, `{"endpoint":%q,"keys":{"p256dh":%q,"auth":%q}}`+"\n",
+		s.baseURL+"/push/"+id,
+		base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(authSecret),
+	)
+}
+
+func (s *stub) push(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/push/")
+	id, action, _ := strings.Cut(path, "/")
+
+	s.mu.Lock()
+	sub, ok := s.subs[id]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "subscription not found", http.StatusGone)
+		return
+	}
+
+	if action == "status" {
+		code, err := strconv.Atoi(r.URL.Query().Get("code"))
+		if err != nil {
+			http.Error(w, "invalid code", http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		sub.status = code
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "vapid t=") {
+		http.Error(w, "missing VAPID authorization", http.StatusUnauthorized)
+		return
+	}
+	if r.Header.Get("Content-Encoding") != "aes128gcm" {
+		http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 4096+1))
+	if err != nil || len(body) > 4096 {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	s.mu.Lock()
+	status := sub.status
+	s.mu.Unlock()
+	if status >= 300 {
+		log.Printf("[%s] rejecting message with status %d", id, status)
+		w.WriteHeader(status)
+		return
+	}
+
+	payload, err := webpush.Decrypt(body, sub.key, sub.authSecret)
+	if err != nil {
+		log.Printf("[%s] failed to decrypt message: %v", id, err)
+		http.Error(w, "decryption failed", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("[%s] TTL=%s Urgency=%s Topic=%s payload=%s",
+		id, r.Header.Get("TTL"), r.Header.Get("Urgency"), r.Header.Get("Topic"), payload)
+	w.WriteHeader(status)
+}
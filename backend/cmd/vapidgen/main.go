@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"noroi/internal/infrastructure/webpush"
+)
+
+// vapidgen prints a fresh VAPID key pair for the Web Push configuration.
+func main() {
+	publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		log.Fatalf("Failed to generate VAPID keys: %v", err)
+	}
+
+	fmt.Printf("WEBPUSH_VAPID_PUBLIC_KEY=%s\n", publicKey)
+	fmt.Printf("WEBPUSH_VAPID_PRIVATE_KEY=%s\n", privateKey)
+}
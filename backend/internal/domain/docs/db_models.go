@@ -25,43 +25,65 @@ type CompanyRecord struct {
 
 // applications テーブル（ユーザーごとの応募・保存単位）に対応
 type ApplicationRecord struct {
-	ID          uuid.UUID      `db:"id"`
-	UserID      uuid.UUID      `db:"user_id"`    // FK -> users.id
-	CompanyID   uuid.UUID      `db:"company_id"` // FK -> companies.id
-	Category    string         `db:"category"`   // enum: main | intern | info
-	Status      string         `db:"status"`     // enum: todo | scheduled | in_progress | done | withdrawn
-	ScheduledAt sql.NullTime   `db:"scheduled_at"`
-	ColorTag    string         `db:"color_tag"` // enum: orange | purple
-	Completed   bool           `db:"completed"`
-	Motivation  sql.NullString `db:"motivation"` // 志望理由
-	WhatToDo    sql.NullString `db:"what_to_do"` // 入社後にやりたいこと
-	JobAxis     sql.NullString `db:"job_axis"`   // 就活軸
-	Strengths   sql.NullString `db:"strengths"`  // 自分の強みがどう生きるか
-	CreatedAt   time.Time      `db:"created_at"`
-	UpdatedAt   time.Time      `db:"updated_at"`
+	ID                  uuid.UUID      `db:"id"`
+	UserID              uuid.UUID      `db:"user_id"`    // FK -> users.id
+	CompanyID           uuid.UUID      `db:"company_id"` // FK -> companies.id
+	Category            string         `db:"category"`   // enum: main | intern | info
+	Status              string         `db:"status"`     // enum: todo | scheduled | in_progress | done | withdrawn | rejected
+	ScheduledAt         sql.NullTime   `db:"scheduled_at"`
+	DurationMinutes     sql.NullInt64  `db:"duration_minutes"`      // 所要時間（分、1〜1440）
+	TravelBufferMinutes int            `db:"travel_buffer_minutes"` // 前後に必要な移動時間（分、0〜240）
+	ColorTag            string         `db:"color_tag"`             // enum: orange | purple
+	Completed           bool           `db:"completed"`
+	Motivation          sql.NullString `db:"motivation"`         // 志望理由
+	WhatToDo            sql.NullString `db:"what_to_do"`         // 入社後にやりたいこと
+	JobAxis             sql.NullString `db:"job_axis"`           // 就活軸
+	Strengths           sql.NullString `db:"strengths"`          // 自分の強みがどう生きるか
+	StatusFromStages    bool           `db:"status_from_stages"` // 選考ステップの結果からステータスを決めるか
+	CreatedAt           time.Time      `db:"created_at"`
+	UpdatedAt           time.Time      `db:"updated_at"`
 }
 
 // selection_stages テーブル（応募ごとの選考ステップ）に対応
 type SelectionStageRecord struct {
-	ID            uuid.UUID      `db:"id"`
-	ApplicationID uuid.UUID      `db:"application_id"` // FK -> applications.id
-	Name          string         `db:"name"`           // ES/一次/二次/最終/内々定/内定
-	ScheduledAt   sql.NullTime   `db:"scheduled_at"`
-	Status        string         `db:"status"` // enum: pending | passed | failed
-	Notes         sql.NullString `db:"notes"`
-	CreatedAt     time.Time      `db:"created_at"`
-	UpdatedAt     time.Time      `db:"updated_at"`
+	ID                  uuid.UUID      `db:"id"`
+	ApplicationID       uuid.UUID      `db:"application_id"` // FK -> applications.id
+	Name                string         `db:"name"`           // ES/一次/二次/最終/内々定/内定
+	ScheduledAt         sql.NullTime   `db:"scheduled_at"`
+	DurationMinutes     sql.NullInt64  `db:"duration_minutes"`
+	TravelBufferMinutes int            `db:"travel_buffer_minutes"`
+	Status              string         `db:"status"` // enum: pending | passed | failed
+	Notes               sql.NullString `db:"notes"`
+	Position            int            `db:"position"`     // 応募内での表示順（0 始まり）
+	ExternalUID         sql.NullString `db:"external_uid"` // 取り込み元の .ics の UID（再取り込みの重複防止）
+	CreatedAt           time.Time      `db:"created_at"`
+	UpdatedAt           time.Time      `db:"updated_at"`
 }
 
-// reminders テーブル（将来のリマインド機能用、任意）に対応
+// reminders テーブル（応募のリマインダー）に対応
 type ReminderRecord struct {
-	ID            uuid.UUID `db:"id"`
-	ApplicationID uuid.UUID `db:"application_id"` // FK -> applications.id
-	TargetAt      time.Time `db:"target_at"`
-	Channel       string    `db:"channel"` // enum: in_app | email
-	Message       string    `db:"message"`
-	CreatedAt     time.Time `db:"created_at"`
-	UpdatedAt     time.Time `db:"updated_at"`
+	ID             uuid.UUID      `db:"id"`
+	ApplicationID  uuid.UUID      `db:"application_id"` // FK -> applications.id
+	TargetAt       time.Time      `db:"target_at"`
+	Channel        string         `db:"channel"` // enum: in_app | email
+	Message        string         `db:"message"`
+	Status         string         `db:"status"` // enum: pending | delivered | acknowledged | failed
+	Attempts       int            `db:"attempts"`
+	NextAttemptAt  time.Time      `db:"next_attempt_at"` // 次に発火する時刻
+	FireCount      int            `db:"fire_count"`      // 配信済みの回数（冪等キーに使う）
+	LastError      sql.NullString `db:"last_error"`
+	DeliveredAt    sql.NullTime   `db:"delivered_at"`
+	AcknowledgedAt sql.NullTime   `db:"acknowledged_at"`
+	CreatedAt      time.Time      `db:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at"`
+}
+
+// calendar_feeds テーブル（iCalendar 購読フィード、ユーザーごとに 1 つ）に対応
+type CalendarFeedRecord struct {
+	UserID         uuid.UUID    `db:"user_id"`    // PK, FK -> users.id
+	TokenHash      string       `db:"token_hash"` // フィード URL の秘密トークンの SHA-256
+	CreatedAt      time.Time    `db:"created_at"`
+	LastAccessedAt sql.NullTime `db:"last_accessed_at"`
 }
 
 // ---- ドメインサービスの簡易スケルトン（ドキュメント用の最小実装）----
@@ -71,17 +93,18 @@ type ProgressPolicy struct{}
 
 // ValidateStatusTransition:
 //
-//	todo -> scheduled | in_progress | withdrawn
-//	scheduled -> in_progress | withdrawn
-//	in_progress -> done | withdrawn
-//	done / withdrawn は終端
+//	todo -> scheduled | in_progress | withdrawn | rejected
+//	scheduled -> in_progress | withdrawn | rejected
+//	in_progress -> done | withdrawn | rejected
+//	done / withdrawn / rejected は終端
 func (ProgressPolicy) ValidateStatusTransition(current, next string) error {
 	allowed := map[string][]string{
-		"todo":        {"scheduled", "in_progress", "withdrawn"},
-		"scheduled":   {"in_progress", "withdrawn"},
-		"in_progress": {"done", "withdrawn"},
+		"todo":        {"scheduled", "in_progress", "withdrawn", "rejected"},
+		"scheduled":   {"in_progress", "withdrawn", "rejected"},
+		"in_progress": {"done", "withdrawn", "rejected"},
 		"done":        {},
 		"withdrawn":   {},
+		"rejected":    {},
 	}
 	for _, n := range allowed[current] {
 		if n == next {
@@ -94,19 +117,34 @@ func (ProgressPolicy) ValidateStatusTransition(current, next string) error {
 // ApplicationScheduler は同一ユーザーの応募予定の時間衝突を検出する。
 type ApplicationScheduler struct{}
 
-// HasConflict: シンプルに「同じ日時と完全一致」したら衝突とみなすデモ実装。
+// HasConflict: 所要時間（未設定なら 1 時間）と移動時間を含めて時間帯が重なれば衝突とみなすデモ実装。
+// 本実装は domain_service.ApplicationScheduler（選考ステップの予定も対象）。
 func (ApplicationScheduler) HasConflict(existing []ApplicationRecord, candidate ApplicationRecord) bool {
-	if candidate.ScheduledAt.Valid {
-		target := candidate.ScheduledAt.Time
-		for _, a := range existing {
-			if a.UserID == candidate.UserID && a.ScheduledAt.Valid && a.ScheduledAt.Time.Equal(target) {
-				return true
-			}
+	if !candidate.ScheduledAt.Valid {
+		return false
+	}
+	start, end := span(candidate)
+	for _, a := range existing {
+		if a.ID == candidate.ID || a.UserID != candidate.UserID || !a.ScheduledAt.Valid {
+			continue
+		}
+		gap := time.Duration(max(a.TravelBufferMinutes, candidate.TravelBufferMinutes)) * time.Minute
+		s, e := span(a)
+		if start.Before(e.Add(gap)) && s.Before(end.Add(gap)) {
+			return true
 		}
 	}
 	return false
 }
 
+func span(a ApplicationRecord) (time.Time, time.Time) {
+	d := time.Hour
+	if a.DurationMinutes.Valid {
+		d = time.Duration(a.DurationMinutes.Int64) * time.Minute
+	}
+	return a.ScheduledAt.Time, a.ScheduledAt.Time.Add(d)
+}
+
 // ReminderService はリマインドの生成を行うスタブ。
 type ReminderService struct{}
 
@@ -70,6 +70,7 @@ const (
 	ApplicationStatusInProgress ApplicationStatus = "in_progress"
 	ApplicationStatusDone       ApplicationStatus = "done"
 	ApplicationStatusWithdrawn  ApplicationStatus = "withdrawn"
+	ApplicationStatusRejected   ApplicationStatus = "rejected"
 )
 
 type SelectionStageStatus string
@@ -1,21 +1,143 @@
 package domain_service
 
 import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
 	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+)
+
+const (
+	// DefaultEventDuration は所要時間が未設定の予定に仮定する長さ。
+	DefaultEventDuration = time.Hour
+	// MaxEventDuration / MaxTravelBuffer は入力できる所要時間・移動時間の上限。
+	MaxEventDuration = 24 * time.Hour
+	MaxTravelBuffer  = 4 * time.Hour
+)
+
+type ScheduleEventKind string
+
+const (
+	ScheduleEventApplication ScheduleEventKind = "application" // 応募そのものの予定 (scheduled_at)
+	ScheduleEventStage       ScheduleEventKind = "stage"       // 選考ステップの予定
+)
+
+// ScheduleEvent は予定表上の 1 件。Buffer は前後に必要な移動時間。
+type ScheduleEvent struct {
+	Kind          ScheduleEventKind
+	ApplicationID uuid.UUID
+	StageID       *uuid.UUID
+	Name          string // 選考ステップ名（応募の予定なら空）
+	Start         time.Time
+	End           time.Time
+	Buffer        time.Duration
+}
+
+type ScheduleConflictKind string
+
+const (
+	ScheduleConflictOverlap ScheduleConflictKind = "overlap"       // 時間帯が重なっている
+	ScheduleConflictBuffer  ScheduleConflictKind = "travel_buffer" // 重ならないが移動時間が足りない
 )
 
+// ScheduleConflict は衝突する 2 件の予定。A は B より先に始まる。
+type ScheduleConflict struct {
+	Kind ScheduleConflictKind
+	A    ScheduleEvent
+	B    ScheduleEvent
+}
+
 // ApplicationScheduler は同一ユーザーの応募予定の時間衝突を検出する。
 type ApplicationScheduler struct{}
 
-// HasConflict: シンプルに「同じ日時と完全一致」したら衝突とみなすデモ実装。
-func (ApplicationScheduler) HasConflict(existing []entity.Application, candidate entity.Application) bool {
-	if candidate.ScheduledAt == nil {
-		return false
+// Events は応募の予定と、予定日時のある未完了 (pending) の選考ステップを予定表の形にする。
+// 終了済み (done / withdrawn / rejected) の応募は予定を持たないものとして扱う。
+func (ApplicationScheduler) Events(app *entity.Application) []ScheduleEvent {
+	if app.Status.IsFinished() {
+		return nil
+	}
+
+	var events []ScheduleEvent
+	if app.ScheduledAt != nil {
+		events = append(events, ScheduleEvent{
+			Kind:          ScheduleEventApplication,
+			ApplicationID: app.ID,
+			Start:         *app.ScheduledAt,
+			End:           app.ScheduledAt.Add(durationOrDefault(app.Duration)),
+			Buffer:        app.TravelBuffer,
+		})
 	}
-	for _, a := range existing {
-		if a.UserID == candidate.UserID && a.ScheduledAt != nil && a.ScheduledAt.Equal(*candidate.ScheduledAt) {
-			return true
+	for _, stage := range app.Stages {
+		if stage.ScheduledAt == nil || stage.Status != value.SelectionStagePending {
+			continue
 		}
+		stageID := stage.ID
+		events = append(events, ScheduleEvent{
+			Kind:          ScheduleEventStage,
+			ApplicationID: app.ID,
+			StageID:       &stageID,
+			Name:          stage.Name,
+			Start:         *stage.ScheduledAt,
+			End:           stage.ScheduledAt.Add(durationOrDefault(stage.Duration)),
+			Buffer:        stage.TravelBuffer,
+		})
+	}
+	return events
+}
+
+// Conflicts は events の中で衝突する組をすべて返す（開始時刻順）。
+// 同じ応募の予定同士（応募の日時と選考ステップが同じ面接を指す場合など）は衝突とみなさない。
+// 2 件の間には、どちらか長い方の移動時間が空いている必要がある。
+func (ApplicationScheduler) Conflicts(events []ScheduleEvent) []ScheduleConflict {
+	sorted := append([]ScheduleEvent(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var conflicts []ScheduleConflict
+	for i, a := range sorted {
+		for _, b := range sorted[i+1:] {
+			// b starts after a ends plus the longest possible buffer: no later event can conflict with a
+			if !b.Start.Before(a.End.Add(MaxTravelBuffer)) {
+				break
+			}
+			if a.ApplicationID == b.ApplicationID {
+				continue
+			}
+			if kind, ok := conflictKind(a, b); ok {
+				conflicts = append(conflicts, ScheduleConflict{Kind: kind, A: a, B: b})
+			}
+		}
+	}
+	return conflicts
+}
+
+// ConflictsOf は Conflicts のうち、applicationID の予定を含むものだけを返す。
+func (s ApplicationScheduler) ConflictsOf(applicationID uuid.UUID, events []ScheduleEvent) []ScheduleConflict {
+	var conflicts []ScheduleConflict
+	for _, c := range s.Conflicts(events) {
+		if c.A.ApplicationID == applicationID || c.B.ApplicationID == applicationID {
+			conflicts = append(conflicts, c)
+		}
+	}
+	return conflicts
+}
+
+// conflictKind expects a to start no later than b
+func conflictKind(a, b ScheduleEvent) (ScheduleConflictKind, bool) {
+	if b.Start.Before(a.End) {
+		return ScheduleConflictOverlap, true
+	}
+	gap := max(a.Buffer, b.Buffer)
+	if b.Start.Before(a.End.Add(gap)) {
+		return ScheduleConflictBuffer, true
+	}
+	return "", false
+}
+
+func durationOrDefault(d *time.Duration) time.Duration {
+	if d == nil {
+		return DefaultEventDuration
 	}
-	return false
+	return *d
 }
@@ -0,0 +1,66 @@
+package domain_service
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"noroi/internal/domain/entity"
+)
+
+// 法人格の表記。照合では「株式会社ABC」と「ABC株式会社」「(株)ABC」を同じ企業とみなす。
+var (
+	japaneseLegalForms = []string{
+		"株式会社", "有限会社", "合同会社", "合資会社", "合名会社",
+		"一般社団法人", "一般財団法人", "(株)", "(有)", "(同)",
+	}
+	englishLegalForms = map[string]bool{
+		"inc": true, "co": true, "ltd": true, "corp": true, "corporation": true,
+		"llc": true, "kk": true, "k.k": true, "company": true, "limited": true,
+	}
+)
+
+// CompanyMatcher は表記ゆれを吸収して企業名を照合する。
+type CompanyMatcher struct{}
+
+// Name は法人格を除いた企業名（NFKC 正規化済み）。部分一致検索の語に使う。
+func (CompanyMatcher) Name(name string) string {
+	s := norm.NFKC.String(strings.TrimSpace(name))
+	for _, form := range japaneseLegalForms {
+		s = strings.ReplaceAll(s, form, " ")
+	}
+
+	words := strings.FieldsFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == ',' })
+	kept := words[:0]
+	for _, word := range words {
+		if !englishLegalForms[strings.TrimRight(strings.ToLower(word), ".")] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// Key は照合用のキー。法人格・大文字小文字・空白や記号の違いを無視する。
+func (m CompanyMatcher) Key(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(m.Name(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Match は name と同じキーを持つ企業を返す。なければ nil。
+func (m CompanyMatcher) Match(name string, companies []*entity.Company) *entity.Company {
+	key := m.Key(name)
+	if key == "" {
+		return nil
+	}
+	for _, company := range companies {
+		if m.Key(company.Name) == key {
+			return company
+		}
+	}
+	return nil
+}
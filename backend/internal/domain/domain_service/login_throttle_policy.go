@@ -0,0 +1,54 @@
+package domain_service
+
+import (
+	"time"
+
+	"noroi/internal/domain/entity"
+)
+
+// LoginThrottlePolicy はログイン失敗時のバックオフとロックアウトを表す。
+//
+// 失敗が FreeAttempts 回を超えると、以降の失敗ごとに BaseDelay から倍々に
+// ロック時間が伸び、MaxLock で頭打ちになる（一時的なロックアウト）。
+// 最後の失敗から Window が経過するとカウンターはリセットされる。
+type LoginThrottlePolicy struct{}
+
+type loginThrottleRule struct {
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxLock      time.Duration
+	Window       time.Duration
+}
+
+var loginThrottleRules = map[entity.LoginThrottleScope]loginThrottleRule{
+	// アカウント単位: パスワード総当たり対策
+	entity.LoginThrottleScopeAccount: {FreeAttempts: 5, BaseDelay: time.Second, MaxLock: 15 * time.Minute, Window: time.Hour},
+	// IP 単位: クレデンシャルスタッフィング対策。NAT 配下の利用者を考慮して緩めにする
+	entity.LoginThrottleScopeIP: {FreeAttempts: 30, BaseDelay: time.Second, MaxLock: time.Hour, Window: time.Hour},
+	// ユーザー単位の二要素認証コード: ログイン時も設定変更時も同じカウンターで数える
+	entity.LoginThrottleScopeMFA: {FreeAttempts: 5, BaseDelay: time.Second, MaxLock: 15 * time.Minute, Window: time.Hour},
+}
+
+// Window returns how long failures are remembered for scope
+func (LoginThrottlePolicy) Window(scope entity.LoginThrottleScope) time.Duration {
+	return loginThrottleRules[scope].Window
+}
+
+// LockDuration returns how long to lock scope after the given number of
+// consecutive failures; zero means no lock.
+func (LoginThrottlePolicy) LockDuration(scope entity.LoginThrottleScope, failures int) time.Duration {
+	rule := loginThrottleRules[scope]
+	excess := failures - rule.FreeAttempts
+	if excess <= 0 {
+		return 0
+	}
+
+	delay := rule.BaseDelay
+	for i := 1; i < excess && delay < rule.MaxLock; i++ {
+		delay *= 2
+	}
+	if delay > rule.MaxLock {
+		delay = rule.MaxLock
+	}
+	return delay
+}
@@ -0,0 +1,19 @@
+package domain_service
+
+import (
+	"noroi/internal/domain/entity"
+	"noroi/pkg/errors"
+)
+
+// ParticipationPolicy は儀式への参加の可否を表す。
+// メールアドレス未確認のユーザーは閲覧はできるが、儀式には参加できない。
+// ポイントの獲得は User.AddPoints が同じ条件で拒否する。
+type ParticipationPolicy struct{}
+
+// CanJoinRitual returns errors.ErrEmailNotVerified for unverified users
+func (ParticipationPolicy) CanJoinRitual(user *entity.User) error {
+	if !user.IsEmailVerified() {
+		return errors.ErrEmailNotVerified
+	}
+	return nil
+}
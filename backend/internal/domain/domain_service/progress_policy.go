@@ -1,32 +1,119 @@
 package domain_service
 
 import (
-	"errors"
-
+	"noroi/internal/domain/entity"
 	"noroi/internal/domain/value"
 )
 
 // ProgressPolicy は応募ステータスの遷移制約を表す。
 type ProgressPolicy struct{}
 
-// ValidateStatusTransition:
+// applicationStatusTransitions:
 //
-//	todo -> scheduled | in_progress | withdrawn
-//	scheduled -> in_progress | withdrawn
-//	in_progress -> done | withdrawn
-//	done / withdrawn は終端
-func (ProgressPolicy) ValidateStatusTransition(current, next value.ApplicationStatus) error {
-	allowed := map[value.ApplicationStatus][]value.ApplicationStatus{
-		value.ApplicationStatusToDo:       {value.ApplicationStatusScheduled, value.ApplicationStatusInProgress, value.ApplicationStatusWithdrawn},
-		value.ApplicationStatusScheduled:  {value.ApplicationStatusInProgress, value.ApplicationStatusWithdrawn},
-		value.ApplicationStatusInProgress: {value.ApplicationStatusDone, value.ApplicationStatusWithdrawn},
-		value.ApplicationStatusDone:       {},
-		value.ApplicationStatusWithdrawn:  {},
-	}
-	for _, n := range allowed[current] {
+//	todo -> scheduled | in_progress | withdrawn | rejected
+//	scheduled -> in_progress | withdrawn | rejected
+//	in_progress -> done | withdrawn | rejected
+//	done / withdrawn / rejected は終端
+var applicationStatusTransitions = map[value.ApplicationStatus][]value.ApplicationStatus{
+	value.ApplicationStatusToDo:       {value.ApplicationStatusScheduled, value.ApplicationStatusInProgress, value.ApplicationStatusWithdrawn, value.ApplicationStatusRejected},
+	value.ApplicationStatusScheduled:  {value.ApplicationStatusInProgress, value.ApplicationStatusWithdrawn, value.ApplicationStatusRejected},
+	value.ApplicationStatusInProgress: {value.ApplicationStatusDone, value.ApplicationStatusWithdrawn, value.ApplicationStatusRejected},
+	value.ApplicationStatusDone:       {},
+	value.ApplicationStatusWithdrawn:  {},
+	value.ApplicationStatusRejected:   {},
+}
+
+// StatusTransitionError は許可されていない遷移を表し、遷移可能なステータスを保持する。
+type StatusTransitionError struct {
+	Current value.ApplicationStatus
+	Next    value.ApplicationStatus
+	Allowed []value.ApplicationStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	return "invalid status transition: " + string(e.Current) + " -> " + string(e.Next)
+}
+
+// AllowedNextStatuses は current から遷移できるステータスを返す（終端なら空）。
+func (ProgressPolicy) AllowedNextStatuses(current value.ApplicationStatus) []value.ApplicationStatus {
+	allowed := applicationStatusTransitions[current]
+	return append(make([]value.ApplicationStatus, 0, len(allowed)), allowed...)
+}
+
+// ValidateStatusTransition は遷移が許可されていなければ *StatusTransitionError を返す。
+func (p ProgressPolicy) ValidateStatusTransition(current, next value.ApplicationStatus) error {
+	for _, n := range applicationStatusTransitions[current] {
 		if n == next {
 			return nil
 		}
 	}
-	return errors.New("invalid status transition: " + string(current) + " -> " + string(next))
+	return &StatusTransitionError{
+		Current: current,
+		Next:    next,
+		Allowed: p.AllowedNextStatuses(current),
+	}
+}
+
+// TransitionPath は current から target までの最短の遷移列を返す（current は含まない）。
+// 到達できなければ ok=false、同じステータスなら空で ok=true。
+func (ProgressPolicy) TransitionPath(current, target value.ApplicationStatus) (path []value.ApplicationStatus, ok bool) {
+	if current == target {
+		return nil, true
+	}
+	prev := map[value.ApplicationStatus]value.ApplicationStatus{current: current}
+	queue := []value.ApplicationStatus{current}
+	for len(queue) > 0 {
+		status := queue[0]
+		queue = queue[1:]
+		for _, next := range applicationStatusTransitions[status] {
+			if _, seen := prev[next]; seen {
+				continue
+			}
+			prev[next] = status
+			if next == target {
+				for s := target; s != current; s = prev[s] {
+					path = append([]value.ApplicationStatus{s}, path...)
+				}
+				return path, true
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil, false
+}
+
+// StatusFromStages は選考ステップの結果から応募ステータスを導く。ステップがなければ ok=false。
+//
+//	いずれかが failed -> rejected（不合格。辞退の withdrawn とは区別する）
+//	すべて passed -> done
+//	passed が 1 つ以上 -> in_progress
+//	予定日時のある pending がある -> scheduled
+//	それ以外 -> todo
+func (ProgressPolicy) StatusFromStages(stages []entity.SelectionStage) (status value.ApplicationStatus, ok bool) {
+	if len(stages) == 0 {
+		return "", false
+	}
+	passed, scheduled := 0, false
+	for _, stage := range stages {
+		switch stage.Status {
+		case value.SelectionStageFailed:
+			return value.ApplicationStatusRejected, true
+		case value.SelectionStagePassed:
+			passed++
+		case value.SelectionStagePending:
+			if stage.ScheduledAt != nil {
+				scheduled = true
+			}
+		}
+	}
+	switch {
+	case passed == len(stages):
+		return value.ApplicationStatusDone, true
+	case passed > 0:
+		return value.ApplicationStatusInProgress, true
+	case scheduled:
+		return value.ApplicationStatusScheduled, true
+	default:
+		return value.ApplicationStatusToDo, true
+	}
 }
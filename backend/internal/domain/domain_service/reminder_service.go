@@ -3,22 +3,14 @@ package domain_service
 import (
 	"time"
 
-	"github.com/google/uuid"
 	"noroi/internal/domain/entity"
 	"noroi/internal/domain/value"
 )
 
-// ReminderService はリマインドの生成を行うスタブ。
+// ReminderService はリマインドの生成を行う。
 type ReminderService struct{}
 
+// Build は応募に紐づく発火待ちのリマインドを作る。
 func (ReminderService) Build(app entity.Application, channel value.ReminderChannel, message string, target time.Time) entity.Reminder {
-	return entity.Reminder{
-		ID:            uuid.New(),
-		ApplicationID: app.ID,
-		TargetAt:      target,
-		Channel:       channel,
-		Message:       message,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
-	}
+	return *entity.NewReminder(app.ID, target, channel, message)
 }
@@ -1,14 +1,19 @@
 package entity
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"noroi/internal/domain/value"
 )
 
+// MaxSelectionStages は 1 つの応募に登録できる選考ステップの上限。
+const MaxSelectionStages = 20
+
 // Application はユーザーごとの応募/保存単位の集約ルート。
 // Company を参照し、カテゴリーや進捗、メモを保持する。
+// Stages は Position 順に保持し、応募と同じトランザクションで保存する。
 type Application struct {
 	ID          uuid.UUID
 	UserID      uuid.UUID
@@ -16,16 +21,23 @@ type Application struct {
 	Category    value.ApplicationCategory
 	Status      value.ApplicationStatus
 	ScheduledAt *time.Time
-	ColorTag    value.ColorTag
-	Completed   bool
-	Motivation  string
-	WhatToDo    string
-	JobAxis     string
-	Strengths   string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Stages      []SelectionStage
-	Reminders   []Reminder
+	// Duration は予定の所要時間（nil は未設定）、TravelBuffer は前後に必要な移動時間
+	Duration     *time.Duration
+	TravelBuffer time.Duration
+	ColorTag     value.ColorTag
+	Completed    bool
+	Motivation   string
+	WhatToDo     string
+	JobAxis      string
+	Strengths    string
+	// StatusFromStages が true なら Status は選考ステップの結果から決まる
+	StatusFromStages bool
+	// Version は読み込んだ時点の版数。保存時に一致しなければ他の更新と競合している
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Stages    []SelectionStage
+	Reminders []Reminder
 }
 
 func NewApplication(userID, companyID uuid.UUID, category value.ApplicationCategory, status value.ApplicationStatus, scheduledAt *time.Time, colorTag value.ColorTag) *Application {
@@ -44,12 +56,19 @@ func NewApplication(userID, companyID uuid.UUID, category value.ApplicationCateg
 	}
 }
 
-func (a *Application) UpdateStatus(next value.ApplicationStatus) {
+// UpdateStatus は遷移を記録した ApplicationStatusChange を返す。ステータスが変わらなければ nil。
+// 遷移の可否は domain_service.ProgressPolicy で事前に検証すること。
+func (a *Application) UpdateStatus(next value.ApplicationStatus) *ApplicationStatusChange {
+	if next == a.Status {
+		return nil
+	}
+	current := a.Status
 	a.Status = next
 	if next == value.ApplicationStatusDone {
 		a.Completed = true
 	}
 	a.UpdatedAt = time.Now()
+	return NewApplicationStatusChange(a.ID, &current, next, a.UpdatedAt)
 }
 
 func (a *Application) UpdateNotes(motivation, whatToDo, jobAxis, strengths string) {
@@ -64,3 +83,77 @@ func (a *Application) Reschedule(t *time.Time) {
 	a.ScheduledAt = t
 	a.UpdatedAt = time.Now()
 }
+
+func (a *Application) SetDuration(d *time.Duration, travelBuffer time.Duration) {
+	a.Duration = d
+	a.TravelBuffer = travelBuffer
+	a.UpdatedAt = time.Now()
+}
+
+// AddStage は選考ステップを末尾に追加する。
+func (a *Application) AddStage(name string, scheduledAt *time.Time, status value.SelectionStageStatus, notes *string) (*SelectionStage, error) {
+	if len(a.Stages) >= MaxSelectionStages {
+		return nil, errors.New("invalid stage: too many stages")
+	}
+	stage := NewSelectionStage(a.ID, name, scheduledAt, status, notes)
+	stage.Position = len(a.Stages)
+	a.Stages = append(a.Stages, *stage)
+	a.UpdatedAt = time.Now()
+	return &a.Stages[len(a.Stages)-1], nil
+}
+
+// Stage は ID に一致する選考ステップを返す。なければ nil。
+func (a *Application) Stage(id uuid.UUID) *SelectionStage {
+	for i := range a.Stages {
+		if a.Stages[i].ID == id {
+			return &a.Stages[i]
+		}
+	}
+	return nil
+}
+
+// RemoveStage は選考ステップを削除し、残りを詰めて採番し直す。
+func (a *Application) RemoveStage(id uuid.UUID) bool {
+	for i := range a.Stages {
+		if a.Stages[i].ID == id {
+			a.Stages = append(a.Stages[:i], a.Stages[i+1:]...)
+			a.renumberStages()
+			a.UpdatedAt = time.Now()
+			return true
+		}
+	}
+	return false
+}
+
+// ReorderStages は選考ステップを ids の順に並べ替える。ids は全ステップの ID をちょうど 1 回ずつ含むこと。
+func (a *Application) ReorderStages(ids []uuid.UUID) error {
+	if len(ids) != len(a.Stages) {
+		return errors.New("invalid stage order: every stage must be listed exactly once")
+	}
+	byID := make(map[uuid.UUID]SelectionStage, len(a.Stages))
+	for _, stage := range a.Stages {
+		byID[stage.ID] = stage
+	}
+	ordered := make([]SelectionStage, 0, len(ids))
+	for _, id := range ids {
+		stage, ok := byID[id]
+		if !ok {
+			return errors.New("invalid stage order: every stage must be listed exactly once")
+		}
+		delete(byID, id)
+		ordered = append(ordered, stage)
+	}
+	a.Stages = ordered
+	a.renumberStages()
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+func (a *Application) renumberStages() {
+	for i := range a.Stages {
+		if a.Stages[i].Position != i {
+			a.Stages[i].Position = i
+			a.Stages[i].UpdatedAt = time.Now()
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"noroi/internal/domain/value"
+)
+
+// ApplicationStatusChange は応募ステータスの遷移 1 件。
+// FromStatus が nil のものは作成時の初期ステータスを表す。
+type ApplicationStatusChange struct {
+	ID            uuid.UUID
+	ApplicationID uuid.UUID
+	FromStatus    *value.ApplicationStatus
+	ToStatus      value.ApplicationStatus
+	ChangedAt     time.Time
+}
+
+func NewApplicationStatusChange(applicationID uuid.UUID, from *value.ApplicationStatus, to value.ApplicationStatus, changedAt time.Time) *ApplicationStatusChange {
+	return &ApplicationStatusChange{
+		ID:            uuid.New(),
+		ApplicationID: applicationID,
+		FromStatus:    from,
+		ToStatus:      to,
+		ChangedAt:     changedAt,
+	}
+}
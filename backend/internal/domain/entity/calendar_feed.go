@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeedAccessInterval は LastAccessedAt を更新する最小間隔（カレンダーアプリは頻繁に取得するため）。
+const CalendarFeedAccessInterval = time.Hour
+
+// CalendarFeed はユーザーの iCalendar 購読フィード。
+// フィード URL に含まれる秘密トークンのハッシュのみ保持し、再発行すると古い URL は無効になる。
+type CalendarFeed struct {
+	UserID         uuid.UUID
+	TokenHash      string
+	CreatedAt      time.Time
+	LastAccessedAt *time.Time
+}
+
+func NewCalendarFeed(userID uuid.UUID, tokenHash string) *CalendarFeed {
+	return &CalendarFeed{
+		UserID:    userID,
+		TokenHash: tokenHash,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// ShouldRecordAccess は now の取得を LastAccessedAt に記録すべきかを返す。
+func (f *CalendarFeed) ShouldRecordAccess(now time.Time) bool {
+	return f.LastAccessedAt == nil || now.Sub(*f.LastAccessedAt) >= CalendarFeedAccessInterval
+}
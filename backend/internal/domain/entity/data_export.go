@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DataExportStatus string
+
+const (
+	DataExportStatusPending    DataExportStatus = "pending"    // 作成待ち・再試行待ち
+	DataExportStatusProcessing DataExportStatus = "processing" // ワーカーが作成中
+	DataExportStatusReady      DataExportStatus = "ready"      // ダウンロード可能
+	DataExportStatusFailed     DataExportStatus = "failed"     // 再試行上限に到達
+	DataExportStatusExpired    DataExportStatus = "expired"    // 期限切れ（ファイル削除済み）
+)
+
+const (
+	// DataExportLinkLifetime is how long a finished archive can be downloaded
+	DataExportLinkLifetime = 24 * time.Hour
+	// DataExportMinInterval limits how often one user can request an export
+	DataExportMinInterval = time.Hour
+	// DataExportMaxAttempts is the number of attempts before a job is given up
+	DataExportMaxAttempts = 3
+	// DataExportLease is how long a claimed job may run before another worker retries it
+	DataExportLease        = 10 * time.Minute
+	dataExportRetryBackoff = 5 * time.Minute
+)
+
+// DataExport is an asynchronous job that archives a user's personal data.
+// The download token is sent by mail; only its hash is stored.
+type DataExport struct {
+	ID                uuid.UUID
+	UserID            uuid.UUID
+	Status            DataExportStatus
+	Attempts          int
+	NextAttemptAt     time.Time
+	FileName          *string
+	SizeBytes         *int64
+	DownloadTokenHash *string
+	LastError         *string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	CompletedAt       *time.Time
+	ExpiresAt         *time.Time
+}
+
+func NewDataExport(userID uuid.UUID) *DataExport {
+	now := time.Now()
+	return &DataExport{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Status:        DataExportStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// IsActive reports whether the job has not finished yet
+func (e *DataExport) IsActive() bool {
+	return e.Status == DataExportStatusPending || e.Status == DataExportStatusProcessing
+}
+
+// IsDownloadable reports whether the archive exists and its link is valid at now
+func (e *DataExport) IsDownloadable(now time.Time) bool {
+	return e.Status == DataExportStatusReady && e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+}
+
+func (e *DataExport) MarkReady(fileName string, sizeBytes int64, downloadTokenHash string) {
+	now := time.Now()
+	expiresAt := now.Add(DataExportLinkLifetime)
+	e.Status = DataExportStatusReady
+	e.FileName = &fileName
+	e.SizeBytes = &sizeBytes
+	e.DownloadTokenHash = &downloadTokenHash
+	e.LastError = nil
+	e.CompletedAt = &now
+	e.ExpiresAt = &expiresAt
+	e.UpdatedAt = now
+}
+
+// MarkFailed records a failed attempt and schedules a retry, or gives up once
+// DataExportMaxAttempts is reached.
+func (e *DataExport) MarkFailed(reason string) {
+	now := time.Now()
+	e.LastError = &reason
+	e.UpdatedAt = now
+
+	if e.Attempts >= DataExportMaxAttempts {
+		e.Status = DataExportStatusFailed
+		e.CompletedAt = &now
+		return
+	}
+	e.Status = DataExportStatusPending
+	e.NextAttemptAt = now.Add(dataExportRetryBackoff)
+}
+
+// MarkExpired records that the archive has been removed from storage
+func (e *DataExport) MarkExpired() {
+	e.Status = DataExportStatusExpired
+	e.DownloadTokenHash = nil
+	e.UpdatedAt = time.Now()
+}
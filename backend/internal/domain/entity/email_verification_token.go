@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// EmailVerificationTokenLifetime is how long a verification link stays valid
+	EmailVerificationTokenLifetime = 24 * time.Hour
+	// EmailVerificationMaxPerHour limits how many verification mails one account receives per hour
+	EmailVerificationMaxPerHour = 3
+)
+
+// EmailVerificationToken はメールアドレス確認リンクのトークン。保存するのはハッシュだけ。
+type EmailVerificationToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+func NewEmailVerificationToken(userID uuid.UUID, tokenHash string) *EmailVerificationToken {
+	now := time.Now()
+	return &EmailVerificationToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: tokenHash,
+		CreatedAt: now,
+		ExpiresAt: now.Add(EmailVerificationTokenLifetime),
+	}
+}
+
+// IsUsable reports whether the token is unused and unexpired at now
+func (t *EmailVerificationToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
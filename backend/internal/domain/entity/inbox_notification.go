@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboxNotification はアプリ内の受信箱に残る通知。DedupKey が同じ通知は 1 件だけ保存される。
+type InboxNotification struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Kind      string
+	Title     string
+	Body      string
+	URL       string
+	DedupKey  *string
+	CreatedAt time.Time
+}
+
+func NewInboxNotification(userID uuid.UUID, kind, title, body, url string, dedupKey *string) *InboxNotification {
+	return &InboxNotification{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Kind:      kind,
+		Title:     title,
+		Body:      body,
+		URL:       url,
+		DedupKey:  dedupKey,
+		CreatedAt: time.Now(),
+	}
+}
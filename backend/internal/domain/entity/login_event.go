@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginThrottleScope は失敗回数を数える単位
+type LoginThrottleScope string
+
+const (
+	LoginThrottleScopeAccount LoginThrottleScope = "account" // key: 正規化したメールアドレス（未登録でも数える）
+	LoginThrottleScopeIP      LoginThrottleScope = "ip"      // key: クライアント IP
+	LoginThrottleScopeMFA     LoginThrottleScope = "mfa"     // key: ユーザー ID（二要素認証コードの入力）
+)
+
+type LoginFailureReason string
+
+const (
+	LoginFailureInvalidCredentials LoginFailureReason = "invalid_credentials"
+	LoginFailureInvalidMFACode     LoginFailureReason = "invalid_mfa_code"
+	LoginFailureThrottled          LoginFailureReason = "throttled"
+)
+
+// LoginEvent はログインの成功・失敗の記録
+type LoginEvent struct {
+	ID            uuid.UUID
+	UserID        *uuid.UUID // 未登録のメールアドレスの場合は nil
+	Email         string
+	IPAddress     string
+	UserAgent     string
+	Success       bool
+	FailureReason *LoginFailureReason
+	CreatedAt     time.Time
+}
+
+func NewLoginEvent(userID *uuid.UUID, email, ipAddress, userAgent string, failure *LoginFailureReason) *LoginEvent {
+	return &LoginEvent{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Email:         email,
+		IPAddress:     ipAddress,
+		UserAgent:     userAgent,
+		Success:       failure == nil,
+		FailureReason: failure,
+		CreatedAt:     time.Now(),
+	}
+}
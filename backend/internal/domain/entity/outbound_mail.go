@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboundMailStatus string
+
+const (
+	OutboundMailStatusPending OutboundMailStatus = "pending" // 送信待ち・再送待ち
+	OutboundMailStatusSent    OutboundMailStatus = "sent"    // 送信済み
+	OutboundMailStatusFailed  OutboundMailStatus = "failed"  // 再送上限に到達
+)
+
+const (
+	// OutboundMailMaxAttempts is the number of delivery attempts before a mail is given up
+	OutboundMailMaxAttempts = 8
+	outboundMailBaseBackoff = time.Minute
+	outboundMailMaxBackoff  = time.Hour
+)
+
+// OutboundMail is a rendered email waiting in the persistent outbox.
+type OutboundMail struct {
+	ID            uuid.UUID
+	To            string
+	Template      string
+	Subject       string
+	TextBody      string
+	HTMLBody      string
+	Status        OutboundMailStatus
+	DedupKey      *string // optional: a second mail with the same key is not enqueued
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     *string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	SentAt        *time.Time
+}
+
+func NewOutboundMail(to, template, subject, textBody, htmlBody string) *OutboundMail {
+	now := time.Now()
+	return &OutboundMail{
+		ID:            uuid.New(),
+		To:            to,
+		Template:      template,
+		Subject:       subject,
+		TextBody:      textBody,
+		HTMLBody:      htmlBody,
+		Status:        OutboundMailStatusPending,
+		Attempts:      0,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func (m *OutboundMail) MarkSent() {
+	now := time.Now()
+	m.Status = OutboundMailStatusSent
+	m.SentAt = &now
+	m.LastError = nil
+	m.UpdatedAt = now
+}
+
+// MarkFailed records a failed attempt and schedules a retry with exponential
+// backoff, or gives up once OutboundMailMaxAttempts is reached.
+func (m *OutboundMail) MarkFailed(reason string) {
+	now := time.Now()
+	m.LastError = &reason
+	m.UpdatedAt = now
+
+	if m.Attempts >= OutboundMailMaxAttempts {
+		m.Status = OutboundMailStatusFailed
+		return
+	}
+
+	// 1m, 2m, 4m, ... capped at 1h
+	backoff := outboundMailMaxBackoff
+	if m.Attempts > 0 && m.Attempts <= 6 {
+		backoff = outboundMailBaseBackoff << (m.Attempts - 1)
+	}
+	m.Status = OutboundMailStatusPending
+	m.NextAttemptAt = now.Add(backoff)
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// PasswordResetTokenLifetime is how long a reset link stays valid
+	PasswordResetTokenLifetime = time.Hour
+	// PasswordResetMaxPerHour limits how many reset mails one account receives per hour
+	PasswordResetMaxPerHour = 3
+)
+
+// PasswordResetToken はパスワード再設定リンクのトークン。平文はメールでのみ送られ、保存するのはハッシュだけ。
+type PasswordResetToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+func NewPasswordResetToken(userID uuid.UUID, tokenHash string) *PasswordResetToken {
+	now := time.Now()
+	return &PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: tokenHash,
+		CreatedAt: now,
+		ExpiresAt: now.Add(PasswordResetTokenLifetime),
+	}
+}
+
+// IsUsable reports whether the token is unused and unexpired at now
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
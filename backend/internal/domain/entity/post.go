@@ -14,6 +14,9 @@ const (
 	PostTypeRitual PostType = "ritual"
 )
 
+// AnonymousDisplayName is shown in place of the author of an anonymous post
+const AnonymousDisplayName = "匿名"
+
 type Post struct {
 	ID          uuid.UUID
 	UserID      uuid.UUID
@@ -93,3 +96,21 @@ func (p *Post) DecrementCurseCount() {
 		p.UpdatedAt = time.Now()
 	}
 }
+
+// AuthorDisplayName returns the name shown for the post's author. Anonymous
+// posts stay anonymous even after the author deletes their account.
+func (p *Post) AuthorDisplayName(author *User) string {
+	if p.IsAnonymous {
+		return AnonymousDisplayName
+	}
+	return author.DisplayName()
+}
+
+// AuthorHandle returns the author's @handle, or "" when the post is anonymous
+// or the author's account is deleted.
+func (p *Post) AuthorHandle(author *User) string {
+	if p.IsAnonymous || author.IsDeleted {
+		return ""
+	}
+	return author.Handle.String()
+}
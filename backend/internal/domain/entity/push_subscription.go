@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"encoding/base64"
+	"noroi/pkg/errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PushSubscription はブラウザの Web Push 購読情報（PushSubscription.toJSON() の内容）。
+type PushSubscription struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	Endpoint      string
+	P256dh        string // クライアントの公開鍵（base64url, 非圧縮 P-256 点）
+	Auth          string // 認証シークレット（base64url, 16 バイト）
+	UserAgent     string
+	FailureCount  int
+	LastSuccessAt *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func NewPushSubscription(userID uuid.UUID, endpoint, p256dh, auth, userAgent string) (*PushSubscription, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return nil, errors.ErrInvalidPushSubscription
+	}
+
+	key, err := DecodePushKey(p256dh)
+	if err != nil || len(key) != 65 || key[0] != 0x04 {
+		return nil, errors.ErrInvalidPushSubscription
+	}
+	secret, err := DecodePushKey(auth)
+	if err != nil || len(secret) != 16 {
+		return nil, errors.ErrInvalidPushSubscription
+	}
+
+	now := time.Now()
+	return &PushSubscription{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Endpoint:  endpoint,
+		P256dh:    p256dh,
+		Auth:      auth,
+		UserAgent: userAgent,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// DecodePushKey decodes a base64url key as sent by browsers (padding optional).
+func DecodePushKey(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
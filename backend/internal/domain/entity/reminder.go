@@ -1,21 +1,52 @@
 package entity
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"noroi/internal/domain/value"
 )
 
-// Reminder は将来のリマインド機能用のエンティティ。
+type ReminderStatus string
+
+const (
+	ReminderStatusPending      ReminderStatus = "pending"      // 発火待ち・再試行待ち
+	ReminderStatusDelivered    ReminderStatus = "delivered"    // 配信済み（未確認）
+	ReminderStatusAcknowledged ReminderStatus = "acknowledged" // ユーザーが確認済み
+	ReminderStatusFailed       ReminderStatus = "failed"       // 再試行上限に到達
+)
+
+const (
+	// ReminderMaxAttempts is the number of delivery attempts per firing before a reminder is given up
+	ReminderMaxAttempts = 5
+	// ReminderLease is how long a claimed reminder stays invisible to other workers
+	ReminderLease = 2 * time.Minute
+	// ReminderMaxSnooze is the furthest a reminder can be snoozed
+	ReminderMaxSnooze    = 7 * 24 * time.Hour
+	reminderBaseBackoff  = time.Minute
+	reminderMaxBackoff   = 30 * time.Minute
+	reminderMessageLimit = 500
+)
+
+// Reminder は応募に紐づくリマインド。NextAttemptAt に発火し、スヌーズすると再び発火する。
+// FireCount は配信済みの回数で、発火ごとの冪等キーに使う。
 type Reminder struct {
-	ID            uuid.UUID
-	ApplicationID uuid.UUID
-	TargetAt      time.Time
-	Channel       value.ReminderChannel
-	Message       string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	ID             uuid.UUID
+	ApplicationID  uuid.UUID
+	TargetAt       time.Time
+	Channel        value.ReminderChannel
+	Message        string
+	Status         ReminderStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	FireCount      int
+	LastError      *string
+	DeliveredAt    *time.Time
+	AcknowledgedAt *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 func NewReminder(applicationID uuid.UUID, targetAt time.Time, channel value.ReminderChannel, message string) *Reminder {
@@ -26,7 +57,86 @@ func NewReminder(applicationID uuid.UUID, targetAt time.Time, channel value.Remi
 		TargetAt:      targetAt,
 		Channel:       channel,
 		Message:       message,
+		Status:        ReminderStatusPending,
+		NextAttemptAt: targetAt,
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
 }
+
+// ValidateReminderMessage は 1〜500 文字のメッセージのみ許可する。
+func ValidateReminderMessage(message string) error {
+	if message == "" || len([]rune(message)) > reminderMessageLimit {
+		return errors.New("invalid message")
+	}
+	return nil
+}
+
+// DeliveryKey は今回の発火を一意に表す。再試行やワーカーの重複実行でも同じ値になる。
+func (r *Reminder) DeliveryKey() string {
+	return fmt.Sprintf("reminder:%s:%d", r.ID, r.FireCount)
+}
+
+func (r *Reminder) MarkDelivered() {
+	now := time.Now()
+	r.Status = ReminderStatusDelivered
+	r.FireCount++
+	r.LastError = nil
+	r.DeliveredAt = &now
+	r.UpdatedAt = now
+}
+
+// MarkFailed records a failed attempt and schedules a retry with exponential
+// backoff, or gives up once ReminderMaxAttempts is reached.
+func (r *Reminder) MarkFailed(reason string) {
+	now := time.Now()
+	r.LastError = &reason
+	r.UpdatedAt = now
+
+	if r.Attempts >= ReminderMaxAttempts {
+		r.Status = ReminderStatusFailed
+		return
+	}
+
+	// 1m, 2m, 4m, ... capped at 30m
+	backoff := reminderMaxBackoff
+	if r.Attempts > 0 && r.Attempts <= 5 {
+		backoff = min(reminderBaseBackoff<<(r.Attempts-1), reminderMaxBackoff)
+	}
+	r.Status = ReminderStatusPending
+	r.NextAttemptAt = now.Add(backoff)
+}
+
+// Defer は今回の発火を until まで遅らせる（通知を控える時間帯など）。試行回数には数えない。
+func (r *Reminder) Defer(until time.Time) {
+	r.Status = ReminderStatusPending
+	if r.Attempts > 0 {
+		r.Attempts--
+	}
+	r.NextAttemptAt = until
+	r.UpdatedAt = time.Now()
+}
+
+// Snooze は until に再び発火させる。確認済みのリマインダーはスヌーズできない。
+func (r *Reminder) Snooze(until time.Time) error {
+	if r.Status == ReminderStatusAcknowledged {
+		return errors.New("invalid reminder state: already acknowledged")
+	}
+	r.Status = ReminderStatusPending
+	r.Attempts = 0
+	r.LastError = nil
+	r.NextAttemptAt = until
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// Acknowledge はリマインダーを確認済みにする。未発火のものは以後発火しない。
+func (r *Reminder) Acknowledge() {
+	if r.Status == ReminderStatusAcknowledged {
+		return
+	}
+	now := time.Now()
+	r.Status = ReminderStatusAcknowledged
+	r.AcknowledgedAt = &now
+	r.UpdatedAt = now
+}
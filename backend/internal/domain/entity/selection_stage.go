@@ -7,16 +7,21 @@ import (
 	"noroi/internal/domain/value"
 )
 
-// SelectionStage は応募ごとの選考ステップ。
+// SelectionStage は応募ごとの選考ステップ。Position は応募内での表示順（0 始まり）。
 type SelectionStage struct {
 	ID            uuid.UUID
 	ApplicationID uuid.UUID
 	Name          string
 	ScheduledAt   *time.Time
+	Duration      *time.Duration // nil は未設定
+	TravelBuffer  time.Duration
 	Status        value.SelectionStageStatus
 	Notes         *string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	Position      int
+	// ExternalUID は取り込み元の予定の UID（.ics の UID）。再取り込みで同じ予定を重複させないために使う
+	ExternalUID *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 func NewSelectionStage(applicationID uuid.UUID, name string, scheduledAt *time.Time, status value.SelectionStageStatus, notes *string) *SelectionStage {
@@ -42,3 +47,19 @@ func (s *SelectionStage) Reschedule(t *time.Time) {
 	s.ScheduledAt = t
 	s.UpdatedAt = time.Now()
 }
+
+func (s *SelectionStage) SetDuration(d *time.Duration, travelBuffer time.Duration) {
+	s.Duration = d
+	s.TravelBuffer = travelBuffer
+	s.UpdatedAt = time.Now()
+}
+
+func (s *SelectionStage) Rename(name string) {
+	s.Name = name
+	s.UpdatedAt = time.Now()
+}
+
+func (s *SelectionStage) UpdateNotes(notes *string) {
+	s.Notes = notes
+	s.UpdatedAt = time.Now()
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// SessionMaxLifetime is the absolute lifetime of a login, regardless of refreshes
+	SessionMaxLifetime = 30 * 24 * time.Hour
+	// RefreshTokenLifetime is how long an unused refresh token stays valid
+	RefreshTokenLifetime = 7 * 24 * time.Hour
+)
+
+type SessionRevokeReason string
+
+const (
+	SessionRevokeReasonLogout        SessionRevokeReason = "logout"
+	SessionRevokeReasonUserRevoked   SessionRevokeReason = "user_revoked"
+	SessionRevokeReasonTokenReuse    SessionRevokeReason = "refresh_token_reuse"
+	SessionRevokeReasonPasswordReset SessionRevokeReason = "password_reset"
+	SessionRevokeReasonRoleChanged   SessionRevokeReason = "role_changed"
+	SessionRevokeReasonAccountDelete SessionRevokeReason = "account_deleted"
+)
+
+// Session は端末ごとのログイン。リフレッシュトークンはセッション単位のファミリーとしてローテーションされる。
+type Session struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	UserAgent     string
+	IPAddress     string
+	CreatedAt     time.Time
+	LastUsedAt    time.Time
+	ExpiresAt     time.Time
+	RevokedAt     *time.Time
+	RevokedReason *SessionRevokeReason
+}
+
+func NewSession(userID uuid.UUID, userAgent, ipAddress string) *Session {
+	now := time.Now()
+	return &Session{
+		ID:         uuid.New(),
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(SessionMaxLifetime),
+	}
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken is a single-use token in a session's rotation chain. Only its hash is stored.
+type RefreshToken struct {
+	ID        uuid.UUID
+	SessionID uuid.UUID
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+func NewRefreshToken(session *Session, tokenHash string) *RefreshToken {
+	now := time.Now()
+	expiresAt := now.Add(RefreshTokenLifetime)
+	if session.ExpiresAt.Before(expiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+	return &RefreshToken{
+		ID:        uuid.New(),
+		SessionID: session.ID,
+		TokenHash: tokenHash,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+}
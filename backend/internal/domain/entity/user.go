@@ -3,6 +3,7 @@ package entity
 import (
 	"github.com/google/uuid"
 	"noroi/internal/domain/value"
+	"noroi/pkg/errors"
 	"time"
 )
 
@@ -14,31 +15,45 @@ const (
 	GenderUnknown Gender = "unknown"
 )
 
+// DeletedUserDisplayName is shown in place of the name of a deleted account
+const DeletedUserDisplayName = "削除されたユーザー"
+
+// HandleChangeCooldown is how long a user must wait between handle changes,
+// so that a handle cannot be swapped quickly to pose as someone else.
+const HandleChangeCooldown = 30 * 24 * time.Hour
+
 type User struct {
-	ID            uuid.UUID
-	Email         value.Email
-	Password      value.Password
-	Username      string
-	Age           int
-	Gender        Gender
-	CurseStyleID  uuid.UUID
-	Points        int
-	ProfilePublic bool
-	NotifyCurse   bool
-	NotifyRitual  bool
-	IsDeleted     bool
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	DeletedAt     *time.Time
+	ID              uuid.UUID
+	Email           value.Email
+	EmailVerifiedAt *time.Time
+	Password        value.Password
+	Role            value.Role
+	Username        string
+	Handle          value.Handle // empty only for deleted users
+	HandleChangedAt *time.Time
+	Age             int
+	Gender          Gender
+	CurseStyleID    uuid.UUID
+	Points          int
+	ProfilePublic   bool
+	NotifyCurse     bool
+	NotifyRitual    bool
+	IsDeleted       bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       *time.Time
 }
 
 func NewUser(email value.Email, password value.Password, username string, age int, gender Gender, curseStyleID uuid.UUID) *User {
 	now := time.Now()
+	id := uuid.New()
 	return &User{
-		ID:            uuid.New(),
+		ID:            id,
 		Email:         email,
 		Password:      password,
+		Role:          value.RoleUser,
 		Username:      username,
+		Handle:        value.GeneratedHandle(id),
 		Age:           age,
 		Gender:        gender,
 		CurseStyleID:  curseStyleID,
@@ -59,19 +74,98 @@ func (u *User) UpdateProfile(username string, age int, gender Gender) {
 	u.UpdatedAt = time.Now()
 }
 
+// ChangeHandle renames the user's @handle. Except for the first change away
+// from a generated handle, changes are limited to one per HandleChangeCooldown.
+func (u *User) ChangeHandle(handle value.Handle) error {
+	if handle == u.Handle {
+		return nil
+	}
+	now := time.Now()
+	if next := u.NextHandleChangeAt(); next != nil && now.Before(*next) {
+		return errors.ErrHandleChangeTooSoon
+	}
+	u.Handle = handle
+	u.HandleChangedAt = &now
+	u.UpdatedAt = now
+	return nil
+}
+
+// NextHandleChangeAt returns when the handle may be changed again, or nil if it can be changed now
+func (u *User) NextHandleChangeAt() *time.Time {
+	if u.HandleChangedAt == nil {
+		return nil
+	}
+	next := u.HandleChangedAt.Add(HandleChangeCooldown)
+	if !time.Now().Before(next) {
+		return nil
+	}
+	return &next
+}
+
+// SetProfilePublic controls whether other users can see the profile's stats and posts
+func (u *User) SetProfilePublic(public bool) {
+	u.ProfilePublic = public
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) UpdateNotifications(notifyCurse, notifyRitual bool) {
+	u.NotifyCurse = notifyCurse
+	u.NotifyRitual = notifyRitual
+	u.UpdatedAt = time.Now()
+}
+
 func (u *User) ChangeCurseStyle(curseStyleID uuid.UUID) {
 	u.CurseStyleID = curseStyleID
 	u.UpdatedAt = time.Now()
 }
 
-func (u *User) AddPoints(points int) {
+// AddPoints credits points. Accounts with an unverified email cannot earn points.
+func (u *User) AddPoints(points int) error {
+	if !u.IsEmailVerified() {
+		return errors.ErrEmailNotVerified
+	}
 	u.Points += points
 	u.UpdatedAt = time.Now()
+	return nil
+}
+
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u *User) VerifyEmail() {
+	now := time.Now()
+	u.EmailVerifiedAt = &now
+	u.UpdatedAt = now
+}
+
+// DisplayName is the name shown next to the user's content
+func (u *User) DisplayName() string {
+	if u.IsDeleted {
+		return DeletedUserDisplayName
+	}
+	return u.Username
 }
 
 func (u *User) Delete() {
 	now := time.Now()
+	u.Handle = ""
 	u.IsDeleted = true
 	u.DeletedAt = &now
 	u.UpdatedAt = now
 }
+
+// HasPermission reports whether the user's role grants permission
+func (u *User) HasPermission(permission value.Permission) bool {
+	return u.Role.HasPermission(permission)
+}
+
+func (u *User) ChangeRole(role value.Role) {
+	u.Role = role
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) ChangePassword(password value.Password) {
+	u.Password = password
+	u.UpdatedAt = time.Now()
+}
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MFARecoveryCodeCount is how many recovery codes are issued at a time
+	MFARecoveryCodeCount = 10
+	// MFAChallengeLifetime is how long the second login step may take
+	MFAChallengeLifetime = 5 * time.Minute
+)
+
+// UserMFA はユーザーの TOTP 設定。EnabledAt が nil の間は登録途中。
+type UserMFA struct {
+	UserID       uuid.UUID
+	Secret       string
+	EnabledAt    *time.Time
+	LastUsedStep int64 // 最後に受け付けた TOTP のタイムステップ（同じコードの再利用を防ぐ）
+	CreatedAt    time.Time
+}
+
+func NewUserMFA(userID uuid.UUID, secret string) *UserMFA {
+	return &UserMFA{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (m *UserMFA) IsEnabled() bool {
+	return m.EnabledAt != nil
+}
+
+// MFARecoveryCode は TOTP が使えないときのための使い捨てコード
+type MFARecoveryCode struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CodeHash  string
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+func NewMFARecoveryCode(userID uuid.UUID, codeHash string) *MFARecoveryCode {
+	return &MFARecoveryCode{
+		ID:        uuid.New(),
+		UserID:    userID,
+		CodeHash:  codeHash,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"time"
+
+	"noroi/internal/domain/value"
+
+	"github.com/google/uuid"
+)
+
+// UserPreferences are the settings kept in the preferences store rather than
+// in columns on users. Keys that were never saved take their default value.
+type UserPreferences struct {
+	UserID          uuid.UUID
+	DigestFrequency value.DigestFrequency
+	QuietHours      *value.QuietHours
+	Locale          value.Locale
+	Timezone        string
+}
+
+func DefaultUserPreferences(userID uuid.UUID) *UserPreferences {
+	return &UserPreferences{
+		UserID:          userID,
+		DigestFrequency: value.DigestOff,
+		Locale:          value.DefaultLocale,
+		Timezone:        value.DefaultTimezone,
+	}
+}
+
+// Location returns the user's time zone, falling back to the default one
+func (p *UserPreferences) Location() *time.Location {
+	if loc, err := time.LoadLocation(p.Timezone); err == nil {
+		return loc
+	}
+	loc, err := time.LoadLocation(value.DefaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// InQuietHours reports whether t falls inside the user's quiet hours
+func (p *UserPreferences) InQuietHours(t time.Time) bool {
+	return p.QuietHours != nil && p.QuietHours.Contains(t.In(p.Location()))
+}
+
+// QuietHoursEnd returns when the quiet hours containing t end
+func (p *UserPreferences) QuietHoursEnd(t time.Time) time.Time {
+	if p.QuietHours == nil {
+		return t
+	}
+	return p.QuietHours.EndAfter(t.In(p.Location()))
+}
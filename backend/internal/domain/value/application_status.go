@@ -10,13 +10,19 @@ const (
 	ApplicationStatusInProgress ApplicationStatus = "in_progress"
 	ApplicationStatusDone       ApplicationStatus = "done"
 	ApplicationStatusWithdrawn  ApplicationStatus = "withdrawn"
+	ApplicationStatusRejected   ApplicationStatus = "rejected"
 )
 
 func (s ApplicationStatus) Validate() error {
 	switch s {
-	case ApplicationStatusToDo, ApplicationStatusScheduled, ApplicationStatusInProgress, ApplicationStatusDone, ApplicationStatusWithdrawn:
+	case ApplicationStatusToDo, ApplicationStatusScheduled, ApplicationStatusInProgress, ApplicationStatusDone, ApplicationStatusWithdrawn, ApplicationStatusRejected:
 		return nil
 	default:
 		return errors.New("invalid application status")
 	}
 }
+
+// IsFinished reports whether the application has ended (done, withdrawn or rejected)
+func (s ApplicationStatus) IsFinished() bool {
+	return s == ApplicationStatusDone || s == ApplicationStatusWithdrawn || s == ApplicationStatusRejected
+}
@@ -0,0 +1,79 @@
+package value
+
+import (
+	"noroi/pkg/errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	HandleMinLength = 3
+	HandleMaxLength = 20
+)
+
+// reservedHandles cannot be taken by users: they are used by the service
+// itself or would let someone pose as staff.
+var reservedHandles = map[string]bool{
+	"admin": true, "administrator": true, "root": true, "system": true,
+	"support": true, "help": true, "info": true, "contact": true,
+	"noroi": true, "official": true, "staff": true, "moderator": true,
+	"mod": true, "security": true, "api": true, "www": true, "mail": true,
+	"me": true, "settings": true, "anonymous": true, "deleted": true,
+	"null": true, "undefined": true, "everyone": true, "here": true,
+}
+
+// reservedHandleWords may not appear anywhere in a handle (e.g. "noroi_admin")
+var reservedHandleWords = []string{"admin", "noroi", "official", "moderator"}
+
+// Handle is a user's unique @name. It is stored normalised: NFKC (so
+// full-width input works), lower case, without the leading "@".
+type Handle string
+
+func NewHandle(s string) (Handle, error) {
+	h := strings.ToLower(norm.NFKC.String(strings.TrimSpace(s)))
+	h = strings.TrimPrefix(h, "@")
+
+	if len(h) < HandleMinLength || len(h) > HandleMaxLength {
+		return "", errors.ErrInvalidHandle
+	}
+	for _, r := range h {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_') {
+			return "", errors.ErrInvalidHandle
+		}
+	}
+	if strings.Trim(h, "_0123456789") == "" {
+		// Handles need at least one letter
+		return "", errors.ErrInvalidHandle
+	}
+
+	if reservedHandles[h] || isGeneratedHandle(h) {
+		return "", errors.ErrHandleReserved
+	}
+	for _, word := range reservedHandleWords {
+		if strings.Contains(h, word) {
+			return "", errors.ErrHandleReserved
+		}
+	}
+
+	return Handle(h), nil
+}
+
+// GeneratedHandle is the handle given to accounts that did not choose one.
+// The "user_" + hex form cannot be chosen through NewHandle, so it never collides.
+func GeneratedHandle(userID uuid.UUID) Handle {
+	return Handle("user_" + strings.ReplaceAll(userID.String(), "-", "")[:12])
+}
+
+func isGeneratedHandle(h string) bool {
+	rest, ok := strings.CutPrefix(h, "user_")
+	if !ok || len(rest) != 12 {
+		return false
+	}
+	return strings.Trim(rest, "0123456789abcdef") == ""
+}
+
+func (h Handle) String() string {
+	return string(h)
+}
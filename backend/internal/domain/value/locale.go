@@ -0,0 +1,22 @@
+package value
+
+import "errors"
+
+type Locale string
+
+const (
+	LocaleJa Locale = "ja"
+	LocaleEn Locale = "en"
+)
+
+// DefaultLocale is used when a user has not chosen a language.
+const DefaultLocale = LocaleJa
+
+func (l Locale) Validate() error {
+	switch l {
+	case LocaleJa, LocaleEn:
+		return nil
+	default:
+		return errors.New("invalid locale")
+	}
+}
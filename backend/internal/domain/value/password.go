@@ -1,27 +1,40 @@
 package value
 
 import (
-	"golang.org/x/crypto/bcrypt"
 	"noroi/pkg/errors"
+	"noroi/pkg/passwordhash"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	PasswordMinLength = 8
+	PasswordMaxLength = 128
 )
 
 type Password struct {
 	hashedValue string
 }
 
-func NewPassword(plainPassword string) (Password, error) {
-	if len(plainPassword) < 8 {
-		return Password{}, errors.ErrPasswordTooShort
+// NewPassword validates plainPassword against the strength rules and hashes it.
+// personalInfo are values the password must not contain, such as the email
+// address and username of the account.
+func NewPassword(plainPassword string, personalInfo ...string) (Password, error) {
+	if err := validatePasswordStrength(plainPassword, personalInfo); err != nil {
+		return Password{}, err
 	}
 
-	hashed, err := bcrypt.GenerateFromPassword([]byte(plainPassword), bcrypt.DefaultCost)
+	hashed, err := passwordhash.Hash(plainPassword)
 	if err != nil {
 		return Password{}, err
 	}
 
-	return Password{hashedValue: string(hashed)}, nil
+	return Password{hashedValue: hashed}, nil
 }
 
+// NewPasswordFromHash restores a stored hash. Both Argon2id and legacy bcrypt
+// hashes are accepted.
 func NewPasswordFromHash(hashedPassword string) Password {
 	return Password{hashedValue: hashedPassword}
 }
@@ -31,6 +44,124 @@ func (p Password) Hash() string {
 }
 
 func (p Password) Compare(plainPassword string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(p.hashedValue), []byte(plainPassword))
-	return err == nil
+	return passwordhash.Verify(p.hashedValue, plainPassword)
+}
+
+// NeedsRehash reports whether the hash uses a legacy scheme or outdated
+// parameters and should be replaced after the next successful Compare
+func (p Password) NeedsRehash() bool {
+	return passwordhash.NeedsRehash(p.hashedValue)
+}
+
+// Rehash hashes an already verified password with the current scheme. Strength
+// rules are not applied, so that existing users are never locked out by them.
+func (p Password) Rehash(plainPassword string) (Password, error) {
+	hashed, err := passwordhash.Hash(plainPassword)
+	if err != nil {
+		return Password{}, err
+	}
+	return Password{hashedValue: hashed}, nil
+}
+
+// CompareDummy spends the same time as Compare against a real hash. Call it when
+// no account matches so that response timing does not reveal which emails exist.
+func CompareDummy(plainPassword string) {
+	passwordhash.VerifyDummy(plainPassword)
+}
+
+func validatePasswordStrength(plain string, personalInfo []string) error {
+	length := utf8.RuneCountInString(plain)
+	if length < PasswordMinLength {
+		return errors.ErrPasswordTooShort
+	}
+	if length > PasswordMaxLength {
+		return errors.ErrPasswordTooLong
+	}
+
+	// 英字・数字・記号のうち2種類以上を含むこと
+	var hasLetter, hasDigit, hasSymbol bool
+	for _, r := range plain {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	classes := 0
+	for _, has := range []bool{hasLetter, hasDigit, hasSymbol} {
+		if has {
+			classes++
+		}
+	}
+	if classes < 2 || isRepetitive(plain) {
+		return errors.ErrPasswordTooSimple
+	}
+
+	lower := strings.ToLower(plain)
+	if commonPasswords[lower] {
+		return errors.ErrPasswordTooCommon
+	}
+
+	for _, info := range personalInfo {
+		info = strings.ToLower(strings.TrimSpace(info))
+		// メールアドレスはローカル部で判定する
+		if at := strings.IndexByte(info, '@'); at >= 0 {
+			info = info[:at]
+		}
+		if utf8.RuneCountInString(info) >= 4 && strings.Contains(lower, info) {
+			return errors.ErrPasswordContainsPersonalInfo
+		}
+	}
+
+	return nil
+}
+
+// isRepetitive reports whether the password is a single repeated unit
+// ("abcabcabc", "11111111") or a run of consecutive characters ("12345678")
+func isRepetitive(plain string) bool {
+	runes := []rune(strings.ToLower(plain))
+
+	for unit := 1; unit <= len(runes)/2; unit++ {
+		if len(runes)%unit != 0 {
+			continue
+		}
+		repeated := true
+		for i := unit; i < len(runes); i++ {
+			if runes[i] != runes[i-unit] {
+				repeated = false
+				break
+			}
+		}
+		if repeated {
+			return true
+		}
+	}
+
+	ascending, descending := true, true
+	for i := 1; i < len(runes); i++ {
+		if runes[i] != runes[i-1]+1 {
+			ascending = false
+		}
+		if runes[i] != runes[i-1]-1 {
+			descending = false
+		}
+	}
+	return ascending || descending
+}
+
+// commonPasswords are frequently leaked passwords that satisfy the other rules
+var commonPasswords = map[string]bool{
+	"password1": true, "password12": true, "password123": true, "password1234": true,
+	"passw0rd": true, "p@ssw0rd": true, "p@ssword": true, "pa$$word": true, "p@ssw0rd1": true,
+	"qwerty123": true, "qwerty1234": true, "qwertyuiop1": true, "1q2w3e4r": true, "1q2w3e4r5t": true,
+	"abc12345": true, "abcd1234": true, "abc123456": true, "a1b2c3d4": true, "1qaz2wsx": true,
+	"zaq12wsx": true, "iloveyou1": true, "welcome1": true, "welcome123": true, "letmein1": true,
+	"admin123": true, "admin1234": true, "administrator1": true, "changeme1": true, "trustno1": true,
+	"monkey123": true, "dragon123": true, "football1": true, "baseball1": true, "master123": true,
+	"sunshine1": true, "princess1": true, "superman1": true, "starwars1": true, "michael1": true,
+	"test1234": true, "test12345": true, "asdf1234": true, "asdfgh123": true, "zxcvbnm1": true,
+	"noroi123": true, "noroi1234": true,
 }
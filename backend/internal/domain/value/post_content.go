@@ -24,6 +24,12 @@ func NewPostContent(content string) (PostContent, error) {
 	return PostContent{value: content}, nil
 }
 
+// NewPostContentFromStored restores stored content without re-validating it,
+// so that content saved under older length rules can still be read
+func NewPostContentFromStored(content string) PostContent {
+	return PostContent{value: content}
+}
+
 func (pc PostContent) String() string {
 	return pc.value
 }
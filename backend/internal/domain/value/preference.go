@@ -0,0 +1,101 @@
+package value
+
+import (
+	"noroi/pkg/errors"
+	"time"
+)
+
+// PreferenceKey names a user preference in the preferences store.
+type PreferenceKey string
+
+const (
+	PreferenceDigestFrequency PreferenceKey = "digest_frequency"
+	PreferenceQuietHours      PreferenceKey = "quiet_hours"
+	PreferenceLocale          PreferenceKey = "locale"
+	PreferenceTimezone        PreferenceKey = "timezone"
+)
+
+// DefaultTimezone is used when a user has not chosen a time zone.
+const DefaultTimezone = "Asia/Tokyo"
+
+// DigestFrequency is how often the user receives a summary mail.
+type DigestFrequency string
+
+const (
+	DigestOff    DigestFrequency = "off"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+func NewDigestFrequency(s string) (DigestFrequency, error) {
+	switch f := DigestFrequency(s); f {
+	case DigestOff, DigestDaily, DigestWeekly:
+		return f, nil
+	default:
+		return "", errors.ErrInvalidDigestFrequency
+	}
+}
+
+// QuietHours is a daily window, in the user's time zone, during which no
+// notifications are pushed. End before Start means the window spans midnight.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+const quietHoursLayout = "15:04"
+
+func NewQuietHours(start, end string) (QuietHours, error) {
+	s, err := time.Parse(quietHoursLayout, start)
+	if err != nil {
+		return QuietHours{}, errors.ErrInvalidQuietHours
+	}
+	e, err := time.Parse(quietHoursLayout, end)
+	if err != nil {
+		return QuietHours{}, errors.ErrInvalidQuietHours
+	}
+	if s.Equal(e) {
+		return QuietHours{}, errors.ErrInvalidQuietHours
+	}
+	return QuietHours{Start: s.Format(quietHoursLayout), End: e.Format(quietHoursLayout)}, nil
+}
+
+// Contains reports whether t (already converted to the user's time zone) falls inside the window
+func (q QuietHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	start, end := minuteOfDay(q.Start), minuteOfDay(q.End)
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// EndAfter returns the first end of the window after t, in t's time zone.
+// For a t inside the window this is when the window closes.
+func (q QuietHours) EndAfter(t time.Time) time.Time {
+	minute := minuteOfDay(q.End)
+	end := time.Date(t.Year(), t.Month(), t.Day(), minute/60, minute%60, 0, 0, t.Location())
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+func minuteOfDay(hhmm string) int {
+	t, err := time.Parse(quietHoursLayout, hhmm)
+	if err != nil {
+		return 0
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+// NewTimezone validates an IANA time zone name such as "Asia/Tokyo"
+func NewTimezone(name string) (string, error) {
+	if name == "" || name == "Local" {
+		return "", errors.ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return "", errors.ErrInvalidTimezone
+	}
+	return name, nil
+}
@@ -0,0 +1,72 @@
+package value
+
+import "noroi/pkg/errors"
+
+// Role is the access level of a user. Each role grants a fixed set of permissions.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// Permission is a single action that can be granted to a role
+type Permission string
+
+const (
+	// PermissionCompaniesCreate allows adding companies to the shared master table
+	PermissionCompaniesCreate Permission = "companies:create"
+	// PermissionCompaniesManage allows editing and removing master company data
+	PermissionCompaniesManage Permission = "companies:manage"
+	// PermissionPostsModerate allows hiding or removing other users' posts
+	PermissionPostsModerate Permission = "posts:moderate"
+	// PermissionUsersManage allows changing other users' roles and accounts
+	PermissionUsersManage Permission = "users:manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleUser: {
+		PermissionCompaniesCreate,
+	},
+	RoleModerator: {
+		PermissionCompaniesCreate,
+		PermissionCompaniesManage,
+		PermissionPostsModerate,
+	},
+	RoleAdmin: {
+		PermissionCompaniesCreate,
+		PermissionCompaniesManage,
+		PermissionPostsModerate,
+		PermissionUsersManage,
+	},
+}
+
+func NewRole(role string) (Role, error) {
+	r := Role(role)
+	if _, ok := rolePermissions[r]; !ok {
+		return "", errors.ErrInvalidRole
+	}
+	return r, nil
+}
+
+func (r Role) String() string {
+	return string(r)
+}
+
+// Permissions returns the permissions granted to the role
+func (r Role) Permissions() []Permission {
+	perms := rolePermissions[r]
+	result := make([]Permission, len(perms))
+	copy(result, perms)
+	return result
+}
+
+func (r Role) HasPermission(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package gateway
+
+import (
+	"context"
+	"io"
+)
+
+// FileStorage stores generated files such as data export archives. Names are
+// flat keys chosen by the caller; they never contain path separators.
+type FileStorage interface {
+	// Save writes r under name and returns the number of bytes written
+	Save(ctx context.Context, name string, r io.Reader) (int64, error)
+
+	// Open returns a reader for name. The caller closes it.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// Delete removes name. Deleting a missing file is not an error.
+	Delete(ctx context.Context, name string) error
+}
@@ -0,0 +1,36 @@
+package gateway
+
+import (
+	"context"
+	"noroi/internal/domain/value"
+)
+
+// MailTemplate names a localized mail template.
+type MailTemplate string
+
+const (
+	MailTemplateWelcome         MailTemplate = "welcome"
+	MailTemplatePasswordReset   MailTemplate = "password_reset"
+	MailTemplateVerifyEmail     MailTemplate = "verify_email"
+	MailTemplateDataExportReady MailTemplate = "data_export_ready"
+	MailTemplateReminder        MailTemplate = "reminder"
+)
+
+// Mail is a fully rendered message ready for a transport.
+type Mail struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string // optional
+}
+
+// Mailer is an outbound mail transport (SMTP, file sink, in-memory sink, ...).
+type Mailer interface {
+	Send(ctx context.Context, mail *Mail) error
+}
+
+// MailRenderer renders a template in the recipient's locale. Renderers fall
+// back to value.DefaultLocale when a template has no translation for locale.
+type MailRenderer interface {
+	Render(template MailTemplate, locale value.Locale, data any) (*Mail, error)
+}
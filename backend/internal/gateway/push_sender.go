@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"context"
+	"noroi/internal/domain/entity"
+	"time"
+)
+
+// PushUrgency is the Web Push "Urgency" header value (RFC 8030 §5.3).
+type PushUrgency string
+
+const (
+	PushUrgencyLow    PushUrgency = "low"
+	PushUrgencyNormal PushUrgency = "normal"
+	PushUrgencyHigh   PushUrgency = "high"
+)
+
+// PushMessage is a single encrypted message to one subscription.
+type PushMessage struct {
+	Payload []byte
+	TTL     time.Duration
+	Urgency PushUrgency
+	Topic   string // optional: replaces an undelivered message with the same topic
+}
+
+// PushSender delivers messages to a push service (FCM, Mozilla autopush, APNs web, ...).
+//
+// Send returns errors.ErrPushSubscriptionExpired when the push service reports the
+// subscription as gone (404/410) and wraps errors.ErrPushTemporaryFailure when the
+// attempt may succeed if retried (429, 5xx, network errors).
+type PushSender interface {
+	Send(ctx context.Context, sub *entity.PushSubscription, msg *PushMessage) error
+
+	// PublicKey returns the VAPID application server key (base64url)
+	// that browsers pass to PushManager.subscribe().
+	PublicKey() string
+
+	// ValidateEndpoint reports whether the sender is willing to deliver to endpoint.
+	ValidateEndpoint(endpoint string) error
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"noroi/internal/handler/middleware"
+	"noroi/internal/usecase"
+	"noroi/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AdminHandler struct {
+	adminUsecase *usecase.AdminUsecase
+}
+
+func NewAdminHandler(adminUsecase *usecase.AdminUsecase) *AdminHandler {
+	return &AdminHandler{
+		adminUsecase: adminUsecase,
+	}
+}
+
+// GetUser returns a user's account and role
+// GET /admin/users/:id
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	user, err := h.adminUsecase.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		if err == errors.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ChangeUserRole assigns a role to a user
+// PUT /admin/users/:id/role
+func (h *AdminHandler) ChangeUserRole(c *gin.Context) {
+	actorID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var input usecase.ChangeRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	user, err := h.adminUsecase.ChangeUserRole(c.Request.Context(), actorID, userID, input)
+	if err != nil {
+		switch err {
+		case errors.ErrInvalidRole:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role"})
+		case errors.ErrCannotChangeOwnRole:
+			c.JSON(http.StatusForbidden, gin.H{"error": "cannot change own role"})
+		case errors.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to change role"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
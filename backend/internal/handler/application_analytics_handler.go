@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"noroi/internal/handler/middleware"
+	"noroi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ApplicationAnalyticsHandler struct {
+	usecase *usecase.ApplicationAnalyticsUsecase
+}
+
+func NewApplicationAnalyticsHandler(uc *usecase.ApplicationAnalyticsUsecase) *ApplicationAnalyticsHandler {
+	return &ApplicationAnalyticsHandler{usecase: uc}
+}
+
+// GET /applications/analytics
+func (h *ApplicationAnalyticsHandler) Analytics(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	weeks := 0
+	if s := c.Query("weeks"); s != "" {
+		weeks, err = strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid weeks"})
+			return
+		}
+	}
+
+	result, err := h.usecase.Analytics(c.Request.Context(), userID, weeks)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid ") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
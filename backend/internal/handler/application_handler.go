@@ -1,15 +1,26 @@
 package handler
 
 import (
+	"io"
 	"net/http"
+	"strings"
 
+	"noroi/internal/domain/domain_service"
 	"noroi/internal/handler/middleware"
 	"noroi/internal/usecase"
+	"noroi/pkg/errors"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+const (
+	// maxICSImportSize bounds an uploaded .ics file
+	maxICSImportSize = 1 << 20
+	// maxCSVImportSize bounds an uploaded CSV file
+	maxCSVImportSize = 2 << 20
+)
+
 type ApplicationHandler struct {
 	usecase *usecase.ApplicationUsecase
 }
@@ -18,6 +29,97 @@ func NewApplicationHandler(uc *usecase.ApplicationUsecase) *ApplicationHandler {
 	return &ApplicationHandler{usecase: uc}
 }
 
+// GET /applications?date=today&category=&status=&from=&to=&sort=&limit=&offset=
+func (h *ApplicationHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit := parseIntDefault(c.Query("limit"), 20)
+	offset := parseIntDefault(c.Query("offset"), 0)
+
+	input := usecase.ListApplicationsInput{
+		Date:     c.Query("date"),
+		Category: c.Query("category"),
+		Status:   c.Query("status"),
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		Sort:     c.Query("sort"),
+		Limit:    limit,
+		Offset:   offset,
+	}
+
+	result, total, err := h.usecase.List(c.Request.Context(), userID, input)
+	if err != nil {
+		if isInvalidListQuery(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+		"pagination": gin.H{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+// isInvalidListQuery reports whether err comes from a bad list filter, sort or range
+func isInvalidListQuery(err error) bool {
+	for _, target := range []error{
+		errors.ErrInvalidApplicationCategory,
+		errors.ErrInvalidApplicationStatus,
+		errors.ErrInvalidSort,
+		errors.ErrInvalidDate,
+		errors.ErrInvalidFrom,
+		errors.ErrInvalidTo,
+		errors.ErrInvalidDateRange,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// GET /applications/:id
+func (h *ApplicationHandler) Get(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	appID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid application id"})
+		return
+	}
+
+	result, err := h.usecase.Get(c.Request.Context(), userID, appID)
+	if err != nil {
+		if err.Error() == "application not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "unauthorized" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // POST /applications
 func (h *ApplicationHandler) Create(c *gin.Context) {
 	userID, err := middleware.GetUserID(c)
@@ -27,15 +129,19 @@ func (h *ApplicationHandler) Create(c *gin.Context) {
 	}
 
 	var req struct {
-		CompanyID   string  `json:"company_id" binding:"required"`
-		Category    string  `json:"category" binding:"required"`
-		Status      string  `json:"status"`
-		ColorTag    string  `json:"color_tag"`
-		ScheduledAt *string `json:"scheduled_at"`
-		Motivation  string  `json:"motivation"`
-		WhatToDo    string  `json:"what_to_do"`
-		JobAxis     string  `json:"job_axis"`
-		Strengths   string  `json:"strengths"`
+		CompanyID           string  `json:"company_id" binding:"required"`
+		Category            string  `json:"category" binding:"required"`
+		Status              string  `json:"status"`
+		ColorTag            string  `json:"color_tag"`
+		ScheduledAt         *string `json:"scheduled_at"`
+		Motivation          string  `json:"motivation"`
+		WhatToDo            string  `json:"what_to_do"`
+		JobAxis             string  `json:"job_axis"`
+		Strengths           string  `json:"strengths"`
+		StatusFromStages    bool    `json:"status_from_stages"`
+		DurationMinutes     *int    `json:"duration_minutes"`
+		EndsAt              *string `json:"ends_at"`
+		TravelBufferMinutes *int    `json:"travel_buffer_minutes"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -44,15 +150,21 @@ func (h *ApplicationHandler) Create(c *gin.Context) {
 	}
 
 	input := usecase.CreateApplicationInput{
-		CompanyID:   req.CompanyID,
-		Category:    req.Category,
-		Status:      req.Status,
-		ColorTag:    req.ColorTag,
-		ScheduledAt: req.ScheduledAt,
-		Motivation:  req.Motivation,
-		WhatToDo:    req.WhatToDo,
-		JobAxis:     req.JobAxis,
-		Strengths:   req.Strengths,
+		CompanyID:        req.CompanyID,
+		Category:         req.Category,
+		Status:           req.Status,
+		ColorTag:         req.ColorTag,
+		ScheduledAt:      req.ScheduledAt,
+		Motivation:       req.Motivation,
+		WhatToDo:         req.WhatToDo,
+		JobAxis:          req.JobAxis,
+		Strengths:        req.Strengths,
+		StatusFromStages: req.StatusFromStages,
+		ScheduleLengthInput: usecase.ScheduleLengthInput{
+			DurationMinutes:     req.DurationMinutes,
+			EndsAt:              req.EndsAt,
+			TravelBufferMinutes: req.TravelBufferMinutes,
+		},
 	}
 
 	result, err := h.usecase.Create(c.Request.Context(), userID, input)
@@ -61,6 +173,10 @@ func (h *ApplicationHandler) Create(c *gin.Context) {
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
 		}
+		if strings.HasPrefix(err.Error(), "invalid ") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -84,14 +200,18 @@ func (h *ApplicationHandler) Update(c *gin.Context) {
 	}
 
 	var req struct {
-		Category    string  `json:"category"`
-		Status      string  `json:"status"`
-		ColorTag    string  `json:"color_tag"`
-		ScheduledAt *string `json:"scheduled_at"`
-		Motivation  string  `json:"motivation"`
-		WhatToDo    string  `json:"what_to_do"`
-		JobAxis     string  `json:"job_axis"`
-		Strengths   string  `json:"strengths"`
+		Category            string  `json:"category"`
+		Status              string  `json:"status"`
+		ColorTag            string  `json:"color_tag"`
+		ScheduledAt         *string `json:"scheduled_at"`
+		Motivation          string  `json:"motivation"`
+		WhatToDo            string  `json:"what_to_do"`
+		JobAxis             string  `json:"job_axis"`
+		Strengths           string  `json:"strengths"`
+		StatusFromStages    *bool   `json:"status_from_stages"`
+		DurationMinutes     *int    `json:"duration_minutes"`
+		EndsAt              *string `json:"ends_at"`
+		TravelBufferMinutes *int    `json:"travel_buffer_minutes"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -100,18 +220,41 @@ func (h *ApplicationHandler) Update(c *gin.Context) {
 	}
 
 	input := usecase.UpdateApplicationInput{
-		Category:    req.Category,
-		Status:      req.Status,
-		ColorTag:    req.ColorTag,
-		ScheduledAt: req.ScheduledAt,
-		Motivation:  req.Motivation,
-		WhatToDo:    req.WhatToDo,
-		JobAxis:     req.JobAxis,
-		Strengths:   req.Strengths,
+		Category:         req.Category,
+		Status:           req.Status,
+		ColorTag:         req.ColorTag,
+		ScheduledAt:      req.ScheduledAt,
+		Motivation:       req.Motivation,
+		WhatToDo:         req.WhatToDo,
+		JobAxis:          req.JobAxis,
+		Strengths:        req.Strengths,
+		StatusFromStages: req.StatusFromStages,
+		ScheduleLengthInput: usecase.ScheduleLengthInput{
+			DurationMinutes:     req.DurationMinutes,
+			EndsAt:              req.EndsAt,
+			TravelBufferMinutes: req.TravelBufferMinutes,
+		},
 	}
 
 	result, err := h.usecase.Update(c.Request.Context(), userID, appID, input)
 	if err != nil {
+		var transitionErr *domain_service.StatusTransitionError
+		if errors.As(err, &transitionErr) {
+			allowed := make([]string, 0, len(transitionErr.Allowed))
+			for _, status := range transitionErr.Allowed {
+				allowed = append(allowed, string(status))
+			}
+			c.JSON(http.StatusConflict, gin.H{
+				"error":                 err.Error(),
+				"current_status":        string(transitionErr.Current),
+				"allowed_next_statuses": allowed,
+			})
+			return
+		}
+		if errors.Is(err, errors.ErrApplicationConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		if err.Error() == "application not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -120,9 +263,345 @@ func (h *ApplicationHandler) Update(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		if strings.HasPrefix(err.Error(), "invalid ") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, result)
 }
+
+// GET /applications/:id/stages
+func (h *ApplicationHandler) ListStages(c *gin.Context) {
+	userID, appID, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.usecase.ListStages(c.Request.Context(), userID, appID)
+	if err != nil {
+		respondStageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// POST /applications/:id/stages
+func (h *ApplicationHandler) CreateStage(c *gin.Context) {
+	userID, appID, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name                string  `json:"name" binding:"required"`
+		ScheduledAt         *string `json:"scheduled_at"`
+		Status              string  `json:"status"`
+		Notes               *string `json:"notes"`
+		DurationMinutes     *int    `json:"duration_minutes"`
+		EndsAt              *string `json:"ends_at"`
+		TravelBufferMinutes *int    `json:"travel_buffer_minutes"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	input := usecase.CreateStageInput{
+		Name:        req.Name,
+		ScheduledAt: req.ScheduledAt,
+		Status:      req.Status,
+		Notes:       req.Notes,
+		ScheduleLengthInput: usecase.ScheduleLengthInput{
+			DurationMinutes:     req.DurationMinutes,
+			EndsAt:              req.EndsAt,
+			TravelBufferMinutes: req.TravelBufferMinutes,
+		},
+	}
+
+	result, err := h.usecase.AddStage(c.Request.Context(), userID, appID, input)
+	if err != nil {
+		respondStageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// PUT /applications/:id/stages/:stageId
+func (h *ApplicationHandler) UpdateStage(c *gin.Context) {
+	userID, appID, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	stageID, err := uuid.Parse(c.Param("stageId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stage id"})
+		return
+	}
+
+	var req struct {
+		Name                *string `json:"name"`
+		ScheduledAt         *string `json:"scheduled_at"`
+		Status              *string `json:"status"`
+		Notes               *string `json:"notes"`
+		DurationMinutes     *int    `json:"duration_minutes"`
+		EndsAt              *string `json:"ends_at"`
+		TravelBufferMinutes *int    `json:"travel_buffer_minutes"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	input := usecase.UpdateStageInput{
+		Name:        req.Name,
+		ScheduledAt: req.ScheduledAt,
+		Status:      req.Status,
+		Notes:       req.Notes,
+		ScheduleLengthInput: usecase.ScheduleLengthInput{
+			DurationMinutes:     req.DurationMinutes,
+			EndsAt:              req.EndsAt,
+			TravelBufferMinutes: req.TravelBufferMinutes,
+		},
+	}
+
+	result, err := h.usecase.UpdateStage(c.Request.Context(), userID, appID, stageID, input)
+	if err != nil {
+		respondStageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DELETE /applications/:id/stages/:stageId
+func (h *ApplicationHandler) DeleteStage(c *gin.Context) {
+	userID, appID, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	stageID, err := uuid.Parse(c.Param("stageId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stage id"})
+		return
+	}
+
+	result, err := h.usecase.DeleteStage(c.Request.Context(), userID, appID, stageID)
+	if err != nil {
+		respondStageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PUT /applications/:id/stages/order
+func (h *ApplicationHandler) ReorderStages(c *gin.Context) {
+	userID, appID, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		StageIDs []string `json:"stage_ids" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	result, err := h.usecase.ReorderStages(c.Request.Context(), userID, appID, req.StageIDs)
+	if err != nil {
+		respondStageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GET /schedule/conflicts?from=&to=
+func (h *ApplicationHandler) ListConflicts(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	input := usecase.ScheduleConflictsInput{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	}
+
+	conflicts, truncated, err := h.usecase.ListConflicts(c.Request.Context(), userID, input)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid ") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"conflicts": conflicts, "truncated": truncated})
+}
+
+// POST /applications/import/ics?confirm=&category=&exclude=
+func (h *ApplicationHandler) ImportICS(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	file, ok := uploadedFile(c, maxICSImportSize, "ics")
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	input := usecase.ImportICSInput{
+		File:     file,
+		Category: c.Query("category"),
+		Confirm:  c.Query("confirm") == "true",
+	}
+	for _, uids := range c.QueryArray("exclude") {
+		input.Exclude = append(input.Exclude, strings.Split(uids, ",")...)
+	}
+
+	result, err := h.usecase.ImportICS(c.Request.Context(), userID, input)
+	if err != nil {
+		respondImportError(c, err, "ics")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GET /applications/export.csv?category=&status=&date=&from=&to=&sort=
+func (h *ApplicationHandler) ExportCSV(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	input := usecase.ListApplicationsInput{
+		Date:     c.Query("date"),
+		Category: c.Query("category"),
+		Status:   c.Query("status"),
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		Sort:     c.Query("sort"),
+	}
+	streamCSV(c, "applications.csv", func(w io.Writer) error {
+		return h.usecase.ExportCSV(c.Request.Context(), userID, input, w)
+	})
+}
+
+// POST /applications/import/csv?dry_run=
+func (h *ApplicationHandler) ImportCSV(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	file, ok := uploadedFile(c, maxCSVImportSize, "csv")
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := h.usecase.ImportCSV(c.Request.Context(), userID, usecase.ImportCSVInput{
+		File:   file,
+		DryRun: c.Query("dry_run") == "true",
+	})
+	if err != nil {
+		respondImportError(c, err, "csv")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// uploadedFile returns the file sent as the multipart field "file" or as the raw
+// body, limited to maxSize, answering the request itself on failure
+func uploadedFile(c *gin.Context, maxSize int64, kind string) (io.ReadCloser, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return c.Request.Body, true
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondImportError(c, err, kind)
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return nil, false
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return nil, false
+	}
+	return f, true
+}
+
+func respondImportError(c *gin.Context, err error, kind string) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": kind + " file is too large"})
+	case errors.Is(err, errors.ErrApplicationConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "invalid "):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// applicationParams reads the user and the :id application, answering the request itself on failure
+func applicationParams(c *gin.Context) (userID, appID uuid.UUID, ok bool) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	appID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid application id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, appID, true
+}
+
+func respondStageError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "application not found", err.Error() == "stage not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err.Error() == "unauthorized":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, errors.ErrApplicationConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "invalid "):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"math"
 	"net/http"
+	"noroi/internal/handler/middleware"
 	"noroi/internal/usecase"
 	"noroi/pkg/errors"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
@@ -27,7 +31,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authUsecase.Register(c.Request.Context(), input)
+	response, err := h.authUsecase.Register(c.Request.Context(), input, clientInfo(c))
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		errorMessage := "internal server error"
@@ -39,12 +43,19 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		case errors.ErrInvalidEmail:
 			statusCode = http.StatusBadRequest
 			errorMessage = "invalid email format"
-		case errors.ErrInvalidPassword, errors.ErrPasswordTooShort:
+		case errors.ErrInvalidPassword, errors.ErrPasswordTooShort, errors.ErrPasswordTooLong,
+			errors.ErrPasswordTooSimple, errors.ErrPasswordTooCommon, errors.ErrPasswordContainsPersonalInfo:
 			statusCode = http.StatusBadRequest
 			errorMessage = err.Error()
 		case errors.ErrCurseStyleNotFound:
 			statusCode = http.StatusBadRequest
 			errorMessage = "invalid curse style"
+		case errors.ErrInvalidHandle, errors.ErrHandleReserved:
+			statusCode = http.StatusBadRequest
+			errorMessage = err.Error()
+		case errors.ErrHandleTaken:
+			statusCode = http.StatusConflict
+			errorMessage = err.Error()
 		}
 
 		c.JSON(statusCode, gin.H{"error": errorMessage})
@@ -63,12 +74,20 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authUsecase.Login(c.Request.Context(), input)
+	response, err := h.authUsecase.Login(c.Request.Context(), input, clientInfo(c))
 	if err != nil {
 		if err == errors.ErrInvalidCredentials {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
 			return
 		}
+		if err == errors.ErrInvalidEmail {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid email format"})
+			return
+		}
+
+		if respondThrottled(c, err) {
+			return
+		}
 
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
@@ -77,6 +96,37 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// VerifyMFA completes a two-step login
+// POST /auth/mfa/verify
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var input usecase.VerifyMFAInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	response, err := h.authUsecase.VerifyMFA(c.Request.Context(), input, clientInfo(c))
+	if err != nil {
+		if respondThrottled(c, err) {
+			return
+		}
+
+		switch err {
+		case errors.ErrInvalidToken:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired mfa token"})
+		case errors.ErrInvalidMFACode:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid code"})
+		case errors.ErrMFANotEnabled:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired mfa token"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // RefreshToken handles token refresh
 // POST /auth/refresh
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
@@ -89,13 +139,136 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	newAccessToken, err := h.authUsecase.RefreshToken(c.Request.Context(), request.RefreshToken)
+	tokens, err := h.authUsecase.RefreshToken(c.Request.Context(), request.RefreshToken)
+	if err != nil {
+		switch err {
+		case errors.ErrInvalidToken:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		case errors.ErrRefreshTokenReused:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token reuse detected; session revoked"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout revokes the session that owns the refresh token
+// POST /auth/logout
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var request struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh token required"})
+		return
+	}
+
+	if err := h.authUsecase.Logout(c.Request.Context(), request.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to logout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+// ListSessions lists the current user's logged-in devices
+// GET /users/me/sessions
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sessions, err := h.authUsecase.ListSessions(c.Request.Context(), userID, middleware.GetSessionID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// ListLoginEvents lists the current user's recent logins and failed attempts
+// GET /users/me/login-events
+func (h *AuthHandler) ListLoginEvents(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	events, err := h.authUsecase.ListLoginEvents(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get login events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// RevokeSession signs out one of the current user's devices
+// DELETE /users/me/sessions/:id
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	if err := h.authUsecase.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		if err == errors.ErrSessionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke session"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"access_token": newAccessToken,
-	})
+	c.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+}
+
+// RevokeOtherSessions signs out every device except the current one
+// DELETE /users/me/sessions
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.authUsecase.RevokeOtherSessions(c.Request.Context(), userID, middleware.GetSessionID(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "other sessions revoked"})
+}
+
+// respondThrottled writes 429 with Retry-After if err is a login or 2FA code lockout
+func respondThrottled(c *gin.Context, err error) bool {
+	var throttled *usecase.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many attempts; try again later"})
+	return true
+}
+
+func clientInfo(c *gin.Context) usecase.ClientInfo {
+	return usecase.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
 }
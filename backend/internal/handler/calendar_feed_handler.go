@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"noroi/internal/handler/middleware"
+	"noroi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CalendarFeedHandler struct {
+	usecase *usecase.CalendarFeedUsecase
+}
+
+func NewCalendarFeedHandler(uc *usecase.CalendarFeedUsecase) *CalendarFeedHandler {
+	return &CalendarFeedHandler{usecase: uc}
+}
+
+// GET /users/me/calendar
+func (h *CalendarFeedHandler) Get(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	result, err := h.usecase.Get(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// POST /users/me/calendar/token
+func (h *CalendarFeedHandler) Regenerate(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	result, err := h.usecase.Regenerate(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// DELETE /users/me/calendar/token
+func (h *CalendarFeedHandler) Disable(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.usecase.Disable(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GET /calendar/:token (the token in the URL is the only credential)
+func (h *CalendarFeedHandler) Feed(c *gin.Context) {
+	plainToken := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	body, err := h.usecase.Render(c.Request.Context(), plainToken)
+	if err != nil {
+		if err.Error() == "calendar feed not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render calendar"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=900")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -59,6 +62,25 @@ func (h *CompanyHandler) List(c *gin.Context) {
 	})
 }
 
+// GET /companies/export.csv?filter=my&category=&status=&search=
+func (h *CompanyHandler) ExportCSV(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	input := usecase.CompanyListInput{
+		FilterMy: strings.ToLower(c.Query("filter")) == "my",
+		Category: c.Query("category"),
+		Status:   c.Query("status"),
+		Search:   c.Query("search"),
+	}
+	streamCSV(c, "companies.csv", func(w io.Writer) error {
+		return h.usecase.ExportCSV(c.Request.Context(), userID, input, w)
+	})
+}
+
 // company creation
 func (h *CompanyHandler) CreateCompany(c *gin.Context) {
 	userID, err := middleware.GetUserID(c)
@@ -104,3 +126,29 @@ func parseIntDefault(s string, def int) int {
 	}
 	return def
 }
+
+// streamCSV sends what write produces as a CSV download. An error before the
+// first byte is answered as JSON; after that the response can only be cut short.
+func streamCSV(c *gin.Context, filename string, write func(w io.Writer) error) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+
+	err := write(c.Writer)
+	if err == nil {
+		return
+	}
+	if c.Writer.Written() {
+		log.Printf("csv export: %s aborted: %v", filename, err)
+		c.Abort()
+		return
+	}
+
+	c.Header("Content-Type", "")
+	c.Header("Content-Disposition", "")
+	if strings.HasPrefix(err.Error(), "invalid ") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
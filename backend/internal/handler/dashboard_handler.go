@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"noroi/internal/handler/middleware"
+	"noroi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DashboardHandler struct {
+	usecase *usecase.DashboardUsecase
+}
+
+func NewDashboardHandler(uc *usecase.DashboardUsecase) *DashboardHandler {
+	return &DashboardHandler{usecase: uc}
+}
+
+// GET /dashboard/today
+func (h *DashboardHandler) Today(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil || userID == (uuid.UUID{}) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	result, err := h.usecase.Today(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"noroi/internal/handler/middleware"
+	"noroi/internal/usecase"
+	"noroi/pkg/errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DataExportHandler struct {
+	dataExportUsecase *usecase.DataExportUsecase
+}
+
+func NewDataExportHandler(dataExportUsecase *usecase.DataExportUsecase) *DataExportHandler {
+	return &DataExportHandler{
+		dataExportUsecase: dataExportUsecase,
+	}
+}
+
+// RequestExport queues an export of the current user's data; the download link is sent by mail
+// POST /users/me/exports
+func (h *DataExportHandler) RequestExport(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	export, err := h.dataExportUsecase.RequestExport(c.Request.Context(), userID)
+	if err != nil {
+		switch err {
+		case errors.ErrTooManyRequests:
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "an export was requested recently; try again later"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to request export"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, export)
+}
+
+// ListExports returns the current user's recent export requests
+// GET /users/me/exports
+func (h *DataExportHandler) ListExports(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	exports, err := h.dataExportUsecase.ListExports(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get exports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exports": exports})
+}
+
+// Download streams an export archive; the token in the link is the only credential
+// GET /exports/download?token=...
+func (h *DataExportHandler) Download(c *gin.Context) {
+	plainToken := c.Query("token")
+	if plainToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	download, err := h.dataExportUsecase.Download(c.Request.Context(), plainToken)
+	if err != nil {
+		switch err {
+		case errors.ErrInvalidToken:
+			c.JSON(http.StatusNotFound, gin.H{"error": "download link is invalid or has expired"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open export"})
+		}
+		return
+	}
+	defer func() {
+		if err := download.Body.Close(); err != nil {
+			log.Printf("failed to close export archive: %v", err)
+		}
+	}()
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, download.FileName))
+	c.Header("Cache-Control", "no-store")
+	if download.SizeBytes > 0 {
+		c.Header("Content-Length", strconv.FormatInt(download.SizeBytes, 10))
+	}
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, download.Body); err != nil {
+		log.Printf("failed to stream export archive: %v", err)
+	}
+}
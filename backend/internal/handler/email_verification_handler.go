@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"noroi/internal/handler/middleware"
+	"noroi/internal/usecase"
+	"noroi/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailVerificationHandler struct {
+	emailVerificationUsecase *usecase.EmailVerificationUsecase
+}
+
+func NewEmailVerificationHandler(emailVerificationUsecase *usecase.EmailVerificationUsecase) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		emailVerificationUsecase: emailVerificationUsecase,
+	}
+}
+
+// VerifyEmail confirms an email address using the token from the verification mail
+// POST /auth/email/verify
+func (h *EmailVerificationHandler) VerifyEmail(c *gin.Context) {
+	var input usecase.VerifyEmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.emailVerificationUsecase.VerifyEmail(c.Request.Context(), input); err != nil {
+		switch err {
+		case errors.ErrInvalidToken:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired verification token"})
+		case errors.ErrEmailAlreadyVerified:
+			c.JSON(http.StatusConflict, gin.H{"error": "email already verified"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
+// ResendVerification sends a new verification mail to the current user
+// POST /auth/email/resend
+func (h *EmailVerificationHandler) ResendVerification(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.emailVerificationUsecase.ResendVerification(c.Request.Context(), userID); err != nil {
+		switch err {
+		case errors.ErrEmailAlreadyVerified:
+			c.JSON(http.StatusConflict, gin.H{"error": "email already verified"})
+		case errors.ErrTooManyRequests:
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many verification mails; try again later"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send verification mail"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "verification mail sent"})
+}
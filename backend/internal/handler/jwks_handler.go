@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+	"noroi/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	jwtManager *jwt.Manager
+}
+
+func NewJWKSHandler(jwtManager *jwt.Manager) *JWKSHandler {
+	return &JWKSHandler{
+		jwtManager: jwtManager,
+	}
+}
+
+// GetJWKS publishes the public keys that verify access tokens
+// GET /.well-known/jwks.json
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtManager.JWKS())
+}
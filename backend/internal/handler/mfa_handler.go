@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"net/http"
+	"noroi/internal/handler/middleware"
+	"noroi/internal/usecase"
+	"noroi/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAHandler struct {
+	mfaUsecase *usecase.MFAUsecase
+}
+
+func NewMFAHandler(mfaUsecase *usecase.MFAUsecase) *MFAHandler {
+	return &MFAHandler{
+		mfaUsecase: mfaUsecase,
+	}
+}
+
+// GetStatus reports whether two-factor authentication is enabled
+// GET /users/me/mfa
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	status, err := h.mfaUsecase.Status(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get two-factor authentication status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Enroll starts TOTP enrolment and returns the secret and otpauth URI
+// POST /users/me/mfa/enroll
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input usecase.EnrollMFAInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	enrollment, err := h.mfaUsecase.Enroll(c.Request.Context(), userID, input)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// Confirm enables TOTP with a code from the authenticator app and returns recovery codes
+// POST /users/me/mfa/confirm
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input usecase.MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	codes, err := h.mfaUsecase.Confirm(c.Request.Context(), userID, input)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// Disable turns two-factor authentication off
+// POST /users/me/mfa/disable
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input usecase.DisableMFAInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.mfaUsecase.Disable(c.Request.Context(), userID, input); err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes
+// POST /users/me/mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input usecase.RegenerateRecoveryCodesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	codes, err := h.mfaUsecase.RegenerateRecoveryCodes(c.Request.Context(), userID, input)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+func respondMFAError(c *gin.Context, err error) {
+	if respondThrottled(c, err) {
+		return
+	}
+
+	switch err {
+	case errors.ErrInvalidMFACode:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+	case errors.ErrInvalidCredentials:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid password"})
+	case errors.ErrMFANotEnrolled:
+		c.JSON(http.StatusConflict, gin.H{"error": "start enrolment first"})
+	case errors.ErrMFANotEnabled:
+		c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication is not enabled"})
+	case errors.ErrMFAAlreadyEnabled:
+		c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication is already enabled"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"noroi/internal/domain/value"
 	"noroi/pkg/jwt"
 	"strings"
 
@@ -13,6 +14,9 @@ const (
 	AuthorizationHeader = "Authorization"
 	BearerPrefix        = "Bearer "
 	UserIDKey           = "user_id"
+	SessionIDKey        = "session_id"
+	RoleKey             = "role"
+	PermissionsKey      = "permissions"
 )
 
 type AuthMiddleware struct {
@@ -42,7 +46,7 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, BearerPrefix)
-		claims, err := m.jwtManager.ValidateToken(tokenString)
+		claims, err := m.jwtManager.ValidateAccessToken(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			c.Abort()
@@ -50,6 +54,45 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		}
 
 		c.Set(UserIDKey, claims.UserID)
+		c.Set(SessionIDKey, claims.SessionID)
+		c.Set(RoleKey, value.Role(claims.Role))
+		permissions := make(map[value.Permission]bool, len(claims.Permissions))
+		for _, p := range claims.Permissions {
+			permissions[value.Permission(p)] = true
+		}
+		c.Set(PermissionsKey, permissions)
+		c.Next()
+	}
+}
+
+// RequireRole allows the request only when the user has one of roles.
+// It must run after RequireAuth.
+func (m *AuthMiddleware) RequireRole(roles ...value.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := GetRole(c)
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		c.Abort()
+	}
+}
+
+// RequirePermission allows the request only when the user has every one of
+// permissions. It must run after RequireAuth.
+func (m *AuthMiddleware) RequirePermission(permissions ...value.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, p := range permissions {
+			if !HasPermission(c, p) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+				c.Abort()
+				return
+			}
+		}
 		c.Next()
 	}
 }
@@ -68,3 +111,48 @@ func GetUserID(c *gin.Context) (uuid.UUID, error) {
 
 	return id, nil
 }
+
+// GetSessionID extracts the current login session ID from gin context
+func GetSessionID(c *gin.Context) uuid.UUID {
+	sessionID, exists := c.Get(SessionIDKey)
+	if !exists {
+		return uuid.Nil
+	}
+
+	id, ok := sessionID.(uuid.UUID)
+	if !ok {
+		return uuid.Nil
+	}
+
+	return id
+}
+
+// GetRole extracts the user's role from gin context
+func GetRole(c *gin.Context) value.Role {
+	role, exists := c.Get(RoleKey)
+	if !exists {
+		return ""
+	}
+
+	r, ok := role.(value.Role)
+	if !ok {
+		return ""
+	}
+
+	return r
+}
+
+// HasPermission reports whether the access token grants permission
+func HasPermission(c *gin.Context, permission value.Permission) bool {
+	permissions, exists := c.Get(PermissionsKey)
+	if !exists {
+		return false
+	}
+
+	granted, ok := permissions.(map[value.Permission]bool)
+	if !ok {
+		return false
+	}
+
+	return granted[permission]
+}
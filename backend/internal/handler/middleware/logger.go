@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,8 +13,8 @@ import (
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := c.Request.URL.Path
-		raw := c.Request.URL.RawQuery
+		path := redactPath(c.Request.URL.Path, c.Param("token"))
+		raw := redactQuery(c.Request.URL.RawQuery)
 
 		c.Next()
 
@@ -37,3 +39,25 @@ func Logger() gin.HandlerFunc {
 		)
 	}
 }
+
+// redactPath hides a credential passed as the :token path segment (e.g. the
+// calendar feed URL /calendar/:token)
+func redactPath(path, token string) string {
+	if token == "" {
+		return path
+	}
+	return strings.Replace(path, "/"+token, "/REDACTED", 1)
+}
+
+// redactQuery hides credentials passed in the query string (e.g. download links)
+func redactQuery(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	values, err := url.ParseQuery(raw)
+	if err != nil || !values.Has("token") {
+		return raw
+	}
+	values.Set("token", "REDACTED")
+	return values.Encode()
+}
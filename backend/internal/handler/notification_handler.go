@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+	"noroi/internal/handler/middleware"
+	"noroi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationUsecase *usecase.NotificationUsecase
+}
+
+func NewNotificationHandler(notificationUsecase *usecase.NotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUsecase: notificationUsecase,
+	}
+}
+
+// ListInbox returns the current user's in-app notifications, newest first
+// GET /users/me/notifications?limit=&offset=
+func (h *NotificationHandler) ListInbox(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit := parseIntDefault(c.Query("limit"), 20)
+	offset := parseIntDefault(c.Query("offset"), 0)
+
+	notifications, err := h.notificationUsecase.ListInbox(c.Request.Context(), userID, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications})
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"noroi/internal/usecase"
+	"noroi/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetHandler struct {
+	passwordResetUsecase *usecase.PasswordResetUsecase
+}
+
+func NewPasswordResetHandler(passwordResetUsecase *usecase.PasswordResetUsecase) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		passwordResetUsecase: passwordResetUsecase,
+	}
+}
+
+// ForgotPassword sends a password reset link
+// POST /auth/password/forgot
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	var input usecase.ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.passwordResetUsecase.ForgotPassword(c.Request.Context(), input); err != nil {
+		if err == errors.ErrInvalidEmail {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid email format"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Same response whether or not the email is registered
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the email is registered, a reset link has been sent"})
+}
+
+// ResetPassword sets a new password using a reset token
+// POST /auth/password/reset
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var input usecase.ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.passwordResetUsecase.ResetPassword(c.Request.Context(), input); err != nil {
+		switch err {
+		case errors.ErrInvalidToken:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired reset token"})
+		case errors.ErrInvalidPassword, errors.ErrPasswordTooShort, errors.ErrPasswordTooLong,
+			errors.ErrPasswordTooSimple, errors.ErrPasswordTooCommon, errors.ErrPasswordContainsPersonalInfo:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password has been reset"})
+}
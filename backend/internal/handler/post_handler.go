@@ -82,6 +82,15 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 		case errors.ErrPostTooLong:
 			statusCode = http.StatusBadRequest
 			errorMessage = "post must be 300 characters or less"
+		case errors.ErrInvalidPostType:
+			statusCode = http.StatusBadRequest
+			errorMessage = "invalid post type"
+		case errors.ErrAnonymousCannotJoin:
+			statusCode = http.StatusBadRequest
+			errorMessage = "anonymous posts cannot join the ritual"
+		case errors.ErrEmailNotVerified:
+			statusCode = http.StatusForbidden
+			errorMessage = "verify your email address to join the ritual"
 		}
 
 		c.JSON(statusCode, gin.H{"error": errorMessage})
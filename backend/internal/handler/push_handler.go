@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+	"noroi/internal/handler/middleware"
+	"noroi/internal/usecase"
+	"noroi/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PushHandler struct {
+	pushUsecase *usecase.PushUsecase
+}
+
+func NewPushHandler(pushUsecase *usecase.PushUsecase) *PushHandler {
+	return &PushHandler{
+		pushUsecase: pushUsecase,
+	}
+}
+
+// GetVAPIDPublicKey returns the application server key for PushManager.subscribe()
+// GET /push/vapid-public-key
+func (h *PushHandler) GetVAPIDPublicKey(c *gin.Context) {
+	key, err := h.pushUsecase.PublicKey()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "push notifications are not available"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"public_key": key})
+}
+
+// Subscribe registers the browser's push subscription for the current user
+// POST /push/subscriptions
+func (h *PushHandler) Subscribe(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input usecase.PushSubscriptionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	sub, err := h.pushUsecase.Subscribe(c.Request.Context(), userID, input, c.Request.UserAgent())
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorMessage := "failed to save push subscription"
+
+		switch err {
+		case errors.ErrInvalidPushSubscription:
+			statusCode = http.StatusBadRequest
+			errorMessage = "invalid push subscription"
+		case errors.ErrPushDisabled:
+			statusCode = http.StatusServiceUnavailable
+			errorMessage = "push notifications are not available"
+		}
+
+		c.JSON(statusCode, gin.H{"error": errorMessage})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+// Unsubscribe removes the browser's push subscription
+// DELETE /push/subscriptions
+func (h *PushHandler) Unsubscribe(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var request struct {
+		Endpoint string `json:"endpoint" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "endpoint required"})
+		return
+	}
+
+	if err := h.pushUsecase.Unsubscribe(c.Request.Context(), userID, request.Endpoint); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete push subscription"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "push subscription deleted successfully"})
+}
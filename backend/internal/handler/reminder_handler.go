@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"noroi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReminderHandler struct {
+	usecase *usecase.ReminderUsecase
+}
+
+func NewReminderHandler(uc *usecase.ReminderUsecase) *ReminderHandler {
+	return &ReminderHandler{usecase: uc}
+}
+
+// POST /applications/:id/reminders
+func (h *ReminderHandler) Create(c *gin.Context) {
+	userID, appID, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		TargetAt string `json:"target_at" binding:"required"`
+		Channel  string `json:"channel"`
+		Message  string `json:"message" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	input := usecase.CreateReminderInput{
+		TargetAt: req.TargetAt,
+		Channel:  req.Channel,
+		Message:  req.Message,
+	}
+
+	result, err := h.usecase.Create(c.Request.Context(), userID, appID, input)
+	if err != nil {
+		respondReminderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// GET /applications/:id/reminders
+func (h *ReminderHandler) List(c *gin.Context) {
+	userID, appID, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.usecase.List(c.Request.Context(), userID, appID)
+	if err != nil {
+		respondReminderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reminders": result})
+}
+
+// DELETE /applications/:id/reminders/:reminderId
+func (h *ReminderHandler) Delete(c *gin.Context) {
+	userID, appID, reminderID, ok := reminderParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.usecase.Delete(c.Request.Context(), userID, appID, reminderID); err != nil {
+		respondReminderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// POST /applications/:id/reminders/:reminderId/snooze
+func (h *ReminderHandler) Snooze(c *gin.Context) {
+	userID, appID, reminderID, ok := reminderParams(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Minutes int    `json:"minutes"`
+		Until   string `json:"until"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	input := usecase.SnoozeReminderInput{
+		Minutes: req.Minutes,
+		Until:   req.Until,
+	}
+
+	result, err := h.usecase.Snooze(c.Request.Context(), userID, appID, reminderID, input)
+	if err != nil {
+		respondReminderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// POST /applications/:id/reminders/:reminderId/ack
+func (h *ReminderHandler) Acknowledge(c *gin.Context) {
+	userID, appID, reminderID, ok := reminderParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.usecase.Acknowledge(c.Request.Context(), userID, appID, reminderID)
+	if err != nil {
+		respondReminderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func reminderParams(c *gin.Context) (userID, appID, reminderID uuid.UUID, ok bool) {
+	userID, appID, ok = applicationParams(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	reminderID, err := uuid.Parse(c.Param("reminderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reminder id"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, appID, reminderID, true
+}
+
+func respondReminderError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "application not found", err.Error() == "reminder not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err.Error() == "unauthorized":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "invalid reminder state: already acknowledged":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "invalid "):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,16 +1,29 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
+	"log"
+	"noroi/internal/domain/value"
+	"noroi/internal/gateway"
 	"noroi/internal/handler/middleware"
+	"noroi/internal/infrastructure/mailer"
 	"noroi/internal/infrastructure/repository"
+	"noroi/internal/infrastructure/storage"
+	"noroi/internal/infrastructure/webpush"
 	"noroi/internal/usecase"
+	"noroi/internal/worker"
 	"noroi/pkg/jwt"
+	"noroi/pkg/passwordhash"
+	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewRouter(db *sql.DB) *gin.Engine {
+// NewRouter wires the application and starts its background workers,
+// which stop when ctx is cancelled.
+func NewRouter(ctx context.Context, db *sql.DB) *gin.Engine {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	curseStyleRepo := repository.NewCurseStyleRepository(db)
@@ -18,31 +31,134 @@ func NewRouter(db *sql.DB) *gin.Engine {
 	curseRepo := repository.NewCurseRepository(db)
 	companyRepo := repository.NewCompanyRepository(db)
 	applicationRepo := repository.NewApplicationRepository(db)
+	pushSubscriptionRepo := repository.NewPushSubscriptionRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	mailOutboxRepo := repository.NewMailOutboxRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	emailVerificationRepo := repository.NewEmailVerificationRepository(db)
+	loginThrottleRepo := repository.NewLoginThrottleRepository(db)
+	loginEventRepo := repository.NewLoginEventRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	dataExportRepo := repository.NewDataExportRepository(db)
+	userPreferenceRepo := repository.NewUserPreferenceRepository(db)
+	reminderRepo := repository.NewReminderRepository(db)
+	calendarFeedRepo := repository.NewCalendarFeedRepository(db)
+	applicationAnalyticsRepo := repository.NewApplicationAnalyticsRepository(db)
 
 	// Initialize JWT manager
-	jwtManager := jwt.NewManager()
+	jwtManager, err := jwt.NewManager(jwt.NewConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize JWT keys: %v", err)
+	}
+
+	// Configure password hashing parameters for new and upgraded hashes
+	passwordHashConfig, err := passwordhash.NewConfig()
+	if err != nil {
+		log.Fatalf("Failed to load password hashing config: %v", err)
+	}
+	passwordhash.Configure(passwordHashConfig)
+
+	// Initialize Web Push sender (disabled when VAPID keys are not configured)
+	var pushSender gateway.PushSender
+	if sender, err := webpush.NewSender(webpush.NewConfig()); err != nil {
+		log.Printf("Web Push disabled: %v", err)
+	} else {
+		pushSender = sender
+	}
+
+	// Initialize mail transport and templates
+	mailTransport, err := mailer.New(mailer.NewConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+	appBaseURL := os.Getenv("APP_BASE_URL")
+	mailRenderer, err := mailer.NewRenderer(appBaseURL)
+	if err != nil {
+		log.Fatalf("Failed to load mail templates: %v", err)
+	}
+
+	// Initialize storage for data export archives
+	exportDir := os.Getenv("EXPORT_STORAGE_DIR")
+	if exportDir == "" {
+		exportDir = "tmp/exports"
+	}
+	exportStorage, err := storage.NewLocalStorage(exportDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize export storage: %v", err)
+	}
+	apiBaseURL := os.Getenv("API_BASE_URL")
+	if apiBaseURL == "" {
+		apiBaseURL = "http://localhost:8080"
+	}
 
 	// Initialize use cases
-	authUsecase := usecase.NewAuthUsecase(userRepo, curseStyleRepo, jwtManager)
+	mailUsecase := usecase.NewMailUsecase(mailOutboxRepo, userPreferenceRepo, mailRenderer, mailTransport)
+	pushUsecase := usecase.NewPushUsecase(pushSubscriptionRepo, pushSender)
+	notificationUsecase := usecase.NewNotificationUsecase(userRepo, notificationRepo, userPreferenceRepo, pushUsecase)
+	emailVerificationUsecase := usecase.NewEmailVerificationUsecase(userRepo, emailVerificationRepo, mailUsecase)
+	loginGuard := usecase.NewLoginGuard(loginThrottleRepo, loginEventRepo)
+	mfaUsecase := usecase.NewMFAUsecase(userRepo, mfaRepo, loginThrottleRepo)
+	authUsecase := usecase.NewAuthUsecase(userRepo, curseStyleRepo, sessionRepo, jwtManager, emailVerificationUsecase, loginGuard, mfaUsecase)
+	passwordResetUsecase := usecase.NewPasswordResetUsecase(userRepo, passwordResetRepo, sessionRepo, mailUsecase)
 	postUsecase := usecase.NewPostUsecase(postRepo, curseRepo, userRepo, curseStyleRepo)
-	curseUsecase := usecase.NewCurseUsecase(postRepo, curseRepo)
-	userUsecase := usecase.NewUserUsecase(userRepo, curseStyleRepo, postRepo)
+	curseUsecase := usecase.NewCurseUsecase(postRepo, curseRepo, notificationUsecase)
+	userUsecase := usecase.NewUserUsecase(userRepo, curseStyleRepo, postRepo, sessionRepo)
 	companyUsecase := usecase.NewCompanyUsecase(companyRepo)
-	applicationUsecase := usecase.NewApplicationUsecase(applicationRepo, companyRepo)
+	applicationUsecase := usecase.NewApplicationUsecase(applicationRepo, companyRepo, userPreferenceRepo)
+	adminUsecase := usecase.NewAdminUsecase(userRepo, sessionRepo)
+	settingsUsecase := usecase.NewSettingsUsecase(userRepo, userPreferenceRepo)
+	dataExportUsecase := usecase.NewDataExportUsecase(dataExportRepo, userRepo, exportStorage, mailUsecase, apiBaseURL)
+	reminderUsecase := usecase.NewReminderUsecase(applicationRepo, reminderRepo, userPreferenceRepo, map[value.ReminderChannel]usecase.ReminderChannel{
+		value.ReminderChannelInApp: usecase.NewInAppReminderChannel(notificationUsecase),
+		value.ReminderChannelEmail: usecase.NewEmailReminderChannel(mailUsecase),
+	})
+	calendarFeedUsecase := usecase.NewCalendarFeedUsecase(calendarFeedRepo, applicationRepo, reminderRepo, userPreferenceRepo, apiBaseURL, appBaseURL)
+	dashboardUsecase := usecase.NewDashboardUsecase(applicationRepo, reminderRepo, userPreferenceRepo)
+	applicationAnalyticsUsecase := usecase.NewApplicationAnalyticsUsecase(applicationAnalyticsRepo, userPreferenceRepo)
 
 	// Initialize handlers
 	authHandler := NewAuthHandler(authUsecase)
+	passwordResetHandler := NewPasswordResetHandler(passwordResetUsecase)
+	emailVerificationHandler := NewEmailVerificationHandler(emailVerificationUsecase)
+	mfaHandler := NewMFAHandler(mfaUsecase)
+	jwksHandler := NewJWKSHandler(jwtManager)
 	postHandler := NewPostHandler(postUsecase)
 	curseHandler := NewCurseHandler(curseUsecase)
 	userHandler := NewUserHandler(userUsecase)
 	companyHandler := NewCompanyHandler(companyUsecase)
 	applicationHandler := NewApplicationHandler(applicationUsecase)
+	pushHandler := NewPushHandler(pushUsecase)
+	notificationHandler := NewNotificationHandler(notificationUsecase)
+	adminHandler := NewAdminHandler(adminUsecase)
+	dataExportHandler := NewDataExportHandler(dataExportUsecase)
+	settingsHandler := NewSettingsHandler(settingsUsecase)
+	reminderHandler := NewReminderHandler(reminderUsecase)
+	calendarFeedHandler := NewCalendarFeedHandler(calendarFeedUsecase)
+	dashboardHandler := NewDashboardHandler(dashboardUsecase)
+	applicationAnalyticsHandler := NewApplicationAnalyticsHandler(applicationAnalyticsUsecase)
+
+	// Start background workers
+	go notificationUsecase.Run(ctx)
+	go passwordResetUsecase.Run(ctx)
+	go worker.NewRitualAnnouncer(notificationUsecase).Run(ctx)
+	go worker.NewMailDispatcher(mailUsecase).Run(ctx)
+	go worker.NewDataExporter(dataExportUsecase).Run(ctx)
+	go worker.NewReminderDispatcher(reminderUsecase).Run(ctx)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(jwtManager)
 
-	// Create router
-	router := gin.Default()
+	// Create router (gin.New rather than gin.Default: gin's own logger would
+	// print the credentials that middleware.Logger redacts)
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	// Only proxies we run may set X-Forwarded-For; otherwise the client IP used
+	// for login throttling and login history is the connection's peer address.
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Global middleware
 	router.Use(middleware.CORS())
@@ -53,6 +169,9 @@ func NewRouter(db *sql.DB) *gin.Engine {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Public keys for verifying access tokens (empty for HS256)
+	router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
@@ -61,16 +180,33 @@ func NewRouter(db *sql.DB) *gin.Engine {
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/mfa/verify", authHandler.VerifyMFA)
 			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.POST("/logout", authHandler.Logout)
+			auth.POST("/password/forgot", passwordResetHandler.ForgotPassword)
+			auth.POST("/password/reset", passwordResetHandler.ResetPassword)
+			auth.POST("/email/verify", emailVerificationHandler.VerifyEmail)
 		}
 
 		// Curse styles (no auth required for now, can be changed)
 		v1.GET("/curse-styles", userHandler.GetCurseStyles)
 
+		// Web Push application server key (no auth required)
+		v1.GET("/push/vapid-public-key", pushHandler.GetVAPIDPublicKey)
+
+		// Data export download (the token in the mailed link authenticates)
+		v1.GET("/exports/download", dataExportHandler.Download)
+
+		// iCalendar subscription feed (the secret token in the URL authenticates)
+		v1.GET("/calendar/:token", calendarFeedHandler.Feed)
+
 		// Protected routes (auth required)
 		protected := v1.Group("")
 		protected.Use(authMiddleware.RequireAuth())
 		{
+			// Resend the verification mail to the signed-in user
+			protected.POST("/auth/email/resend", emailVerificationHandler.ResendVerification)
+
 			// Post routes
 			posts := protected.Group("/posts")
 			{
@@ -87,19 +223,86 @@ func NewRouter(db *sql.DB) *gin.Engine {
 			{
 				users.GET("/me", userHandler.GetProfile)
 				users.PUT("/me", userHandler.UpdateProfile)
+				users.DELETE("/me", userHandler.DeleteAccount)
 				users.GET("/me/posts", userHandler.GetMyPosts)
+				users.POST("/me/exports", dataExportHandler.RequestExport)
+				users.GET("/me/exports", dataExportHandler.ListExports)
+				users.GET("/me/calendar", calendarFeedHandler.Get)
+				users.POST("/me/calendar/token", calendarFeedHandler.Regenerate)
+				users.DELETE("/me/calendar/token", calendarFeedHandler.Disable)
+				users.GET("/me/sessions", authHandler.ListSessions)
+				users.GET("/me/login-events", authHandler.ListLoginEvents)
+				users.GET("/me/mfa", mfaHandler.GetStatus)
+				users.POST("/me/mfa/enroll", mfaHandler.Enroll)
+				users.POST("/me/mfa/confirm", mfaHandler.Confirm)
+				users.POST("/me/mfa/disable", mfaHandler.Disable)
+				users.POST("/me/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+				users.DELETE("/me/sessions", authHandler.RevokeOtherSessions)
+				users.DELETE("/me/sessions/:id", authHandler.RevokeSession)
+				users.PUT("/me/handle", userHandler.ChangeHandle)
+				users.GET("/me/settings", settingsHandler.GetSettings)
+				users.PATCH("/me/settings", settingsHandler.UpdateSettings)
+				users.GET("/me/notifications", notificationHandler.ListInbox)
+
+				// Other users' public profiles
+				users.GET("/handle/:handle", userHandler.GetUserByHandle)
+				users.GET("/:id", userHandler.GetUser)
+				users.GET("/:id/posts", userHandler.GetUserPosts)
 			}
 
 			// Companies routes
 			protected.GET("/companies", companyHandler.List)
-			protected.POST("/companies", companyHandler.CreateCompany)
+			protected.GET("/companies/export.csv", companyHandler.ExportCSV)
+			protected.POST("/companies", authMiddleware.RequirePermission(value.PermissionCompaniesCreate), companyHandler.CreateCompany)
 
 			// Applications routes
+			protected.GET("/applications", applicationHandler.List)
+			protected.GET("/applications/export.csv", applicationHandler.ExportCSV)
+			protected.GET("/applications/analytics", applicationAnalyticsHandler.Analytics)
+			protected.GET("/applications/:id", applicationHandler.Get)
 			protected.POST("/applications", applicationHandler.Create)
+			// Imports may add companies to the shared master table
+			protected.POST("/applications/import/ics", authMiddleware.RequirePermission(value.PermissionCompaniesCreate), applicationHandler.ImportICS)
+			protected.POST("/applications/import/csv", authMiddleware.RequirePermission(value.PermissionCompaniesCreate), applicationHandler.ImportCSV)
 			protected.PUT("/applications/:id", applicationHandler.Update)
+			protected.GET("/applications/:id/stages", applicationHandler.ListStages)
+			protected.POST("/applications/:id/stages", applicationHandler.CreateStage)
+			protected.PUT("/applications/:id/stages/order", applicationHandler.ReorderStages)
+			protected.PUT("/applications/:id/stages/:stageId", applicationHandler.UpdateStage)
+			protected.DELETE("/applications/:id/stages/:stageId", applicationHandler.DeleteStage)
+			protected.GET("/applications/:id/reminders", reminderHandler.List)
+			protected.POST("/applications/:id/reminders", reminderHandler.Create)
+			protected.DELETE("/applications/:id/reminders/:reminderId", reminderHandler.Delete)
+			protected.POST("/applications/:id/reminders/:reminderId/snooze", reminderHandler.Snooze)
+			protected.POST("/applications/:id/reminders/:reminderId/ack", reminderHandler.Acknowledge)
+			protected.GET("/schedule/conflicts", applicationHandler.ListConflicts)
+			protected.GET("/dashboard/today", dashboardHandler.Today)
 
+			// Web Push subscription routes
+			protected.POST("/push/subscriptions", pushHandler.Subscribe)
+			protected.DELETE("/push/subscriptions", pushHandler.Unsubscribe)
+		}
+
+		// Admin routes (moderators and admins; each route checks its permission)
+		admin := v1.Group("/admin")
+		admin.Use(authMiddleware.RequireAuth(), authMiddleware.RequireRole(value.RoleModerator, value.RoleAdmin))
+		{
+			admin.GET("/users/:id", authMiddleware.RequirePermission(value.PermissionUsersManage), adminHandler.GetUser)
+			admin.PUT("/users/:id/role", authMiddleware.RequirePermission(value.PermissionUsersManage), adminHandler.ChangeUserRole)
 		}
 	}
 
 	return router
 }
+
+// trustedProxies reads TRUSTED_PROXIES, a comma-separated list of IPs or CIDRs.
+// It is nil when unset, i.e. the server is not behind a proxy.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"noroi/internal/handler/middleware"
+	"noroi/internal/usecase"
+	"noroi/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SettingsHandler struct {
+	settingsUsecase *usecase.SettingsUsecase
+}
+
+func NewSettingsHandler(settingsUsecase *usecase.SettingsUsecase) *SettingsHandler {
+	return &SettingsHandler{
+		settingsUsecase: settingsUsecase,
+	}
+}
+
+// GetSettings returns the current user's privacy, notification and preference settings
+// GET /users/me/settings
+func (h *SettingsHandler) GetSettings(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	settings, err := h.settingsUsecase.GetSettings(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings changes only the settings present in the request body
+// PATCH /users/me/settings
+func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input usecase.UpdateSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	settings, err := h.settingsUsecase.UpdateSettings(c.Request.Context(), userID, input)
+	if err != nil {
+		switch err {
+		case errors.ErrInvalidDigestFrequency, errors.ErrInvalidQuietHours, errors.ErrInvalidLocale, errors.ErrInvalidTimezone:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update settings"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
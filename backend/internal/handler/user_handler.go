@@ -4,8 +4,11 @@ import (
 	"net/http"
 	"noroi/internal/handler/middleware"
 	"noroi/internal/usecase"
+	"noroi/pkg/errors"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type UserHandler struct {
@@ -60,6 +63,33 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, profile)
 }
 
+// DeleteAccount deletes the current user's account
+// DELETE /users/me
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input usecase.DeleteAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.userUsecase.DeleteAccount(c.Request.Context(), userID, input); err != nil {
+		if err == errors.ErrInvalidCredentials {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid password"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "account deleted"})
+}
+
 // GetCurseStyles handles getting all curse styles
 // GET /curse-styles
 func (h *UserHandler) GetCurseStyles(c *gin.Context) {
@@ -91,3 +121,118 @@ func (h *UserHandler) GetMyPosts(c *gin.Context) {
 
 	c.JSON(http.StatusOK, posts)
 }
+
+// GetUser handles getting another user's public profile
+// GET /users/:id
+func (h *UserHandler) GetUser(c *gin.Context) {
+	viewerID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	profile, err := h.userUsecase.GetPublicProfile(c.Request.Context(), viewerID, userID)
+	if err != nil {
+		if err == errors.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// GetUserPosts handles getting another user's non-anonymous posts
+// GET /users/:id/posts
+func (h *UserHandler) GetUserPosts(c *gin.Context) {
+	viewerID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	posts, err := h.userUsecase.GetPublicPosts(c.Request.Context(), viewerID, userID, offset, limit)
+	if err != nil {
+		switch err {
+		case errors.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.ErrProfilePrivate:
+			c.JSON(http.StatusForbidden, gin.H{"error": "profile is private"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get posts"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
+// GetUserByHandle handles getting another user's public profile by @handle
+// GET /users/handle/:handle
+func (h *UserHandler) GetUserByHandle(c *gin.Context) {
+	viewerID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	profile, err := h.userUsecase.GetPublicProfileByHandle(c.Request.Context(), viewerID, c.Param("handle"))
+	if err != nil {
+		if err == errors.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ChangeHandle handles renaming the current user's @handle
+// PUT /users/me/handle
+func (h *UserHandler) ChangeHandle(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input usecase.ChangeHandleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	handle, err := h.userUsecase.ChangeHandle(c.Request.Context(), userID, input)
+	if err != nil {
+		switch err {
+		case errors.ErrInvalidHandle, errors.ErrHandleReserved:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.ErrHandleTaken, errors.ErrHandleChangeTooSoon:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to change handle"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, handle)
+}
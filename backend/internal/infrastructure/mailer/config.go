@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"noroi/internal/gateway"
+)
+
+type Transport string
+
+const (
+	TransportSMTP   Transport = "smtp"   // 本番: SMTP サーバー経由で送信
+	TransportFile   Transport = "file"   // 開発: .eml ファイルとして書き出す
+	TransportMemory Transport = "memory" // テスト: メモリ上に保持する
+)
+
+type Config struct {
+	Transport    Transport
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPTimeout  time.Duration
+	FileDir      string
+}
+
+func NewConfig() *Config {
+	port, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	if err != nil {
+		port = 587
+	}
+	return &Config{
+		Transport:    Transport(os.Getenv("MAIL_TRANSPORT")),
+		From:         getEnv("MAIL_FROM", "呪癖 <no-reply@noroi.local>"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     port,
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPTimeout:  30 * time.Second,
+		FileDir:      getEnv("MAIL_FILE_DIR", "tmp/mail"),
+	}
+}
+
+// New creates the transport selected by cfg.Transport. There is no default so
+// that a deployment missing MAIL_TRANSPORT does not quietly write mail to disk.
+func New(cfg *Config) (gateway.Mailer, error) {
+	switch cfg.Transport {
+	case "":
+		return nil, fmt.Errorf("MAIL_TRANSPORT is required (smtp, file or memory)")
+	case TransportSMTP:
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for the smtp mail transport")
+		}
+		return NewSMTPMailer(cfg), nil
+	case TransportFile:
+		return NewFileMailer(cfg.From, cfg.FileDir)
+	case TransportMemory:
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown mail transport: %q", cfg.Transport)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
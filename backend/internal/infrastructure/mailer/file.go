@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"noroi/internal/gateway"
+)
+
+// fileMailer writes each message as an .eml file, for local development.
+type fileMailer struct {
+	from string
+	dir  string
+}
+
+func NewFileMailer(from, dir string) (gateway.Mailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &fileMailer{from: from, dir: dir}, nil
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg *gateway.Mail) error {
+	body, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), uuid.New().String()[:8])
+	if err := os.WriteFile(filepath.Join(m.dir, name), body, 0o644); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+
+	"noroi/internal/gateway"
+)
+
+// MemoryMailer keeps sent messages in memory, for tests.
+type MemoryMailer struct {
+	mu   sync.Mutex
+	sent []gateway.Mail
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg *gateway.Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, *msg)
+	return nil
+}
+
+// Sent returns a copy of every message sent so far.
+func (m *MemoryMailer) Sent() []gateway.Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]gateway.Mail(nil), m.sent...)
+}
+
+// Reset discards recorded messages.
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = nil
+}
@@ -0,0 +1,102 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"time"
+
+	"noroi/internal/gateway"
+)
+
+// buildMessage encodes m as a UTF-8 MIME message. A multipart/alternative body
+// is produced when an HTML part is present.
+func buildMessage(from string, m *gateway.Mail) ([]byte, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+	toAddr, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	writeHeader("From", fromAddr.String())
+	writeHeader("To", toAddr.String())
+	writeHeader("Subject", mime.BEncoding.Encode("UTF-8", m.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", messageID(fromAddr.Address))
+	writeHeader("MIME-Version", "1.0")
+
+	if m.HTMLBody == "" {
+		writeHeader("Content-Type", `text/plain; charset="UTF-8"`)
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, m.TextBody); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	writeHeader("Content-Type", `multipart/alternative; boundary="`+mw.Boundary()+`"`)
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{`text/plain; charset="UTF-8"`, m.TextBody},
+		{`text/html; charset="UTF-8"`, m.HTMLBody},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create mime part: %w", err)
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return fmt.Errorf("failed to encode body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to encode body: %w", err)
+	}
+	return nil
+}
+
+func messageID(fromAddress string) string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	domain := "noroi.local"
+	if at := bytes.LastIndexByte([]byte(fromAddress), '@'); at >= 0 {
+		domain = fromAddress[at+1:]
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
@@ -0,0 +1,104 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"noroi/internal/domain/value"
+	"noroi/internal/gateway"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templateData is the root object every template is executed with.
+type templateData struct {
+	AppURL string // frontend base URL for links, without trailing slash
+	Data   any    // template-specific values supplied by the caller
+}
+
+// renderer renders templates/<name>.<locale>.tmpl. Each file defines the
+// "subject" and "text" blocks and optionally an "html" block.
+type renderer struct {
+	appURL string
+	text   map[string]*texttemplate.Template
+	html   map[string]*htmltemplate.Template
+}
+
+func NewRenderer(appURL string) (gateway.MailRenderer, error) {
+	entries, err := templateFS.ReadDir("templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mail templates: %w", err)
+	}
+
+	r := &renderer{
+		appURL: strings.TrimRight(appURL, "/"),
+		text:   make(map[string]*texttemplate.Template),
+		html:   make(map[string]*htmltemplate.Template),
+	}
+	for _, entry := range entries {
+		key := strings.TrimSuffix(entry.Name(), ".tmpl")
+		path := "templates/" + entry.Name()
+
+		textTmpl, err := texttemplate.ParseFS(templateFS, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mail template %s: %w", entry.Name(), err)
+		}
+		htmlTmpl, err := htmltemplate.ParseFS(templateFS, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mail template %s: %w", entry.Name(), err)
+		}
+		r.text[key] = textTmpl
+		r.html[key] = htmlTmpl
+	}
+
+	return r, nil
+}
+
+func (r *renderer) Render(template gateway.MailTemplate, locale value.Locale, data any) (*gateway.Mail, error) {
+	key := string(template) + "." + string(locale)
+	if _, ok := r.text[key]; !ok {
+		key = string(template) + "." + string(value.DefaultLocale)
+	}
+	textTmpl, ok := r.text[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown mail template: %s", template)
+	}
+	root := templateData{AppURL: r.appURL, Data: data}
+
+	subject, err := execute(textTmpl, "subject", root)
+	if err != nil {
+		return nil, err
+	}
+	text, err := execute(textTmpl, "text", root)
+	if err != nil {
+		return nil, err
+	}
+
+	mail := &gateway.Mail{
+		Subject:  strings.TrimSpace(subject),
+		TextBody: strings.TrimSpace(text) + "\n",
+	}
+
+	if htmlTmpl := r.html[key]; htmlTmpl.Lookup("html") != nil {
+		var buf bytes.Buffer
+		if err := htmlTmpl.ExecuteTemplate(&buf, "html", root); err != nil {
+			return nil, fmt.Errorf("failed to render mail template %s: %w", key, err)
+		}
+		mail.HTMLBody = buf.String()
+	}
+
+	return mail, nil
+}
+
+func execute(t *texttemplate.Template, name string, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("failed to render mail template %s/%s: %w", t.Name(), name, err)
+	}
+	return buf.String(), nil
+}
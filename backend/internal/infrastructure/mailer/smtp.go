@@ -0,0 +1,108 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"noroi/internal/gateway"
+)
+
+type smtpMailer struct {
+	from     string
+	host     string
+	port     int
+	username string
+	password string
+	timeout  time.Duration
+}
+
+func NewSMTPMailer(cfg *Config) gateway.Mailer {
+	return &smtpMailer{
+		from:     cfg.From,
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		timeout:  cfg.SMTPTimeout,
+	}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg *gateway.Mail) error {
+	body, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+	fromAddr, _ := mail.ParseAddress(m.from)
+	toAddr, _ := mail.ParseAddress(msg.To)
+
+	addr := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+	dialer := &net.Dialer{Timeout: m.timeout}
+
+	var conn net.Conn
+	if m.port == 465 {
+		// Implicit TLS (SMTPS)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: m.host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+
+	// Bound the whole SMTP conversation; net/smtp itself is not context aware.
+	deadline := time.Now().Add(m.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to set smtp deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && m.port != 465 {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return fmt.Errorf("smtp starttls failed: %w", err)
+		}
+	}
+
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(fromAddr.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	if err := client.Rcpt(toAddr.Address); err != nil {
+		return fmt.Errorf("smtp RCPT TO failed: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("failed to write smtp message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finish smtp message: %w", err)
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	repo "noroi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type applicationAnalyticsRepository struct {
+	db *sql.DB
+}
+
+func NewApplicationAnalyticsRepository(db *sql.DB) repo.ApplicationAnalyticsRepository {
+	return &applicationAnalyticsRepository{db: db}
+}
+
+func (r *applicationAnalyticsRepository) StageFunnel(ctx context.Context, userID uuid.UUID) ([]*repo.StageFunnelStep, error) {
+	// Stages are grouped by name within a category and ordered by where they
+	// usually come in an application. The time to the next stage is measured
+	// between scheduled dates, so it covers waiting for the result as well.
+	query := `
+		WITH stages AS (
+			SELECT
+				a.category,
+				btrim(s.name) AS name,
+				s.application_id,
+				s.position,
+				s.status,
+				s.scheduled_at,
+				LEAD(s.scheduled_at) OVER (PARTITION BY s.application_id ORDER BY s.position, s.id) AS next_scheduled_at
+			FROM selection_stages s
+			JOIN applications a ON a.id = s.application_id
+			WHERE a.user_id = $1
+		),
+		steps AS (
+			SELECT
+				category,
+				name,
+				AVG(position) AS avg_position,
+				COUNT(DISTINCT application_id) AS reached,
+				COUNT(DISTINCT application_id) FILTER (WHERE status = 'passed') AS passed,
+				COUNT(DISTINCT application_id) FILTER (WHERE status = 'failed') AS failed,
+				COUNT(DISTINCT application_id) FILTER (WHERE status = 'pending') AS pending,
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM next_scheduled_at - scheduled_at)::float8)
+					FILTER (WHERE status = 'passed' AND next_scheduled_at > scheduled_at) / 86400 AS median_days_to_next
+			FROM stages
+			GROUP BY category, name
+		)
+		SELECT
+			category,
+			name,
+			reached,
+			passed,
+			failed,
+			pending,
+			passed::float8 / NULLIF(passed + failed, 0),
+			reached::float8 / NULLIF(FIRST_VALUE(reached) OVER (PARTITION BY category ORDER BY avg_position, name), 0),
+			median_days_to_next
+		FROM steps
+		ORDER BY category, avg_position, name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query stage funnel: %w", err)
+	}
+	defer rows.Close()
+
+	var steps []*repo.StageFunnelStep
+	for rows.Next() {
+		var step repo.StageFunnelStep
+		if err := rows.Scan(
+			&step.Category,
+			&step.Name,
+			&step.Reached,
+			&step.Passed,
+			&step.Failed,
+			&step.Pending,
+			&step.PassRate,
+			&step.Conversion,
+			&step.MedianDaysToNext,
+		); err != nil {
+			return nil, fmt.Errorf("scan stage funnel: %w", err)
+		}
+		steps = append(steps, &step)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate stage funnel: %w", err)
+	}
+
+	return steps, nil
+}
+
+func (r *applicationAnalyticsRepository) Outcomes(ctx context.Context, userID uuid.UUID) ([]*repo.ApplicationOutcome, error) {
+	// Only a main selection ends in an offer; a done internship or info
+	// session is attendance, so those categories have no offers or offer rate.
+	query := `
+		SELECT
+			category,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status IN ('todo', 'scheduled', 'in_progress')),
+			COUNT(*) FILTER (WHERE category = 'main' AND status = 'done'),
+			COUNT(*) FILTER (WHERE status = 'rejected'),
+			COUNT(*) FILTER (WHERE status = 'withdrawn'),
+			CASE WHEN category = 'main' THEN
+				COUNT(*) FILTER (WHERE status = 'done')::float8
+					/ NULLIF(COUNT(*) FILTER (WHERE status IN ('done', 'withdrawn', 'rejected')), 0)
+			END
+		FROM applications
+		WHERE user_id = $1
+		GROUP BY category
+		ORDER BY category
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query application outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var outcomes []*repo.ApplicationOutcome
+	for rows.Next() {
+		var outcome repo.ApplicationOutcome
+		if err := rows.Scan(
+			&outcome.Category,
+			&outcome.Applications,
+			&outcome.Active,
+			&outcome.Offers,
+			&outcome.Rejected,
+			&outcome.Withdrawn,
+			&outcome.OfferRate,
+		); err != nil {
+			return nil, fmt.Errorf("scan application outcomes: %w", err)
+		}
+		outcomes = append(outcomes, &outcome)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate application outcomes: %w", err)
+	}
+
+	return outcomes, nil
+}
+
+func (r *applicationAnalyticsRepository) StatusDurations(ctx context.Context, userID uuid.UUID) ([]*repo.StatusDuration, error) {
+	query := `
+		WITH history AS (
+			SELECT
+				a.category,
+				h.to_status AS status,
+				h.changed_at,
+				LEAD(h.changed_at) OVER (PARTITION BY h.application_id ORDER BY h.changed_at, h.id) AS left_at
+			FROM application_status_history h
+			JOIN applications a ON a.id = h.application_id
+			WHERE a.user_id = $1
+		)
+		SELECT
+			category,
+			status,
+			COUNT(*),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM left_at - changed_at)::float8) / 86400
+		FROM history
+		WHERE left_at IS NOT NULL
+		GROUP BY category, status
+		ORDER BY category, status
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query status durations: %w", err)
+	}
+	defer rows.Close()
+
+	var durations []*repo.StatusDuration
+	for rows.Next() {
+		var duration repo.StatusDuration
+		if err := rows.Scan(&duration.Category, &duration.Status, &duration.Samples, &duration.MedianDays); err != nil {
+			return nil, fmt.Errorf("scan status durations: %w", err)
+		}
+		durations = append(durations, &duration)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate status durations: %w", err)
+	}
+
+	return durations, nil
+}
+
+func (r *applicationAnalyticsRepository) WeeklyActivity(ctx context.Context, userID uuid.UUID, now time.Time, timeZone string, weeks int) ([]*repo.WeeklyActivity, error) {
+	// Timestamps are stored in UTC; they are moved to the user's wall clock
+	// before being bucketed so that weeks start on their Monday.
+	query := `
+		WITH bounds AS (
+			SELECT date_trunc('week', ($2::timestamp AT TIME ZONE 'UTC') AT TIME ZONE $3) AS current_week
+		),
+		weeks AS (
+			SELECT generate_series(
+				current_week - ($4::int - 1) * INTERVAL '1 week',
+				current_week,
+				INTERVAL '1 week'
+			) AS week_start
+			FROM bounds
+		),
+		events AS (
+			SELECT 'application' AS kind, a.category, (a.created_at AT TIME ZONE 'UTC') AT TIME ZONE $3 AS at
+			FROM applications a
+			WHERE a.user_id = $1
+			UNION ALL
+			SELECT 'stage', a.category, (s.scheduled_at AT TIME ZONE 'UTC') AT TIME ZONE $3
+			FROM selection_stages s
+			JOIN applications a ON a.id = s.application_id
+			WHERE a.user_id = $1 AND s.scheduled_at IS NOT NULL
+			UNION ALL
+			SELECT h.to_status, a.category, (h.changed_at AT TIME ZONE 'UTC') AT TIME ZONE $3
+			FROM application_status_history h
+			JOIN applications a ON a.id = h.application_id
+			WHERE a.user_id = $1 AND h.from_status IS NOT NULL
+		)
+		SELECT
+			w.week_start::date,
+			COUNT(*) FILTER (WHERE e.kind = 'application'),
+			COUNT(*) FILTER (WHERE e.kind = 'stage'),
+			COUNT(*) FILTER (WHERE e.kind NOT IN ('application', 'stage')),
+			COUNT(*) FILTER (WHERE e.kind = 'done' AND e.category = 'main'),
+			COUNT(*) FILTER (WHERE e.kind = 'rejected'),
+			COUNT(*) FILTER (WHERE e.kind = 'withdrawn')
+		FROM weeks w
+		LEFT JOIN events e ON e.at >= w.week_start AND e.at < w.week_start + INTERVAL '1 week'
+		GROUP BY w.week_start
+		ORDER BY w.week_start
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, now.UTC(), timeZone, weeks)
+	if err != nil {
+		return nil, fmt.Errorf("query weekly activity: %w", err)
+	}
+	defer rows.Close()
+
+	var activity []*repo.WeeklyActivity
+	for rows.Next() {
+		var week repo.WeeklyActivity
+		if err := rows.Scan(
+			&week.WeekStart,
+			&week.Applications,
+			&week.StagesScheduled,
+			&week.StatusChanges,
+			&week.Offers,
+			&week.Rejections,
+			&week.Withdrawals,
+		); err != nil {
+			return nil, fmt.Errorf("scan weekly activity: %w", err)
+		}
+		activity = append(activity, &week)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate weekly activity: %w", err)
+	}
+
+	return activity, nil
+}
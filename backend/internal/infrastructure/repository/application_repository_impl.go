@@ -4,12 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"noroi/internal/domain/entity"
 	"noroi/internal/domain/value"
 	repo "noroi/internal/repository"
+	"noroi/pkg/errors"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type applicationRepository struct {
@@ -21,15 +25,100 @@ func NewApplicationRepository(db *sql.DB) repo.ApplicationRepository {
 }
 
 func (r *applicationRepository) Create(ctx context.Context, app *entity.Application) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertApplication(ctx, tx, app); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+func (r *applicationRepository) Update(ctx context.Context, app *entity.Application, changes ...*entity.ApplicationStatusChange) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateApplication(ctx, tx, app, changes...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	app.Version++
+	return nil
+}
+
+func (r *applicationRepository) SaveImport(ctx context.Context, imp *repo.ApplicationImport) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, company := range imp.Companies {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO companies (id, name, recruitment_url, industry, location, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`,
+			company.ID,
+			company.Name,
+			company.RecruitmentURL,
+			company.Industry,
+			company.Location,
+			company.CreatedAt,
+			company.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("insert company: %w", err)
+		}
+	}
+
+	for _, app := range imp.Created {
+		if err := insertApplication(ctx, tx, app); err != nil {
+			return err
+		}
+	}
+
+	for _, update := range imp.Updated {
+		if err := updateApplication(ctx, tx, update.Application, update.Changes...); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	for _, update := range imp.Updated {
+		update.Application.Version++
+	}
+	return nil
+}
+
+// insertApplication inserts app with its stages and records its initial status
+func insertApplication(ctx context.Context, tx *sql.Tx, app *entity.Application) error {
 	query := `
 		INSERT INTO applications (
 			id, user_id, company_id, category, status, scheduled_at,
 			color_tag, completed, motivation, what_to_do, job_axis, strengths,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			status_from_stages, duration_minutes, travel_buffer_minutes, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := tx.ExecContext(ctx, query,
 		app.ID,
 		app.UserID,
 		app.CompanyID,
@@ -42,6 +131,9 @@ func (r *applicationRepository) Create(ctx context.Context, app *entity.Applicat
 		app.WhatToDo,
 		app.JobAxis,
 		app.Strengths,
+		app.StatusFromStages,
+		durationMinutes(app.Duration),
+		int(app.TravelBuffer/time.Minute),
 		app.CreatedAt,
 		app.UpdatedAt,
 	)
@@ -49,10 +141,17 @@ func (r *applicationRepository) Create(ctx context.Context, app *entity.Applicat
 		return fmt.Errorf("insert application: %w", err)
 	}
 
-	return nil
+	if err := saveStages(ctx, tx, app); err != nil {
+		return err
+	}
+
+	initial := entity.NewApplicationStatusChange(app.ID, nil, app.Status, app.CreatedAt)
+	return insertStatusChanges(ctx, tx, initial)
 }
 
-func (r *applicationRepository) Update(ctx context.Context, app *entity.Application) error {
+// updateApplication saves app if it is still at the version it was read at,
+// then its stages and status changes. The caller bumps app.Version on commit.
+func updateApplication(ctx context.Context, tx *sql.Tx, app *entity.Application, changes ...*entity.ApplicationStatusChange) error {
 	query := `
 		UPDATE applications SET
 			category = $1,
@@ -64,11 +163,15 @@ func (r *applicationRepository) Update(ctx context.Context, app *entity.Applicat
 			what_to_do = $7,
 			job_axis = $8,
 			strengths = $9,
-			updated_at = $10
-		WHERE id = $11 AND user_id = $12
+			status_from_stages = $10,
+			duration_minutes = $11,
+			travel_buffer_minutes = $12,
+			updated_at = $13,
+			version = version + 1
+		WHERE id = $14 AND user_id = $15 AND version = $16
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, query,
 		app.Category,
 		app.Status,
 		app.ScheduledAt,
@@ -78,9 +181,13 @@ func (r *applicationRepository) Update(ctx context.Context, app *entity.Applicat
 		app.WhatToDo,
 		app.JobAxis,
 		app.Strengths,
+		app.StatusFromStages,
+		durationMinutes(app.Duration),
+		int(app.TravelBuffer/time.Minute),
 		app.UpdatedAt,
 		app.ID,
 		app.UserID,
+		app.Version,
 	)
 	if err != nil {
 		return fmt.Errorf("update application: %w", err)
@@ -92,41 +199,221 @@ func (r *applicationRepository) Update(ctx context.Context, app *entity.Applicat
 	}
 
 	if rowsAffected == 0 {
+		// 行が残っていれば、読み込んだ後に他の更新が先に保存されている
+		var exists bool
+		err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS(SELECT 1 FROM applications WHERE id = $1 AND user_id = $2)`,
+			app.ID, app.UserID,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("check application: %w", err)
+		}
+		if exists {
+			return errors.ErrApplicationConflict
+		}
 		return fmt.Errorf("application not found or unauthorized")
 	}
 
+	if err := saveStages(ctx, tx, app); err != nil {
+		return err
+	}
+
+	return insertStatusChanges(ctx, tx, changes...)
+}
+
+func insertStatusChanges(ctx context.Context, tx *sql.Tx, changes ...*entity.ApplicationStatusChange) error {
+	query := `
+		INSERT INTO application_status_history (id, application_id, from_status, to_status, changed_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	for _, change := range changes {
+		if change == nil {
+			continue
+		}
+		_, err := tx.ExecContext(ctx, query,
+			change.ID,
+			change.ApplicationID,
+			change.FromStatus,
+			change.ToStatus,
+			change.ChangedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("insert status history: %w", err)
+		}
+	}
+
 	return nil
 }
 
-func (r *applicationRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Application, error) {
+// saveStages makes selection_stages match app.Stages: stages no longer in the
+// aggregate are deleted and the rest are inserted or updated. Update calls it
+// only after the version check, so a stale aggregate cannot drop stages that
+// another request saved in the meantime.
+func saveStages(ctx context.Context, tx *sql.Tx, app *entity.Application) error {
+	ids := make([]string, 0, len(app.Stages))
+	for _, stage := range app.Stages {
+		ids = append(ids, stage.ID.String())
+	}
+
+	_, err := tx.ExecContext(ctx,
+		`DELETE FROM selection_stages WHERE application_id = $1 AND NOT (id = ANY($2::uuid[]))`,
+		app.ID, pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("delete selection stages: %w", err)
+	}
+
+	query := `
+		INSERT INTO selection_stages (
+			id, application_id, name, scheduled_at, duration_minutes, travel_buffer_minutes,
+			status, notes, position, external_uid, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			scheduled_at = EXCLUDED.scheduled_at,
+			duration_minutes = EXCLUDED.duration_minutes,
+			travel_buffer_minutes = EXCLUDED.travel_buffer_minutes,
+			status = EXCLUDED.status,
+			notes = EXCLUDED.notes,
+			position = EXCLUDED.position,
+			external_uid = EXCLUDED.external_uid,
+			updated_at = EXCLUDED.updated_at
+		WHERE selection_stages.application_id = EXCLUDED.application_id
+	`
+
+	for _, stage := range app.Stages {
+		_, err := tx.ExecContext(ctx, query,
+			stage.ID,
+			app.ID,
+			stage.Name,
+			stage.ScheduledAt,
+			durationMinutes(stage.Duration),
+			int(stage.TravelBuffer/time.Minute),
+			stage.Status,
+			stage.Notes,
+			stage.Position,
+			stage.ExternalUID,
+			stage.CreatedAt,
+			stage.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("save selection stage: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadStages fills in the stages of apps with one query
+func (r *applicationRepository) loadStages(ctx context.Context, apps ...*entity.Application) error {
+	if len(apps) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(apps))
+	byID := make(map[uuid.UUID]*entity.Application, len(apps))
+	for _, app := range apps {
+		ids = append(ids, app.ID.String())
+		byID[app.ID] = app
+		app.Stages = nil
+	}
+
 	query := `
 		SELECT
-			id, user_id, company_id, category, status, scheduled_at,
-			color_tag, completed, motivation, what_to_do, job_axis, strengths,
-			created_at, updated_at
-		FROM applications
-		WHERE id = $1
+			id, application_id, name, scheduled_at, duration_minutes, travel_buffer_minutes,
+			status, notes, position, external_uid, created_at, updated_at
+		FROM selection_stages
+		WHERE application_id = ANY($1::uuid[])
+		ORDER BY application_id, position, created_at, id
 	`
 
-	var app entity.Application
-	var scheduledAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&app.ID,
-		&app.UserID,
-		&app.CompanyID,
-		&app.Category,
-		&app.Status,
-		&scheduledAt,
-		&app.ColorTag,
-		&app.Completed,
-		&app.Motivation,
-		&app.WhatToDo,
-		&app.JobAxis,
-		&app.Strengths,
-		&app.CreatedAt,
-		&app.UpdatedAt,
-	)
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("query selection stages: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			stage        entity.SelectionStage
+			scheduledAt  sql.NullTime
+			duration     sql.NullInt64
+			travelBuffer int
+			notes        sql.NullString
+			externalUID  sql.NullString
+		)
+		err := rows.Scan(
+			&stage.ID, &stage.ApplicationID, &stage.Name, &scheduledAt, &duration, &travelBuffer,
+			&stage.Status, &notes, &stage.Position, &externalUID, &stage.CreatedAt, &stage.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("scan selection stages: %w", err)
+		}
+		if scheduledAt.Valid {
+			stage.ScheduledAt = &scheduledAt.Time
+		}
+		stage.Duration = durationFromMinutes(duration)
+		stage.TravelBuffer = time.Duration(travelBuffer) * time.Minute
+		if notes.Valid {
+			stage.Notes = &notes.String
+		}
+		if externalUID.Valid {
+			stage.ExternalUID = &externalUID.String
+		}
+		if app, ok := byID[stage.ApplicationID]; ok {
+			app.Stages = append(app.Stages, stage)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate selection stages: %w", err)
+	}
+
+	return nil
+}
+
+func (r *applicationRepository) FindStatusHistory(ctx context.Context, applicationID uuid.UUID) ([]*entity.ApplicationStatusChange, error) {
+	query := `
+		SELECT id, application_id, from_status, to_status, changed_at
+		FROM application_status_history
+		WHERE application_id = $1
+		ORDER BY changed_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("query status history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []*entity.ApplicationStatusChange
+	for rows.Next() {
+		var (
+			change     entity.ApplicationStatusChange
+			fromStatus sql.NullString
+		)
+		if err := rows.Scan(&change.ID, &change.ApplicationID, &fromStatus, &change.ToStatus, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan status history: %w", err)
+		}
+		if fromStatus.Valid {
+			from := value.ApplicationStatus(fromStatus.String)
+			change.FromStatus = &from
+		}
+		history = append(history, &change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate status history: %w", err)
+	}
+
+	return history, nil
+}
+
+func (r *applicationRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Application, error) {
+	query := `SELECT ` + applicationColumns + ` FROM applications a WHERE a.id = $1`
+
+	app, err := scanApplication(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("application not found")
 	}
@@ -134,42 +421,21 @@ func (r *applicationRepository) FindByID(ctx context.Context, id uuid.UUID) (*en
 		return nil, fmt.Errorf("query application: %w", err)
 	}
 
-	if scheduledAt.Valid {
-		app.ScheduledAt = &scheduledAt.Time
+	if err := r.loadStages(ctx, app); err != nil {
+		return nil, err
 	}
 
-	return &app, nil
+	return app, nil
 }
 
 func (r *applicationRepository) FindByUserAndCompany(ctx context.Context, userID, companyID uuid.UUID, category value.ApplicationCategory) (*entity.Application, error) {
 	query := `
-		SELECT
-			id, user_id, company_id, category, status, scheduled_at,
-			color_tag, completed, motivation, what_to_do, job_axis, strengths,
-			created_at, updated_at
-		FROM applications
-		WHERE user_id = $1 AND company_id = $2 AND category = $3
+		SELECT ` + applicationColumns + `
+		FROM applications a
+		WHERE a.user_id = $1 AND a.company_id = $2 AND a.category = $3
 	`
 
-	var app entity.Application
-	var scheduledAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, userID, companyID, category).Scan(
-		&app.ID,
-		&app.UserID,
-		&app.CompanyID,
-		&app.Category,
-		&app.Status,
-		&scheduledAt,
-		&app.ColorTag,
-		&app.Completed,
-		&app.Motivation,
-		&app.WhatToDo,
-		&app.JobAxis,
-		&app.Strengths,
-		&app.CreatedAt,
-		&app.UpdatedAt,
-	)
+	app, err := scanApplication(r.db.QueryRowContext(ctx, query, userID, companyID, category))
 	if err == sql.ErrNoRows {
 		return nil, nil // Not found is not an error
 	}
@@ -177,9 +443,300 @@ func (r *applicationRepository) FindByUserAndCompany(ctx context.Context, userID
 		return nil, fmt.Errorf("query application: %w", err)
 	}
 
+	if err := r.loadStages(ctx, app); err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (r *applicationRepository) FindByStageExternalUID(ctx context.Context, userID uuid.UUID, externalUID string) (*repo.ApplicationWithCompany, error) {
+	query := `
+		SELECT ` + applicationColumns + `,
+			c.id, c.name, c.recruitment_url, c.industry, c.location, c.created_at, c.updated_at
+		FROM applications a
+		JOIN companies c ON c.id = a.company_id
+		WHERE a.user_id = $1
+			AND EXISTS (SELECT 1 FROM selection_stages s WHERE s.application_id = a.id AND s.external_uid = $2)
+		ORDER BY a.created_at, a.id
+		LIMIT 1
+	`
+
+	var company entity.Company
+	app, err := scanApplication(r.db.QueryRowContext(ctx, query, userID, externalUID),
+		&company.ID, &company.Name, &company.RecruitmentURL, &company.Industry, &company.Location,
+		&company.CreatedAt, &company.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query application by stage uid: %w", err)
+	}
+
+	if err := r.loadStages(ctx, app); err != nil {
+		return nil, err
+	}
+
+	return &repo.ApplicationWithCompany{Application: app, Company: &company}, nil
+}
+
+func (r *applicationRepository) List(ctx context.Context, q repo.ApplicationQuery) ([]*repo.ApplicationWithCompany, int, error) {
+	params := []interface{}{q.UserID}
+	where := []string{"a.user_id = $1"}
+
+	if q.Category != "" {
+		params = append(params, q.Category)
+		where = append(where, fmt.Sprintf("a.category = $%d", len(params)))
+	}
+	if q.Status != "" {
+		params = append(params, q.Status)
+		where = append(where, fmt.Sprintf("a.status = $%d", len(params)))
+	}
+	if q.ScheduledFrom != nil {
+		params = append(params, q.ScheduledFrom.UTC())
+		where = append(where, fmt.Sprintf("a.scheduled_at >= $%d", len(params)))
+	}
+	if q.ScheduledUntil != nil {
+		params = append(params, q.ScheduledUntil.UTC())
+		where = append(where, fmt.Sprintf("a.scheduled_at < $%d", len(params)))
+	}
+
+	// limit & offset
+	limit := q.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	offset := q.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	params = append(params, limit, offset)
+
+	direction := "ASC"
+	if q.Descending {
+		direction = "DESC"
+	}
+
+	query := `
+SELECT ` + applicationColumns + `,
+  c.id, c.name, c.recruitment_url, c.industry, c.location, c.created_at, c.updated_at,
+  COUNT(*) OVER () AS total
+FROM applications a
+JOIN companies c ON c.id = a.company_id
+WHERE ` + strings.Join(where, " AND ") + `
+ORDER BY a.scheduled_at ` + direction + ` NULLS LAST, a.created_at, a.id
+LIMIT $` + fmt.Sprintf("%d", len(params)-1) + ` OFFSET $` + fmt.Sprintf("%d", len(params))
+
+	rows, err := r.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query applications: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		results []*repo.ApplicationWithCompany
+		total   int
+	)
+	for rows.Next() {
+		var company entity.Company
+
+		app, err := scanApplication(rows,
+			&company.ID, &company.Name, &company.RecruitmentURL, &company.Industry, &company.Location,
+			&company.CreatedAt, &company.UpdatedAt,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan applications: %w", err)
+		}
+
+		results = append(results, &repo.ApplicationWithCompany{
+			Application: app,
+			Company:     &company,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate applications: %w", err)
+	}
+
+	// A page past the end has no rows to carry the window count
+	if len(results) == 0 && offset > 0 {
+		countQuery := `SELECT COUNT(*) FROM applications a WHERE ` + strings.Join(where, " AND ")
+		if err := r.db.QueryRowContext(ctx, countQuery, params[:len(params)-2]...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("count applications: %w", err)
+		}
+	}
+
+	return results, total, nil
+}
+
+// calendarFeedLimit bounds the applications exported to a calendar feed
+const calendarFeedLimit = 500
+
+func (r *applicationRepository) FindScheduled(ctx context.Context, userID uuid.UUID, from time.Time, until *time.Time, limit int) ([]*repo.ApplicationWithCompany, bool, error) {
+	return r.findScheduled(ctx, userID, from, until, true, limit)
+}
+
+func (r *applicationRepository) FindForCalendar(ctx context.Context, userID uuid.UUID, since time.Time) ([]*repo.ApplicationWithCompany, error) {
+	results, _, err := r.findScheduled(ctx, userID, since, nil, false, calendarFeedLimit)
+	return results, err
+}
+
+// findScheduled loads up to limit applications scheduled, or with a stage
+// scheduled, in [from, until), and reports whether more matched. openOnly
+// leaves out finished applications and stages. Applications are ordered by
+// their first event in the range, so a cut-off result keeps the earliest ones
+// whether they were found by their own date or a stage.
+func (r *applicationRepository) findScheduled(ctx context.Context, userID uuid.UUID, from time.Time, until *time.Time, openOnly bool, limit int) ([]*repo.ApplicationWithCompany, bool, error) {
+	query := `
+SELECT ` + applicationColumns + `,
+  c.id, c.name, c.recruitment_url, c.industry, c.location, c.created_at, c.updated_at
+FROM applications a
+JOIN companies c ON c.id = a.company_id
+CROSS JOIN LATERAL (
+  SELECT LEAST(
+    CASE WHEN a.scheduled_at >= $2 AND ($3::timestamp IS NULL OR a.scheduled_at < $3) THEN a.scheduled_at END,
+    (
+      SELECT MIN(s.scheduled_at) FROM selection_stages s
+      WHERE s.application_id = a.id
+        AND (NOT $4 OR s.status = 'pending')
+        AND s.scheduled_at >= $2
+        AND ($3::timestamp IS NULL OR s.scheduled_at < $3)
+    )
+  ) AS first_at
+) e
+WHERE a.user_id = $1
+  AND (NOT $4 OR a.status NOT IN ('done', 'withdrawn', 'rejected'))
+  AND e.first_at IS NOT NULL
+ORDER BY e.first_at, a.created_at, a.id
+LIMIT $5`
+
+	var untilParam interface{}
+	if until != nil {
+		untilParam = until.UTC()
+	}
+
+	// One extra row tells whether the result was cut off
+	rows, err := r.db.QueryContext(ctx, query, userID, from.UTC(), untilParam, openOnly, limit+1)
+	if err != nil {
+		return nil, false, fmt.Errorf("query scheduled applications: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		results []*repo.ApplicationWithCompany
+		apps    []*entity.Application
+	)
+	for rows.Next() {
+		var company entity.Company
+
+		app, err := scanApplication(rows,
+			&company.ID, &company.Name, &company.RecruitmentURL, &company.Industry, &company.Location,
+			&company.CreatedAt, &company.UpdatedAt,
+		)
+		if err != nil {
+			return nil, false, fmt.Errorf("scan scheduled applications: %w", err)
+		}
+
+		results = append(results, &repo.ApplicationWithCompany{Application: app, Company: &company})
+		apps = append(apps, app)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("iterate scheduled applications: %w", err)
+	}
+
+	truncated := len(results) > limit
+	if truncated {
+		results, apps = results[:limit], apps[:limit]
+	}
+
+	if err := r.loadStages(ctx, apps...); err != nil {
+		return nil, false, err
+	}
+
+	return results, truncated, nil
+}
+
+func (r *applicationRepository) CountByCategoryAndStatus(ctx context.Context, userID uuid.UUID) ([]*repo.ApplicationCount, error) {
+	query := `
+		SELECT category, status, COUNT(*)
+		FROM applications
+		WHERE user_id = $1
+		GROUP BY category, status
+		ORDER BY category, status
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("count applications: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*repo.ApplicationCount
+	for rows.Next() {
+		var count repo.ApplicationCount
+		if err := rows.Scan(&count.Category, &count.Status, &count.Count); err != nil {
+			return nil, fmt.Errorf("scan application counts: %w", err)
+		}
+		counts = append(counts, &count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate application counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+const applicationColumns = `
+	a.id, a.user_id, a.company_id, a.category, a.status, a.scheduled_at,
+	a.duration_minutes, a.travel_buffer_minutes, a.color_tag, a.completed,
+	COALESCE(a.motivation, ''), COALESCE(a.what_to_do, ''), COALESCE(a.job_axis, ''), COALESCE(a.strengths, ''),
+	a.status_from_stages, a.version, a.created_at, a.updated_at
+`
+
+// scanApplication scans applicationColumns followed by any extra destinations
+func scanApplication(row rowScanner, extra ...any) (*entity.Application, error) {
+	var (
+		app          entity.Application
+		scheduledAt  sql.NullTime
+		duration     sql.NullInt64
+		travelBuffer int
+	)
+
+	dest := []any{
+		&app.ID, &app.UserID, &app.CompanyID, &app.Category, &app.Status, &scheduledAt,
+		&duration, &travelBuffer, &app.ColorTag, &app.Completed,
+		&app.Motivation, &app.WhatToDo, &app.JobAxis, &app.Strengths,
+		&app.StatusFromStages, &app.Version, &app.CreatedAt, &app.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
 	if scheduledAt.Valid {
 		app.ScheduledAt = &scheduledAt.Time
 	}
+	app.Duration = durationFromMinutes(duration)
+	app.TravelBuffer = time.Duration(travelBuffer) * time.Minute
 
 	return &app, nil
 }
+
+func durationMinutes(d *time.Duration) *int {
+	if d == nil {
+		return nil
+	}
+	minutes := int(*d / time.Minute)
+	return &minutes
+}
+
+func durationFromMinutes(minutes sql.NullInt64) *time.Duration {
+	if !minutes.Valid {
+		return nil
+	}
+	d := time.Duration(minutes.Int64) * time.Minute
+	return &d
+}
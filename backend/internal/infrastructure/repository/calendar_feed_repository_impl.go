@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"noroi/internal/domain/entity"
+	repo "noroi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type calendarFeedRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarFeedRepository(db *sql.DB) repo.CalendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+func (r *calendarFeedRepository) Save(ctx context.Context, feed *entity.CalendarFeed) error {
+	query := `
+		INSERT INTO calendar_feeds (user_id, token_hash, created_at, last_accessed_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET
+			token_hash = EXCLUDED.token_hash,
+			created_at = EXCLUDED.created_at,
+			last_accessed_at = EXCLUDED.last_accessed_at
+	`
+	if _, err := r.db.ExecContext(ctx, query, feed.UserID, feed.TokenHash, feed.CreatedAt.UTC(), utcPtr(feed.LastAccessedAt)); err != nil {
+		return fmt.Errorf("save calendar feed: %w", err)
+	}
+	return nil
+}
+
+func (r *calendarFeedRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*entity.CalendarFeed, error) {
+	return r.findOne(ctx, `WHERE user_id = $1`, userID)
+}
+
+func (r *calendarFeedRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.CalendarFeed, error) {
+	return r.findOne(ctx, `WHERE token_hash = $1`, tokenHash)
+}
+
+func (r *calendarFeedRepository) findOne(ctx context.Context, where string, arg any) (*entity.CalendarFeed, error) {
+	query := `
+		SELECT user_id, token_hash, created_at, last_accessed_at
+		FROM calendar_feeds
+	` + where
+
+	var (
+		feed           entity.CalendarFeed
+		lastAccessedAt sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(&feed.UserID, &feed.TokenHash, &feed.CreatedAt, &lastAccessedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query calendar feed: %w", err)
+	}
+
+	if lastAccessedAt.Valid {
+		feed.LastAccessedAt = &lastAccessedAt.Time
+	}
+	return &feed, nil
+}
+
+func (r *calendarFeedRepository) RecordAccess(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE calendar_feeds SET last_accessed_at = $2 WHERE user_id = $1`, userID, at.UTC()); err != nil {
+		return fmt.Errorf("update calendar feed: %w", err)
+	}
+	return nil
+}
+
+func (r *calendarFeedRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM calendar_feeds WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("delete calendar feed: %w", err)
+	}
+	return nil
+}
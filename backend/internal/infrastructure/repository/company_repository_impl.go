@@ -67,7 +67,7 @@ FROM companies c
 LEFT JOIN applications a
   ON ` + strings.Join(joinConditions, " AND ") + `
 ` + whereClause + `
-ORDER BY c.name
+ORDER BY c.name, c.id, a.category
 LIMIT $` + fmt.Sprintf("%d", len(params)-1) + ` OFFSET $` + fmt.Sprintf("%d", len(params))
 
 	rows, err := r.db.QueryContext(ctx, query, params...)
@@ -146,6 +146,27 @@ LIMIT $` + fmt.Sprintf("%d", len(params)-1) + ` OFFSET $` + fmt.Sprintf("%d", le
 	return results, nil
 }
 
+func (r *companyRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Company, error) {
+	query := `
+		SELECT id, name, recruitment_url, industry, location, created_at, updated_at
+		FROM companies
+		WHERE id = $1
+	`
+
+	var c entity.Company
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&c.ID, &c.Name, &c.RecruitmentURL, &c.Industry, &c.Location, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query company: %w", err)
+	}
+
+	return &c, nil
+}
+
 func (r *companyRepository) Create(ctx context.Context, company *entity.Company) error {
 	query := `
 		INSERT INTO companies (id, name, recruitment_url, industry, location, created_at, updated_at)
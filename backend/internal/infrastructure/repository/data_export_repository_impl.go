@@ -0,0 +1,449 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type dataExportRepository struct {
+	db *sql.DB
+}
+
+func NewDataExportRepository(db *sql.DB) repository.DataExportRepository {
+	return &dataExportRepository{db: db}
+}
+
+const dataExportColumns = `
+	id, user_id, status, attempts, next_attempt_at, file_name, size_bytes,
+	download_token_hash, last_error, created_at, updated_at, completed_at, expires_at
+`
+
+func (r *dataExportRepository) Create(ctx context.Context, export *entity.DataExport) error {
+	query := `
+		INSERT INTO data_exports (id, user_id, status, attempts, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		export.ID, export.UserID, export.Status, export.Attempts, export.NextAttemptAt,
+		export.CreatedAt, export.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create data export: %w", err)
+	}
+	return nil
+}
+
+func (r *dataExportRepository) FindByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + `
+		FROM data_exports
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	return r.queryExports(ctx, query, userID, limit)
+}
+
+func (r *dataExportRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE download_token_hash = $1`
+	export, err := scanDataExport(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find data export: %w", err)
+	}
+	return export, nil
+}
+
+func (r *dataExportRepository) ClaimNext(ctx context.Context, lease time.Duration, maxAttempts int) (*entity.DataExport, error) {
+	now := time.Now()
+
+	// A job still processing after its lease crashed its worker; stop retrying
+	// it once every attempt has been used so that it cannot crash workers forever
+	giveUp := `
+		UPDATE data_exports
+		SET status = 'failed', last_error = 'export did not finish', completed_at = $1
+		WHERE status = 'processing' AND next_attempt_at <= $1 AND attempts >= $2
+	`
+	if _, err := r.db.ExecContext(ctx, giveUp, now, maxAttempts); err != nil {
+		return nil, fmt.Errorf("failed to give up data exports: %w", err)
+	}
+
+	query := `
+		UPDATE data_exports
+		SET status = 'processing', attempts = attempts + 1, next_attempt_at = $1
+		WHERE id = (
+			SELECT id FROM data_exports
+			WHERE status IN ('pending', 'processing') AND next_attempt_at <= $2 AND attempts < $3
+			ORDER BY next_attempt_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + dataExportColumns
+	export, err := scanDataExport(r.db.QueryRowContext(ctx, query, now.Add(lease), now, maxAttempts))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim data export: %w", err)
+	}
+	return export, nil
+}
+
+func (r *dataExportRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]*entity.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + `
+		FROM data_exports
+		WHERE status = 'ready' AND expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+	return r.queryExports(ctx, query, now, limit)
+}
+
+func (r *dataExportRepository) Update(ctx context.Context, export *entity.DataExport) error {
+	query := `
+		UPDATE data_exports
+		SET status = $1, next_attempt_at = $2, file_name = $3, size_bytes = $4,
+			download_token_hash = $5, last_error = $6, completed_at = $7, expires_at = $8,
+			updated_at = $9
+		WHERE id = $10
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		export.Status, export.NextAttemptAt, export.FileName, export.SizeBytes,
+		export.DownloadTokenHash, export.LastError, export.CompletedAt, export.ExpiresAt,
+		export.UpdatedAt, export.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update data export: %w", err)
+	}
+	return nil
+}
+
+func (r *dataExportRepository) queryExports(ctx context.Context, query string, args ...any) ([]*entity.DataExport, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data exports: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	var exports []*entity.DataExport
+	for rows.Next() {
+		export, err := scanDataExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		exports = append(exports, export)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return exports, nil
+}
+
+func scanDataExport(row rowScanner) (*entity.DataExport, error) {
+	var export entity.DataExport
+	var fileName, tokenHash, lastError sql.NullString
+	var sizeBytes sql.NullInt64
+	var completedAt, expiresAt sql.NullTime
+
+	err := row.Scan(
+		&export.ID, &export.UserID, &export.Status, &export.Attempts, &export.NextAttemptAt,
+		&fileName, &sizeBytes, &tokenHash, &lastError,
+		&export.CreatedAt, &export.UpdatedAt, &completedAt, &expiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if fileName.Valid {
+		export.FileName = &fileName.String
+	}
+	if sizeBytes.Valid {
+		export.SizeBytes = &sizeBytes.Int64
+	}
+	if tokenHash.Valid {
+		export.DownloadTokenHash = &tokenHash.String
+	}
+	if lastError.Valid {
+		export.LastError = &lastError.String
+	}
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+	if expiresAt.Valid {
+		export.ExpiresAt = &expiresAt.Time
+	}
+
+	return &export, nil
+}
+
+func (r *dataExportRepository) CollectPersonalData(ctx context.Context, userID uuid.UUID) (*repository.PersonalData, error) {
+	// 一貫したスナップショットを読むため REPEATABLE READ の読み取り専用トランザクションを使う
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	data := &repository.PersonalData{}
+	if data.Posts, err = collectPosts(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+	if data.CursesGiven, err = collectCurses(ctx, tx, `
+		SELECT post_id, created_at FROM curses WHERE user_id = $1 ORDER BY created_at
+	`, userID); err != nil {
+		return nil, err
+	}
+	if data.CursesReceived, err = collectCurses(ctx, tx, `
+		SELECT c.post_id, c.created_at
+		FROM curses c
+		JOIN posts p ON p.id = c.post_id
+		WHERE p.user_id = $1
+		ORDER BY c.created_at
+	`, userID); err != nil {
+		return nil, err
+	}
+	if data.Applications, err = collectApplications(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+	if data.RitualParticipations, err = collectRitualParticipations(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return data, nil
+}
+
+func collectPosts(ctx context.Context, tx *sql.Tx, userID uuid.UUID) ([]*entity.Post, error) {
+	query := `
+		SELECT
+			id, user_id, username, content, post_type, is_anonymous,
+			ritual_id, curse_count, is_deleted, created_at, updated_at, deleted_at
+		FROM posts
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect posts: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	var posts []*entity.Post
+	for rows.Next() {
+		var post entity.Post
+		var content string
+		var ritualID uuid.NullUUID
+		var deletedAt sql.NullTime
+
+		err := rows.Scan(
+			&post.ID, &post.UserID, &post.Username, &content, &post.PostType,
+			&post.IsAnonymous, &ritualID, &post.CurseCount,
+			&post.IsDeleted, &post.CreatedAt, &post.UpdatedAt, &deletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+
+		// 保存済みの本文はそのまま書き出す（文字数ルールの変更で過去の投稿が欠けないように）
+		post.Content = value.NewPostContentFromStored(content)
+		if ritualID.Valid {
+			post.RitualID = &ritualID.UUID
+		}
+		if deletedAt.Valid {
+			post.DeletedAt = &deletedAt.Time
+		}
+		posts = append(posts, &post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return posts, nil
+}
+
+func collectCurses(ctx context.Context, tx *sql.Tx, query string, userID uuid.UUID) ([]*repository.CurseRecord, error) {
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect curses: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	var curses []*repository.CurseRecord
+	for rows.Next() {
+		var curse repository.CurseRecord
+		if err := rows.Scan(&curse.PostID, &curse.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan curse: %w", err)
+		}
+		curses = append(curses, &curse)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return curses, nil
+}
+
+func collectApplications(ctx context.Context, tx *sql.Tx, userID uuid.UUID) ([]*repository.ApplicationRecord, error) {
+	query := `
+		SELECT
+			a.id, a.user_id, a.company_id, a.category, a.status, a.scheduled_at,
+			a.color_tag, a.completed, COALESCE(a.motivation, ''), COALESCE(a.what_to_do, ''),
+			COALESCE(a.job_axis, ''), COALESCE(a.strengths, ''), a.created_at, a.updated_at,
+			c.name
+		FROM applications a
+		JOIN companies c ON c.id = a.company_id
+		WHERE a.user_id = $1
+		ORDER BY a.created_at
+	`
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect applications: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	var records []*repository.ApplicationRecord
+	byID := make(map[uuid.UUID]*entity.Application)
+	for rows.Next() {
+		var app entity.Application
+		var scheduledAt sql.NullTime
+		var companyName string
+
+		err := rows.Scan(
+			&app.ID, &app.UserID, &app.CompanyID, &app.Category, &app.Status, &scheduledAt,
+			&app.ColorTag, &app.Completed, &app.Motivation, &app.WhatToDo,
+			&app.JobAxis, &app.Strengths, &app.CreatedAt, &app.UpdatedAt,
+			&companyName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan application: %w", err)
+		}
+		if scheduledAt.Valid {
+			app.ScheduledAt = &scheduledAt.Time
+		}
+
+		records = append(records, &repository.ApplicationRecord{Application: &app, CompanyName: companyName})
+		byID[app.ID] = &app
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	stageQuery := `
+		SELECT s.id, s.application_id, s.name, s.scheduled_at, s.status, s.notes, s.created_at, s.updated_at
+		FROM selection_stages s
+		JOIN applications a ON a.id = s.application_id
+		WHERE a.user_id = $1
+		ORDER BY s.application_id, s.position, s.created_at
+	`
+	stageRows, err := tx.QueryContext(ctx, stageQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect selection stages: %w", err)
+	}
+	defer func() {
+		if err := stageRows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	for stageRows.Next() {
+		var stage entity.SelectionStage
+		var scheduledAt sql.NullTime
+		var notes sql.NullString
+
+		err := stageRows.Scan(
+			&stage.ID, &stage.ApplicationID, &stage.Name, &scheduledAt, &stage.Status,
+			&notes, &stage.CreatedAt, &stage.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan selection stage: %w", err)
+		}
+		if scheduledAt.Valid {
+			stage.ScheduledAt = &scheduledAt.Time
+		}
+		if notes.Valid {
+			stage.Notes = &notes.String
+		}
+
+		if app, ok := byID[stage.ApplicationID]; ok {
+			app.Stages = append(app.Stages, stage)
+		}
+	}
+	if err = stageRows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return records, nil
+}
+
+func collectRitualParticipations(ctx context.Context, tx *sql.Tx, userID uuid.UUID) ([]*repository.RitualParticipationRecord, error) {
+	query := `
+		SELECT
+			rp.id, rp.ritual_id, rp.user_id, rp.total_damage, rp.post_count, rp.curse_count,
+			rp.rank, rp.points_earned, rp.created_at, rp.updated_at,
+			r.start_time, r.status
+		FROM ritual_participants rp
+		JOIN rituals r ON r.id = rp.ritual_id
+		WHERE rp.user_id = $1
+		ORDER BY r.start_time
+	`
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect ritual participations: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	var records []*repository.RitualParticipationRecord
+	for rows.Next() {
+		var p entity.RitualParticipant
+		var record repository.RitualParticipationRecord
+
+		err := rows.Scan(
+			&p.ID, &p.RitualID, &p.UserID, &p.TotalDamage, &p.PostCount, &p.CurseCount,
+			&p.Rank, &p.PointsEarned, &p.CreatedAt, &p.UpdatedAt,
+			&record.RitualStartTime, &record.RitualStatus,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ritual participation: %w", err)
+		}
+		record.Participant = &p
+		records = append(records, &record)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return records, nil
+}
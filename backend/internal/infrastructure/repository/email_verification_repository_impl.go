@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type emailVerificationRepository struct {
+	db *sql.DB
+}
+
+func NewEmailVerificationRepository(db *sql.DB) repository.EmailVerificationRepository {
+	return &emailVerificationRepository{db: db}
+}
+
+func (r *emailVerificationRepository) Create(ctx context.Context, token *entity.EmailVerificationToken) error {
+	query := `
+		INSERT INTO email_verification_tokens (id, user_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.ExecContext(
+		ctx, query,
+		token.ID, token.UserID, token.TokenHash, token.CreatedAt, token.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+	return nil
+}
+
+func (r *emailVerificationRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, created_at, expires_at, used_at
+		FROM email_verification_tokens
+		WHERE token_hash = $1
+	`
+	var token entity.EmailVerificationToken
+	var usedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.TokenHash,
+		&token.CreatedAt, &token.ExpiresAt, &usedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find email verification token: %w", err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return &token, nil
+}
+
+func (r *emailVerificationRepository) CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM email_verification_tokens WHERE user_id = $1 AND created_at >= $2`
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count email verification tokens: %w", err)
+	}
+	return count, nil
+}
+
+func (r *emailVerificationRepository) Consume(ctx context.Context, token *entity.EmailVerificationToken, user *entity.User) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now()
+
+	// Conditional update: only one caller can consume a given token
+	result, err := tx.ExecContext(ctx,
+		`UPDATE email_verification_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL`,
+		token.ID, now,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume email verification token: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE email_verification_tokens SET used_at = $2 WHERE user_id = $1 AND used_at IS NULL`,
+		user.ID, now,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to invalidate email verification tokens: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE users SET email_verified_at = $2, updated_at = $3 WHERE id = $1`,
+		user.ID, user.EmailVerifiedAt, user.UpdatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit email verification: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type loginEventRepository struct {
+	db *sql.DB
+}
+
+func NewLoginEventRepository(db *sql.DB) repository.LoginEventRepository {
+	return &loginEventRepository{db: db}
+}
+
+func (r *loginEventRepository) Create(ctx context.Context, event *entity.LoginEvent) error {
+	query := `
+		INSERT INTO login_events (
+			id, user_id, email, ip_address, user_agent, success, failure_reason, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.ExecContext(
+		ctx, query,
+		event.ID, event.UserID, event.Email, event.IPAddress, event.UserAgent,
+		event.Success, event.FailureReason, event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create login event: %w", err)
+	}
+	return nil
+}
+
+func (r *loginEventRepository) FindByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.LoginEvent, error) {
+	query := `
+		SELECT id, user_id, email, ip_address, user_agent, success, failure_reason, created_at
+		FROM login_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find login events: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	var events []*entity.LoginEvent
+	for rows.Next() {
+		var event entity.LoginEvent
+		var eventUserID uuid.NullUUID
+		var failureReason sql.NullString
+
+		err := rows.Scan(
+			&event.ID, &eventUserID, &event.Email, &event.IPAddress, &event.UserAgent,
+			&event.Success, &failureReason, &event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan login event: %w", err)
+		}
+
+		if eventUserID.Valid {
+			event.UserID = &eventUserID.UUID
+		}
+		if failureReason.Valid {
+			reason := entity.LoginFailureReason(failureReason.String)
+			event.FailureReason = &reason
+		}
+		events = append(events, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+	"time"
+)
+
+type loginThrottleRepository struct {
+	db *sql.DB
+}
+
+func NewLoginThrottleRepository(db *sql.DB) repository.LoginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+func (r *loginThrottleRepository) LockedUntil(ctx context.Context, scope entity.LoginThrottleScope, key string) (time.Time, error) {
+	query := `
+		SELECT locked_until
+		FROM login_attempt_counters
+		WHERE scope = $1 AND throttle_key = $2
+	`
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, scope, key).Scan(&lockedUntil)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to find login lock: %w", err)
+	}
+	if !lockedUntil.Valid {
+		return time.Time{}, nil
+	}
+	return lockedUntil.Time, nil
+}
+
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, scope entity.LoginThrottleScope, key string, now, resetBefore time.Time) (int, error) {
+	// Single upsert so concurrent failures on different replicas are all counted
+	query := `
+		INSERT INTO login_attempt_counters (scope, throttle_key, failure_count, last_failure_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (scope, throttle_key) DO UPDATE SET
+			failure_count = CASE
+				WHEN login_attempt_counters.last_failure_at < $4 THEN 1
+				ELSE login_attempt_counters.failure_count + 1
+			END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING failure_count
+	`
+	var failures int
+	if err := r.db.QueryRowContext(ctx, query, scope, key, now, resetBefore).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return failures, nil
+}
+
+func (r *loginThrottleRepository) Lock(ctx context.Context, scope entity.LoginThrottleScope, key string, until time.Time) error {
+	query := `
+		UPDATE login_attempt_counters
+		SET locked_until = GREATEST(COALESCE(locked_until, $3), $3)
+		WHERE scope = $1 AND throttle_key = $2
+	`
+	if _, err := r.db.ExecContext(ctx, query, scope, key, until); err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+	return nil
+}
+
+func (r *loginThrottleRepository) Reset(ctx context.Context, scope entity.LoginThrottleScope, key string) error {
+	query := `DELETE FROM login_attempt_counters WHERE scope = $1 AND throttle_key = $2`
+	if _, err := r.db.ExecContext(ctx, query, scope, key); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+	"time"
+)
+
+type mailOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewMailOutboxRepository(db *sql.DB) repository.MailOutboxRepository {
+	return &mailOutboxRepository{db: db}
+}
+
+func (r *mailOutboxRepository) Enqueue(ctx context.Context, mail *entity.OutboundMail) error {
+	query := `
+		INSERT INTO mail_outbox (
+			id, to_address, template, subject, text_body, html_body,
+			status, dedup_key, attempts, next_attempt_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (dedup_key) DO NOTHING
+	`
+	_, err := r.db.ExecContext(
+		ctx, query,
+		mail.ID, mail.To, mail.Template, mail.Subject, mail.TextBody, mail.HTMLBody,
+		mail.Status, mail.DedupKey, mail.Attempts, mail.NextAttemptAt, mail.CreatedAt, mail.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue mail: %w", err)
+	}
+	return nil
+}
+
+func (r *mailOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.OutboundMail, error) {
+	query := `
+		UPDATE mail_outbox
+		SET attempts = attempts + 1,
+			next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM mail_outbox
+			WHERE status = 'pending' AND next_attempt_at <= $3
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			id, to_address, template, subject, text_body, html_body,
+			status, attempts, next_attempt_at, last_error, created_at, updated_at, sent_at
+	`
+	now := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, limit, now.Add(lease), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim mails: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	var mails []*entity.OutboundMail
+	for rows.Next() {
+		var mail entity.OutboundMail
+		var lastError sql.NullString
+		var sentAt sql.NullTime
+
+		err := rows.Scan(
+			&mail.ID, &mail.To, &mail.Template, &mail.Subject, &mail.TextBody, &mail.HTMLBody,
+			&mail.Status, &mail.Attempts, &mail.NextAttemptAt, &lastError,
+			&mail.CreatedAt, &mail.UpdatedAt, &sentAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan mail: %w", err)
+		}
+
+		if lastError.Valid {
+			mail.LastError = &lastError.String
+		}
+		if sentAt.Valid {
+			mail.SentAt = &sentAt.Time
+		}
+
+		mails = append(mails, &mail)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return mails, nil
+}
+
+func (r *mailOutboxRepository) Update(ctx context.Context, mail *entity.OutboundMail) error {
+	query := `
+		UPDATE mail_outbox
+		SET status = $1, attempts = $2, next_attempt_at = $3,
+			last_error = $4, sent_at = $5
+		WHERE id = $6
+	`
+	_, err := r.db.ExecContext(
+		ctx, query,
+		mail.Status, mail.Attempts, mail.NextAttemptAt,
+		mail.LastError, mail.SentAt, mail.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type mfaRepository struct {
+	db *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) repository.MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+	var mfa entity.UserMFA
+	var enabledAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&mfa.UserID, &mfa.Secret, &enabledAt, &mfa.LastUsedStep, &mfa.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find mfa settings: %w", err)
+	}
+
+	if enabledAt.Valid {
+		mfa.EnabledAt = &enabledAt.Time
+	}
+
+	return &mfa, nil
+}
+
+func (r *mfaRepository) SavePending(ctx context.Context, mfa *entity.UserMFA) error {
+	query := `
+		INSERT INTO user_mfa (user_id, secret, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			created_at = EXCLUDED.created_at,
+			last_used_step = 0
+		WHERE user_mfa.enabled_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, mfa.UserID, mfa.Secret, mfa.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save mfa settings: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return errors.ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+func (r *mfaRepository) Enable(ctx context.Context, userID uuid.UUID, step int64, codes []*entity.MFARecoveryCode) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE user_mfa SET enabled_at = $2, last_used_step = $3 WHERE user_id = $1 AND enabled_at IS NULL`,
+		userID, time.Now(), step,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enable mfa: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return errors.ErrMFAAlreadyEnabled
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit mfa enrolment: %w", err)
+	}
+	return nil
+}
+
+func (r *mfaRepository) Disable(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete mfa settings: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit mfa removal: %w", err)
+	}
+	return nil
+}
+
+func (r *mfaRepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE user_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`,
+		userID, step,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record totp step: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE mfa_recovery_codes SET used_at = $3 WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, codeHash, time.Now(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*entity.MFARecoveryCode) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit recovery codes: %w", err)
+	}
+	return nil
+}
+
+func (r *mfaRepository) CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uuid.UUID, codes []*entity.MFARecoveryCode) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	query := `
+		INSERT INTO mfa_recovery_codes (id, user_id, code_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, code := range codes {
+		if _, err := tx.ExecContext(ctx, query, code.ID, code.UserID, code.CodeHash, code.CreatedAt); err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) repository.NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) ClaimBroadcast(ctx context.Context, key string) (bool, error) {
+	query := `INSERT INTO notification_broadcasts (key) VALUES ($1) ON CONFLICT (key) DO NOTHING`
+	result, err := r.db.ExecContext(ctx, query, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim broadcast: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (r *notificationRepository) SaveInbox(ctx context.Context, n *entity.InboxNotification) error {
+	query := `
+		INSERT INTO notification_inbox (id, user_id, kind, title, body, url, dedup_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (dedup_key) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		n.ID, n.UserID, n.Kind, n.Title, n.Body, n.URL, n.DedupKey, n.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save inbox notification: %w", err)
+	}
+	return nil
+}
+
+func (r *notificationRepository) FindInbox(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*entity.InboxNotification, error) {
+	query := `
+		SELECT id, user_id, kind, title, body, url, dedup_key, created_at
+		FROM notification_inbox
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find inbox notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]*entity.InboxNotification, 0)
+	for rows.Next() {
+		var (
+			n        entity.InboxNotification
+			dedupKey sql.NullString
+		)
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Title, &n.Body, &n.URL, &dedupKey, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan inbox notification: %w", err)
+		}
+		if dedupKey.Valid {
+			n.DedupKey = &dedupKey.String
+		}
+		notifications = append(notifications, &n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate inbox notifications: %w", err)
+	}
+
+	return notifications, nil
+}
@@ -89,7 +89,7 @@ func (r *postRepository) FindTimeline(ctx context.Context, offset, limit int, cu
 		SELECT
 			p.id, p.user_id, p.username, p.content, p.post_type, p.is_anonymous,
 			p.ritual_id, p.curse_count, p.is_deleted, p.created_at, p.updated_at, p.deleted_at,
-			u.id, u.email, u.password_hash, u.username, u.age, u.gender,
+			u.id, u.email, u.password_hash, u.username, COALESCE(u.handle, ''), u.age, u.gender,
 			u.curse_style_id, u.points, u.profile_public, u.notify_curse,
 			u.notify_ritual, u.is_deleted, u.created_at, u.updated_at, u.deleted_at,
 			COALESCE((SELECT TRUE FROM curses WHERE user_id = $1 AND post_id = p.id), FALSE) as is_liked
@@ -120,7 +120,7 @@ func (r *postRepository) FindTimeline(ctx context.Context, offset, limit int, cu
 		err := rows.Scan(
 			&post.ID, &post.UserID, &post.Username, &content, &post.PostType, &post.IsAnonymous,
 			&postRitualID, &post.CurseCount, &post.IsDeleted, &post.CreatedAt, &post.UpdatedAt, &postDeletedAt,
-			&user.ID, &email, &passwordHash, &user.Username, &user.Age, &user.Gender,
+			&user.ID, &email, &passwordHash, &user.Username, &user.Handle, &user.Age, &user.Gender,
 			&user.CurseStyleID, &user.Points, &user.ProfilePublic, &user.NotifyCurse,
 			&user.NotifyRitual, &user.IsDeleted, &user.CreatedAt, &user.UpdatedAt, &userDeletedAt,
 			&isLiked,
@@ -170,10 +170,35 @@ func (r *postRepository) FindByUserID(ctx context.Context, userID uuid.UUID, off
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
-	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	posts, err := r.queryPosts(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find posts by user ID: %w", err)
 	}
+	return posts, nil
+}
+
+func (r *postRepository) FindPublicByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*entity.Post, error) {
+	query := `
+		SELECT
+			id, user_id, username, content, post_type, is_anonymous,
+			ritual_id, curse_count, is_deleted, created_at, updated_at, deleted_at
+		FROM posts
+		WHERE user_id = $1 AND is_deleted = FALSE AND is_anonymous = FALSE
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	posts, err := r.queryPosts(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find public posts by user ID: %w", err)
+	}
+	return posts, nil
+}
+
+func (r *postRepository) queryPosts(ctx context.Context, query string, args ...interface{}) ([]*entity.Post, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer func() {
 		if err := rows.Close(); err != nil {
 			fmt.Printf("failed to close rows: %v\n", err)
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type pushSubscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewPushSubscriptionRepository(db *sql.DB) repository.PushSubscriptionRepository {
+	return &pushSubscriptionRepository{db: db}
+}
+
+func (r *pushSubscriptionRepository) Upsert(ctx context.Context, sub *entity.PushSubscription) error {
+	// The endpoint uniquely identifies a browser install, so a re-subscription
+	// (possibly after switching accounts) replaces the previous owner and keys.
+	query := `
+		INSERT INTO push_subscriptions (
+			id, user_id, endpoint, p256dh, auth, user_agent,
+			failure_count, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, 0, $7, $8)
+		ON CONFLICT (endpoint) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			p256dh = EXCLUDED.p256dh,
+			auth = EXCLUDED.auth,
+			user_agent = EXCLUDED.user_agent,
+			failure_count = 0
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(
+		ctx, query,
+		sub.ID, sub.UserID, sub.Endpoint, sub.P256dh, sub.Auth, sub.UserAgent,
+		sub.CreatedAt, sub.UpdatedAt,
+	).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert push subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *pushSubscriptionRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.PushSubscription, error) {
+	query := `
+		SELECT
+			id, user_id, endpoint, p256dh, auth, user_agent,
+			failure_count, last_success_at, created_at, updated_at
+		FROM push_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push subscriptions: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	var subs []*entity.PushSubscription
+	for rows.Next() {
+		var sub entity.PushSubscription
+		var lastSuccessAt sql.NullTime
+
+		err := rows.Scan(
+			&sub.ID, &sub.UserID, &sub.Endpoint, &sub.P256dh, &sub.Auth, &sub.UserAgent,
+			&sub.FailureCount, &lastSuccessAt, &sub.CreatedAt, &sub.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan push subscription: %w", err)
+		}
+
+		if lastSuccessAt.Valid {
+			sub.LastSuccessAt = &lastSuccessAt.Time
+		}
+
+		subs = append(subs, &sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return subs, nil
+}
+
+func (r *pushSubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM push_subscriptions WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *pushSubscriptionRepository) DeleteByUserAndEndpoint(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	query := `DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = $2`
+	_, err := r.db.ExecContext(ctx, query, userID, endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *pushSubscriptionRepository) RecordDelivery(ctx context.Context, id uuid.UUID, success bool) error {
+	query := `
+		UPDATE push_subscriptions
+		SET failure_count = failure_count + 1
+		WHERE id = $1
+	`
+	if success {
+		query = `
+			UPDATE push_subscriptions
+			SET failure_count = 0, last_success_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+	}
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to record push delivery: %w", err)
+	}
+	return nil
+}
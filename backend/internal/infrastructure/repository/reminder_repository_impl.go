@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"noroi/internal/domain/entity"
+	repo "noroi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type reminderRepository struct {
+	db *sql.DB
+}
+
+func NewReminderRepository(db *sql.DB) repo.ReminderRepository {
+	return &reminderRepository{db: db}
+}
+
+const reminderColumns = `
+	r.id, r.application_id, r.target_at, r.channel, r.message, r.status, r.attempts,
+	r.next_attempt_at, r.fire_count, r.last_error, r.delivered_at, r.acknowledged_at,
+	r.created_at, r.updated_at
+`
+
+// Reminder times are stored in UTC (TIMESTAMP columns carry no zone)
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+func (r *reminderRepository) Create(ctx context.Context, reminder *entity.Reminder) error {
+	query := `
+		INSERT INTO reminders (
+			id, application_id, target_at, channel, message, status, attempts,
+			next_attempt_at, fire_count, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		reminder.ID,
+		reminder.ApplicationID,
+		reminder.TargetAt.UTC(),
+		reminder.Channel,
+		reminder.Message,
+		reminder.Status,
+		reminder.Attempts,
+		reminder.NextAttemptAt.UTC(),
+		reminder.FireCount,
+		reminder.CreatedAt,
+		reminder.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert reminder: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reminderRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Reminder, error) {
+	query := `SELECT ` + reminderColumns + ` FROM reminders r WHERE r.id = $1`
+
+	reminder, err := scanReminder(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("reminder not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query reminder: %w", err)
+	}
+
+	return reminder, nil
+}
+
+func (r *reminderRepository) FindByApplicationID(ctx context.Context, applicationID uuid.UUID) ([]*entity.Reminder, error) {
+	query := `
+		SELECT ` + reminderColumns + `
+		FROM reminders r
+		WHERE r.application_id = $1
+		ORDER BY r.target_at, r.created_at, r.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("query reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []*entity.Reminder
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan reminders: %w", err)
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+func (r *reminderRepository) FindPendingByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Reminder, error) {
+	query := `
+		SELECT ` + reminderColumns + `
+		FROM reminders r
+		JOIN applications a ON a.id = r.application_id
+		WHERE a.user_id = $1 AND r.status = 'pending'
+		ORDER BY r.next_attempt_at, r.id
+		LIMIT 1000
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query pending reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []*entity.Reminder
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan pending reminders: %w", err)
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate pending reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+func (r *reminderRepository) FindPendingBefore(ctx context.Context, userID uuid.UUID, until time.Time, limit int) ([]*repo.UserReminder, error) {
+	query := `
+		SELECT ` + reminderColumns + `, c.name, a.category
+		FROM reminders r
+		JOIN applications a ON a.id = r.application_id
+		JOIN companies c ON c.id = a.company_id
+		WHERE a.user_id = $1 AND r.status = 'pending' AND r.next_attempt_at < $2
+		ORDER BY r.next_attempt_at, r.id
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, until.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("query pending reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []*repo.UserReminder
+	for rows.Next() {
+		var item repo.UserReminder
+		reminder, err := scanReminder(rows, &item.CompanyName, &item.Category)
+		if err != nil {
+			return nil, fmt.Errorf("scan pending reminders: %w", err)
+		}
+		item.Reminder = reminder
+		reminders = append(reminders, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate pending reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+func (r *reminderRepository) CountPendingBefore(ctx context.Context, userID uuid.UUID, until time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM reminders r
+		JOIN applications a ON a.id = r.application_id
+		WHERE a.user_id = $1 AND r.status = 'pending' AND r.next_attempt_at < $2
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID, until.UTC()).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count pending reminders: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *reminderRepository) Update(ctx context.Context, reminder *entity.Reminder) error {
+	query := `
+		UPDATE reminders SET
+			status = $1,
+			attempts = $2,
+			next_attempt_at = $3,
+			fire_count = $4,
+			last_error = $5,
+			delivered_at = $6,
+			acknowledged_at = $7,
+			updated_at = $8
+		WHERE id = $9
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		reminder.Status,
+		reminder.Attempts,
+		reminder.NextAttemptAt.UTC(),
+		reminder.FireCount,
+		reminder.LastError,
+		utcPtr(reminder.DeliveredAt),
+		utcPtr(reminder.AcknowledgedAt),
+		reminder.UpdatedAt,
+		reminder.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update reminder: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("reminder not found")
+	}
+
+	return nil
+}
+
+func (r *reminderRepository) UpdateClaimed(ctx context.Context, due *repo.DueReminder) (bool, error) {
+	query := `
+		UPDATE reminders SET
+			status = $1,
+			attempts = $2,
+			next_attempt_at = $3,
+			fire_count = $4,
+			last_error = $5,
+			delivered_at = $6,
+			updated_at = $7
+		WHERE id = $8 AND status = 'pending' AND attempts = $9 AND next_attempt_at = $10
+	`
+
+	reminder := due.Reminder
+	result, err := r.db.ExecContext(ctx, query,
+		reminder.Status,
+		reminder.Attempts,
+		reminder.NextAttemptAt.UTC(),
+		reminder.FireCount,
+		reminder.LastError,
+		utcPtr(reminder.DeliveredAt),
+		reminder.UpdatedAt,
+		reminder.ID,
+		due.ClaimedAttempts,
+		due.LeaseUntil.UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("update claimed reminder: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (r *reminderRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM reminders WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete reminder: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("reminder not found")
+	}
+
+	return nil
+}
+
+func (r *reminderRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*repo.DueReminder, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM reminders
+			WHERE status = 'pending' AND next_attempt_at <= $2
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE reminders r
+		SET attempts = r.attempts + 1,
+			next_attempt_at = $3
+		FROM due, applications a, users u, companies c
+		WHERE r.id = due.id
+			AND a.id = r.application_id
+			AND u.id = a.user_id
+			AND c.id = a.company_id
+		RETURNING ` + reminderColumns + `, a.user_id, COALESCE(u.email, ''), c.name
+	`
+
+	now := time.Now().UTC()
+	rows, err := r.db.QueryContext(ctx, query, limit, now, now.Add(lease))
+	if err != nil {
+		return nil, fmt.Errorf("claim reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var claimed []*repo.DueReminder
+	for rows.Next() {
+		var due repo.DueReminder
+		reminder, err := scanReminder(rows, &due.UserID, &due.Email, &due.CompanyName)
+		if err != nil {
+			return nil, fmt.Errorf("scan reminders: %w", err)
+		}
+		due.Reminder = reminder
+		due.ClaimedAttempts = reminder.Attempts
+		due.LeaseUntil = reminder.NextAttemptAt
+		claimed = append(claimed, &due)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reminders: %w", err)
+	}
+
+	return claimed, nil
+}
+
+// scanReminder scans reminderColumns followed by any extra destinations
+func scanReminder(row rowScanner, extra ...any) (*entity.Reminder, error) {
+	var (
+		reminder       entity.Reminder
+		lastError      sql.NullString
+		deliveredAt    sql.NullTime
+		acknowledgedAt sql.NullTime
+	)
+
+	dest := []any{
+		&reminder.ID, &reminder.ApplicationID, &reminder.TargetAt, &reminder.Channel, &reminder.Message,
+		&reminder.Status, &reminder.Attempts, &reminder.NextAttemptAt, &reminder.FireCount,
+		&lastError, &deliveredAt, &acknowledgedAt, &reminder.CreatedAt, &reminder.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if lastError.Valid {
+		reminder.LastError = &lastError.String
+	}
+	if deliveredAt.Valid {
+		reminder.DeliveredAt = &deliveredAt.Time
+	}
+	if acknowledgedAt.Valid {
+		reminder.AcknowledgedAt = &acknowledgedAt.Time
+	}
+
+	return &reminder, nil
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) repository.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	sessionQuery := `
+		INSERT INTO sessions (
+			id, user_id, user_agent, ip_address, created_at, last_used_at, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err = tx.ExecContext(
+		ctx, sessionQuery,
+		session.ID, session.UserID, session.UserAgent, session.IPAddress,
+		session.CreatedAt, session.LastUsedAt, session.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	if err := insertRefreshToken(ctx, tx, token); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit session: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Session, error) {
+	query := `
+		SELECT
+			id, user_id, user_agent, ip_address, created_at,
+			last_used_at, expires_at, revoked_at, revoked_reason
+		FROM sessions
+		WHERE id = $1
+	`
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find session: %w", err)
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) FindActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error) {
+	query := `
+		SELECT
+			id, user_id, user_agent, ip_address, created_at,
+			last_used_at, expires_at, revoked_at, revoked_reason
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_used_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sessions: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	var sessions []*entity.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) FindRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, token_hash, created_at, expires_at, used_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+	var token entity.RefreshToken
+	var usedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID, &token.SessionID, &token.TokenHash,
+		&token.CreatedAt, &token.ExpiresAt, &usedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return &token, nil
+}
+
+func (r *sessionRepository) Rotate(ctx context.Context, used *entity.RefreshToken, next *entity.RefreshToken) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now()
+
+	// Conditional update: only one caller can consume a given token
+	result, err := tx.ExecContext(ctx,
+		`UPDATE refresh_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL`,
+		used.ID, now,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume refresh token: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE sessions SET last_used_at = $2 WHERE id = $1`,
+		used.SessionID, now,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit rotation: %w", err)
+	}
+	return true, nil
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID, reason entity.SessionRevokeReason) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = $3, revoked_reason = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, id, reason, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID, keepID uuid.UUID, reason entity.SessionRevokeReason) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = $4, revoked_reason = $3
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, keepID, reason, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+func insertRefreshToken(ctx context.Context, tx *sql.Tx, token *entity.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, session_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := tx.ExecContext(
+		ctx, query,
+		token.ID, token.SessionID, token.TokenHash, token.CreatedAt, token.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(row rowScanner) (*entity.Session, error) {
+	var session entity.Session
+	var revokedAt sql.NullTime
+	var revokedReason sql.NullString
+
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress, &session.CreatedAt,
+		&session.LastUsedAt, &session.ExpiresAt, &revokedAt, &revokedReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	if revokedReason.Valid {
+		reason := entity.SessionRevokeReason(revokedReason.String)
+		session.RevokedReason = &reason
+	}
+
+	return &session, nil
+}
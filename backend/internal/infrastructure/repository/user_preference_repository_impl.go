@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type userPreferenceRepository struct {
+	db *sql.DB
+}
+
+func NewUserPreferenceRepository(db *sql.DB) repository.UserPreferenceRepository {
+	return &userPreferenceRepository{db: db}
+}
+
+func (r *userPreferenceRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*entity.UserPreferences, error) {
+	query := `SELECT key, value FROM user_preferences WHERE user_id = $1`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user preferences: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	preferences := entity.DefaultUserPreferences(userID)
+	for rows.Next() {
+		var key string
+		var raw []byte
+		if err := rows.Scan(&key, &raw); err != nil {
+			return nil, fmt.Errorf("failed to scan user preference: %w", err)
+		}
+		if err := decodePreference(preferences, value.PreferenceKey(key), raw); err != nil {
+			// A value that no longer validates falls back to the default
+			fmt.Printf("ignoring preference %s for user %s: %v\n", key, userID, err)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return preferences, nil
+}
+
+func (r *userPreferenceRepository) Save(ctx context.Context, preferences *entity.UserPreferences) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := savePreferences(ctx, tx, preferences); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *userPreferenceRepository) SaveSettings(ctx context.Context, user *entity.User, preferences *entity.UserPreferences) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		UPDATE users
+		SET profile_public = $1, notify_curse = $2, notify_ritual = $3, updated_at = $4
+		WHERE id = $5 AND is_deleted = FALSE
+	`
+	result, err := tx.ExecContext(ctx, query, user.ProfilePublic, user.NotifyCurse, user.NotifyRitual, user.UpdatedAt, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update user settings: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return errors.ErrUserNotFound
+	}
+
+	if err := savePreferences(ctx, tx, preferences); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func savePreferences(ctx context.Context, tx *sql.Tx, preferences *entity.UserPreferences) error {
+	query := `
+		INSERT INTO user_preferences (user_id, key, value, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, key) DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at
+		WHERE user_preferences.value IS DISTINCT FROM EXCLUDED.value
+	`
+	now := time.Now()
+	for key, v := range encodePreferences(preferences) {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode preference %s: %w", key, err)
+		}
+		if _, err := tx.ExecContext(ctx, query, preferences.UserID, string(key), raw, now); err != nil {
+			return fmt.Errorf("failed to save preference %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// encodePreferences maps each preference key to the value stored as JSON
+func encodePreferences(p *entity.UserPreferences) map[value.PreferenceKey]any {
+	return map[value.PreferenceKey]any{
+		value.PreferenceDigestFrequency: p.DigestFrequency,
+		value.PreferenceQuietHours:      p.QuietHours,
+		value.PreferenceLocale:          p.Locale,
+		value.PreferenceTimezone:        p.Timezone,
+	}
+}
+
+// decodePreference validates a stored value and applies it to p. Unknown keys
+// (e.g. from a newer release) are ignored.
+func decodePreference(p *entity.UserPreferences, key value.PreferenceKey, raw []byte) error {
+	switch key {
+	case value.PreferenceDigestFrequency:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		frequency, err := value.NewDigestFrequency(s)
+		if err != nil {
+			return err
+		}
+		p.DigestFrequency = frequency
+	case value.PreferenceQuietHours:
+		var q *value.QuietHours
+		if err := json.Unmarshal(raw, &q); err != nil {
+			return err
+		}
+		if q == nil {
+			p.QuietHours = nil
+			return nil
+		}
+		quietHours, err := value.NewQuietHours(q.Start, q.End)
+		if err != nil {
+			return err
+		}
+		p.QuietHours = &quietHours
+	case value.PreferenceLocale:
+		var locale value.Locale
+		if err := json.Unmarshal(raw, &locale); err != nil {
+			return err
+		}
+		if err := locale.Validate(); err != nil {
+			return err
+		}
+		p.Locale = locale
+	case value.PreferenceTimezone:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		timezone, err := value.NewTimezone(s)
+		if err != nil {
+			return err
+		}
+		p.Timezone = timezone
+	}
+	return nil
+}
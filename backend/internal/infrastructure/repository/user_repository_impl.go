@@ -8,8 +8,10 @@ import (
 	"noroi/internal/domain/value"
 	"noroi/internal/repository"
 	"noroi/pkg/errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type userRepository struct {
@@ -20,21 +22,88 @@ func NewUserRepository(db *sql.DB) repository.UserRepository {
 	return &userRepository{db: db}
 }
 
+const userColumns = `
+	id, email, password_hash, username, handle, handle_changed_at, age, gender,
+	curse_style_id, points, profile_public, notify_curse,
+	notify_ritual, is_deleted, created_at, updated_at, deleted_at,
+	email_verified_at, role
+`
+
+func scanUser(row rowScanner) (*entity.User, error) {
+	var user entity.User
+	var email, passwordHash string
+	var handle sql.NullString
+	var handleChangedAt, deletedAt, emailVerifiedAt sql.NullTime
+
+	err := row.Scan(
+		&user.ID, &email, &passwordHash, &user.Username, &handle, &handleChangedAt,
+		&user.Age, &user.Gender, &user.CurseStyleID, &user.Points,
+		&user.ProfilePublic, &user.NotifyCurse, &user.NotifyRitual,
+		&user.IsDeleted, &user.CreatedAt, &user.UpdatedAt, &deletedAt,
+		&emailVerifiedAt, &user.Role,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	emailVal, _ := value.NewEmail(email)
+	user.Email = emailVal
+	user.Password = value.NewPasswordFromHash(passwordHash)
+	user.Handle = value.Handle(handle.String)
+	if handleChangedAt.Valid {
+		user.HandleChangedAt = &handleChangedAt.Time
+	}
+	if deletedAt.Valid {
+		user.DeletedAt = &deletedAt.Time
+	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+
+	return &user, nil
+}
+
+// nullableHandle stores the empty handle of a deleted user as NULL
+func nullableHandle(handle value.Handle) sql.NullString {
+	return sql.NullString{String: handle.String(), Valid: handle != ""}
+}
+
+// isHandleConflict reports whether err is a unique violation on the handle index
+func isHandleConflict(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "idx_users_handle"
+}
+
+// handleHeldQuery reports whether a handle is still reserved after its owner left
+const handleHeldQuery = `SELECT EXISTS(SELECT 1 FROM handle_holds WHERE handle = $1 AND held_until > $2)`
+
 func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
+	var held bool
+	if err := r.db.QueryRowContext(ctx, handleHeldQuery, user.Handle.String(), user.CreatedAt).Scan(&held); err != nil {
+		return fmt.Errorf("failed to check handle hold: %w", err)
+	}
+	if held {
+		return errors.ErrHandleTaken
+	}
+
 	query := `
 		INSERT INTO users (
-			id, email, password_hash, username, age, gender,
+			id, email, password_hash, username, handle, handle_changed_at, age, gender,
 			curse_style_id, points, profile_public, notify_curse,
-			notify_ritual, is_deleted, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			notify_ritual, is_deleted, created_at, updated_at, email_verified_at, role
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 	`
 	_, err := r.db.ExecContext(
 		ctx, query,
 		user.ID, user.Email.String(), user.Password.Hash(), user.Username,
+		nullableHandle(user.Handle), user.HandleChangedAt,
 		user.Age, user.Gender, user.CurseStyleID, user.Points,
 		user.ProfilePublic, user.NotifyCurse, user.NotifyRitual,
-		user.IsDeleted, user.CreatedAt, user.UpdatedAt,
+		user.IsDeleted, user.CreatedAt, user.UpdatedAt, user.EmailVerifiedAt, user.Role,
 	)
+	if isHandleConflict(err) {
+		return errors.ErrHandleTaken
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -42,109 +111,210 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 }
 
 func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
-	query := `
-		SELECT
-			id, email, password_hash, username, age, gender,
-			curse_style_id, points, profile_public, notify_curse,
-			notify_ritual, is_deleted, created_at, updated_at, deleted_at
-		FROM users
-		WHERE id = $1 AND is_deleted = FALSE
-	`
-	var user entity.User
-	var email, passwordHash string
-	var deletedAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID, &email, &passwordHash, &user.Username,
-		&user.Age, &user.Gender, &user.CurseStyleID, &user.Points,
-		&user.ProfilePublic, &user.NotifyCurse, &user.NotifyRitual,
-		&user.IsDeleted, &user.CreatedAt, &user.UpdatedAt, &deletedAt,
-	)
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND is_deleted = FALSE`
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, errors.ErrUserNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user by ID: %w", err)
 	}
-
-	emailVal, _ := value.NewEmail(email)
-	user.Email = emailVal
-	user.Password = value.NewPasswordFromHash(passwordHash)
-	if deletedAt.Valid {
-		user.DeletedAt = &deletedAt.Time
-	}
-
-	return &user, nil
+	return user, nil
 }
 
 func (r *userRepository) FindByEmail(ctx context.Context, email value.Email) (*entity.User, error) {
-	query := `
-		SELECT
-			id, email, password_hash, username, age, gender,
-			curse_style_id, points, profile_public, notify_curse,
-			notify_ritual, is_deleted, created_at, updated_at, deleted_at
-		FROM users
-		WHERE email = $1 AND is_deleted = FALSE
-	`
-	var user entity.User
-	var emailStr, passwordHash string
-	var deletedAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, email.String()).Scan(
-		&user.ID, &emailStr, &passwordHash, &user.Username,
-		&user.Age, &user.Gender, &user.CurseStyleID, &user.Points,
-		&user.ProfilePublic, &user.NotifyCurse, &user.NotifyRitual,
-		&user.IsDeleted, &user.CreatedAt, &user.UpdatedAt, &deletedAt,
-	)
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1 AND is_deleted = FALSE`
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email.String()))
 	if err == sql.ErrNoRows {
 		return nil, errors.ErrUserNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user by email: %w", err)
 	}
+	return user, nil
+}
 
-	emailVal, _ := value.NewEmail(emailStr)
-	user.Email = emailVal
-	user.Password = value.NewPasswordFromHash(passwordHash)
-	if deletedAt.Valid {
-		user.DeletedAt = &deletedAt.Time
+func (r *userRepository) FindByHandle(ctx context.Context, handle value.Handle) (*entity.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE handle = $1 AND is_deleted = FALSE`
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, handle.String()))
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrUserNotFound
 	}
-
-	return &user, nil
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user by handle: %w", err)
+	}
+	return user, nil
 }
 
 func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// 変更前のユーザー名を取得し、同じトランザクションで行をロックする
+	var previousUsername string
+	var previousHandle sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT username, handle FROM users WHERE id = $1 FOR UPDATE`, user.ID).Scan(&previousUsername, &previousHandle)
+	if err == sql.ErrNoRows {
+		return errors.ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	// 退会者から予約中のハンドルには改名できない
+	if user.Handle != "" && previousHandle.String != user.Handle.String() {
+		var held bool
+		if err := tx.QueryRowContext(ctx, handleHeldQuery, user.Handle.String(), user.UpdatedAt).Scan(&held); err != nil {
+			return fmt.Errorf("failed to check handle hold: %w", err)
+		}
+		if held {
+			return errors.ErrHandleTaken
+		}
+	}
+
 	query := `
 		UPDATE users
-		SET username = $1, age = $2, gender = $3, curse_style_id = $4,
-			points = $5, profile_public = $6, notify_curse = $7,
-			notify_ritual = $8, is_deleted = $9, updated_at = $10,
-			deleted_at = $11
-		WHERE id = $12
+		SET username = $1, handle = $2, handle_changed_at = $3, age = $4, gender = $5,
+			curse_style_id = $6, points = $7, profile_public = $8, notify_curse = $9,
+			notify_ritual = $10, is_deleted = $11, updated_at = $12,
+			deleted_at = $13
+		WHERE id = $14
 	`
-	_, err := r.db.ExecContext(
+	_, err = tx.ExecContext(
 		ctx, query,
-		user.Username, user.Age, user.Gender, user.CurseStyleID,
-		user.Points, user.ProfilePublic, user.NotifyCurse,
+		user.Username, nullableHandle(user.Handle), user.HandleChangedAt, user.Age, user.Gender,
+		user.CurseStyleID, user.Points, user.ProfilePublic, user.NotifyCurse,
 		user.NotifyRitual, user.IsDeleted, user.UpdatedAt,
 		user.DeletedAt, user.ID,
 	)
+	if isHandleConflict(err) {
+		return errors.ErrHandleTaken
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
+
+	// 投稿に複製しているユーザー名を改名に追従させる
+	if user.Username != previousUsername {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE posts SET username = $1 WHERE user_id = $2 AND username <> $1`,
+			user.Username, user.ID,
+		); err != nil {
+			return fmt.Errorf("failed to update username on posts: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) UpdatePasswordHash(ctx context.Context, user *entity.User) error {
+	query := `UPDATE users SET password_hash = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, user.Password.Hash(), user.UpdatedAt, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update password hash: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id uuid.UUID, role value.Role) error {
+	query := `UPDATE users SET role = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, role, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
 	return nil
 }
 
-func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
+func (r *userRepository) Delete(ctx context.Context, user *entity.User) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// 非公開データを削除（選考ステップ・リマインダーは ON DELETE CASCADE で消える）
+	purges := []struct{ name, query string }{
+		{"applications", `DELETE FROM applications WHERE user_id = $1`},
+		{"push subscriptions", `DELETE FROM push_subscriptions WHERE user_id = $1`},
+		{"mfa recovery codes", `DELETE FROM mfa_recovery_codes WHERE user_id = $1`},
+		{"mfa", `DELETE FROM user_mfa WHERE user_id = $1`},
+		{"password reset tokens", `DELETE FROM password_reset_tokens WHERE user_id = $1`},
+		{"email verification tokens", `DELETE FROM email_verification_tokens WHERE user_id = $1`},
+		{"login events", `DELETE FROM login_events WHERE user_id = $1`},
+		{"preferences", `DELETE FROM user_preferences WHERE user_id = $1`},
+		{"calendar feed", `DELETE FROM calendar_feeds WHERE user_id = $1`},
+		{"notification inbox", `DELETE FROM notification_inbox WHERE user_id = $1`},
+	}
+	for _, p := range purges {
+		if _, err := tx.ExecContext(ctx, p.query, user.ID); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", p.name, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM login_attempt_counters WHERE scope = 'account' AND throttle_key = $1`,
+		user.Email.String(),
+	); err != nil {
+		return fmt.Errorf("failed to delete login attempt counter: %w", err)
+	}
+
+	// エクスポート済みのアーカイブは即時失効させ、ファイルは削除ワーカーに消させる
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE data_exports SET expires_at = $1, download_token_hash = NULL, updated_at = $1 WHERE user_id = $2 AND status = 'ready'`,
+		user.UpdatedAt, user.ID,
+	); err != nil {
+		return fmt.Errorf("failed to expire data exports: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE data_exports SET status = 'failed', last_error = 'account deleted', updated_at = $1 WHERE user_id = $2 AND status IN ('pending', 'processing')`,
+		user.UpdatedAt, user.ID,
+	); err != nil {
+		return fmt.Errorf("failed to cancel data exports: %w", err)
+	}
+
+	// 投稿は残すため、投稿に複製したユーザー名も消す
+	if _, err := tx.ExecContext(ctx, `UPDATE posts SET username = '' WHERE user_id = $1`, user.ID); err != nil {
+		return fmt.Errorf("failed to anonymize posts: %w", err)
+	}
+
+	// ハンドルは解放する前に予約し、一定期間は他のユーザーが取得できないようにする
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO handle_holds (handle, held_until)
+		SELECT handle, $1 FROM users WHERE id = $2 AND handle IS NOT NULL
+		ON CONFLICT (handle) DO UPDATE SET held_until = EXCLUDED.held_until
+	`, user.DeletedAt.Add(entity.HandleChangeCooldown), user.ID); err != nil {
+		return fmt.Errorf("failed to hold handle: %w", err)
+	}
+
+	// メールアドレスは UNIQUE のため、再登録できるよう墓標アドレスに置き換える
 	query := `
 		UPDATE users
-		SET is_deleted = TRUE, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1
+		SET email = 'deleted+' || id::text || '@deleted.invalid', password_hash = '',
+			username = '', handle = NULL, profile_public = FALSE, notify_curse = FALSE, notify_ritual = FALSE,
+			email_verified_at = NULL, is_deleted = TRUE, deleted_at = $1, updated_at = $2
+		WHERE id = $3 AND is_deleted = FALSE
 	`
-	_, err := r.db.ExecContext(ctx, query, id)
+	result, err := tx.ExecContext(ctx, query, user.DeletedAt, user.UpdatedAt, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
+	if affected == 0 {
+		return errors.ErrUserNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
@@ -178,3 +348,58 @@ func (r *userRepository) GetUserStats(ctx context.Context, userID uuid.UUID) (po
 	}
 	return posts, curses, days, nil
 }
+
+func (r *userRepository) GetPublicUserStats(ctx context.Context, userID uuid.UUID) (posts int, curses int, days int, err error) {
+	query := `
+		SELECT
+			COALESCE(COUNT(DISTINCT p.id), 0) as posts,
+			COALESCE(SUM(p.curse_count), 0) as curses,
+			COALESCE(EXTRACT(DAY FROM NOW() - u.created_at)::int, 0) as days
+		FROM users u
+		LEFT JOIN posts p ON p.user_id = u.id AND p.is_deleted = FALSE AND p.is_anonymous = FALSE
+		WHERE u.id = $1 AND u.is_deleted = FALSE
+		GROUP BY u.created_at
+	`
+	err = r.db.QueryRowContext(ctx, query, userID).Scan(&posts, &curses, &days)
+	if err == sql.ErrNoRows {
+		return 0, 0, 0, errors.ErrUserNotFound
+	}
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to get public user stats: %w", err)
+	}
+	return posts, curses, days, nil
+}
+
+func (r *userRepository) FindIDsForRitualNotification(ctx context.Context, offset, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT id
+		FROM users
+		WHERE notify_ritual = TRUE AND is_deleted = FALSE
+		ORDER BY id
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find ritual notification users: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Printf("failed to close rows: %v\n", err)
+		}
+	}()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return ids, nil
+}
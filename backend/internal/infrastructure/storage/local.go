@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"noroi/internal/gateway"
+)
+
+// localStorage keeps files in a directory on the local disk. Files are only
+// readable by the server process, since they contain personal data.
+type localStorage struct {
+	dir string
+}
+
+// NewLocalStorage uses dir, read from EXPORT_STORAGE_DIR by the caller, creating it if needed
+func NewLocalStorage(dir string) (gateway.FileStorage, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &localStorage{dir: dir}, nil
+}
+
+func (s *localStorage) Save(ctx context.Context, name string, r io.Reader) (int64, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return 0, err
+	}
+
+	// Write to a temporary file first so that a partial file is never served
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		_ = tmp.Close()
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to store file: %w", err)
+	}
+	return size, nil
+}
+
+func (s *localStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return f, nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+func (s *localStorage) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	return filepath.Join(s.dir, name), nil
+}
@@ -0,0 +1,40 @@
+package webpush
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type Config struct {
+	PublicKey  string // VAPID public key (base64url, uncompressed P-256 point)
+	PrivateKey string // VAPID private key (base64url, 32-byte scalar)
+	Subject    string // mailto: or https: contact for the push service operator
+	// AllowInsecureEndpoints permits http:// and loopback endpoints so that
+	// subscriptions pointing at a local push-service stub can be registered.
+	AllowInsecureEndpoints bool
+	Timeout                time.Duration
+}
+
+func NewConfig() *Config {
+	allowInsecure, _ := strconv.ParseBool(os.Getenv("WEBPUSH_ALLOW_INSECURE_ENDPOINTS"))
+	return &Config{
+		PublicKey:              os.Getenv("WEBPUSH_VAPID_PUBLIC_KEY"),
+		PrivateKey:             os.Getenv("WEBPUSH_VAPID_PRIVATE_KEY"),
+		Subject:                getEnv("WEBPUSH_VAPID_SUBJECT", "mailto:admin@noroi.local"),
+		AllowInsecureEndpoints: allowInsecure,
+		Timeout:                10 * time.Second,
+	}
+}
+
+// Enabled reports whether VAPID keys are configured.
+func (c *Config) Enabled() bool {
+	return c.PublicKey != "" && c.PrivateKey != ""
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
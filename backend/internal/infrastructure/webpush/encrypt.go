@@ -0,0 +1,164 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// recordSize is the aes128gcm record size advertised in the header. Payloads
+// are always sent as a single record, so it only needs to exceed the payload.
+const recordSize = 4096
+
+// maxPayloadSize keeps the encrypted body under the 4096-byte limit that push
+// services are required to accept (RFC 8030 §7.2).
+const maxPayloadSize = recordSize - 16 - 1 - 86
+
+// encrypt encrypts a payload for a subscription using the aes128gcm content
+// coding with the Web Push key derivation (RFC 8188 + RFC 8291).
+func encrypt(payload, uaPublic, authSecret []byte) ([]byte, error) {
+	if len(payload) > maxPayloadSize {
+		return nil, fmt.Errorf("payload too large: %d bytes", len(payload))
+	}
+
+	curve := ecdh.P256()
+	uaKey, err := curve.NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+
+	// A fresh application-server key pair and salt per message
+	asKey, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	sharedSecret, err := asKey.ECDH(uaKey)
+	if err != nil {
+		return nil, fmt.Errorf("ecdh failed: %w", err)
+	}
+	asPublic := asKey.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public, 32)
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := expand(sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := expand(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := expand(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm: %w", err)
+	}
+
+	// Single (and therefore last) record: payload followed by the 0x02 delimiter
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	// Header: salt(16) || rs(4) || idlen(1) || keyid(as_public)
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func expand(secret, salt, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		return nil, fmt.Errorf("hkdf failed: %w", err)
+	}
+	return out, nil
+}
+
+// Decrypt reverses encrypt for a single-record aes128gcm body. It plays the
+// browser's role and is used by the local push-service stub (cmd/pushstub).
+func Decrypt(body []byte, uaPrivate *ecdh.PrivateKey, authSecret []byte) ([]byte, error) {
+	if len(body) < 21 {
+		return nil, fmt.Errorf("body too short")
+	}
+	salt := body[:16]
+	idLen := int(body[20])
+	if len(body) < 21+idLen {
+		return nil, fmt.Errorf("body too short")
+	}
+	asPublic := body[21 : 21+idLen]
+	ciphertext := body[21+idLen:]
+
+	asKey, err := ecdh.P256().NewPublicKey(asPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender key: %w", err)
+	}
+	sharedSecret, err := uaPrivate.ECDH(asKey)
+	if err != nil {
+		return nil, fmt.Errorf("ecdh failed: %w", err)
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPrivate.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := expand(sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := expand(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := expand(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm: %w", err)
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	// Strip padding and the record delimiter
+	for i := len(plaintext) - 1; i >= 0; i-- {
+		switch plaintext[i] {
+		case 0x00:
+			continue
+		case 0x02:
+			return plaintext[:i], nil
+		default:
+			return nil, fmt.Errorf("invalid record delimiter")
+		}
+	}
+	return nil, fmt.Errorf("missing record delimiter")
+}
@@ -0,0 +1,181 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"noroi/internal/domain/entity"
+	"noroi/internal/gateway"
+	"noroi/pkg/errors"
+)
+
+type sender struct {
+	client        *http.Client
+	key           *vapidKey
+	subject       string
+	allowInsecure bool
+}
+
+func NewSender(cfg *Config) (gateway.PushSender, error) {
+	if !cfg.Enabled() {
+		return nil, errors.ErrPushDisabled
+	}
+
+	key, err := parseVAPIDKey(cfg.PublicKey, cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: cfg.Timeout}
+	if !cfg.AllowInsecureEndpoints {
+		client.Transport = publicOnlyTransport()
+	}
+
+	return &sender{
+		client:        client,
+		key:           key,
+		subject:       cfg.Subject,
+		allowInsecure: cfg.AllowInsecureEndpoints,
+	}, nil
+}
+
+func (s *sender) PublicKey() string {
+	return s.key.publicKeyString()
+}
+
+func (s *sender) Send(ctx context.Context, sub *entity.PushSubscription, msg *gateway.PushMessage) error {
+	if err := s.ValidateEndpoint(sub.Endpoint); err != nil {
+		return err
+	}
+
+	uaPublic, err := entity.DecodePushKey(sub.P256dh)
+	if err != nil {
+		return errors.ErrInvalidPushSubscription
+	}
+	authSecret, err := entity.DecodePushKey(sub.Auth)
+	if err != nil {
+		return errors.ErrInvalidPushSubscription
+	}
+
+	body, err := encrypt(msg.Payload, uaPublic, authSecret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt push payload: %w", err)
+	}
+
+	authorization, err := s.key.authorization(sub.Endpoint, s.subject)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build push request: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(msg.TTL/time.Second)))
+	if msg.Urgency != "" {
+		req.Header.Set("Urgency", string(msg.Urgency))
+	}
+	if msg.Topic != "" {
+		req.Header.Set("Topic", msg.Topic)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if errors.Is(err, errors.ErrInvalidPushSubscription) {
+			return errors.ErrInvalidPushSubscription
+		}
+		return fmt.Errorf("%w: %v", errors.ErrPushTemporaryFailure, err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close push response body: %v\n", err)
+		}
+	}()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return errors.ErrPushSubscriptionExpired
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("%w: status %d", errors.ErrPushTemporaryFailure, resp.StatusCode)
+	default:
+		return fmt.Errorf("push service rejected message: status %d", resp.StatusCode)
+	}
+}
+
+// ValidateEndpoint rejects endpoints the server must not POST to. Push services
+// are always reached over https; plain http and loopback hosts are only accepted
+// when AllowInsecureEndpoints is set (local push-service stub).
+func (s *sender) ValidateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return errors.ErrInvalidPushSubscription
+	}
+	if s.allowInsecure {
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return errors.ErrInvalidPushSubscription
+		}
+		return nil
+	}
+	if u.Scheme != "https" {
+		return errors.ErrInvalidPushSubscription
+	}
+
+	host := u.Hostname()
+	if host == "localhost" {
+		return errors.ErrInvalidPushSubscription
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return errors.ErrInvalidPushSubscription
+	}
+	return nil
+}
+
+// publicOnlyTransport checks the address actually dialled, so that a host name
+// resolving (or later re-resolving, or redirecting) to an internal address is
+// refused as well. No proxy is used: the check would only see the proxy.
+func publicOnlyTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return errors.ErrInvalidPushSubscription
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return errors.ErrInvalidPushSubscription
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// publicIP reports whether ip is a globally routable unicast address
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	// Carrier-grade NAT (100.64.0.0/10) is not covered by IsPrivate
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 100 && ip4[1]&0xc0 == 64 {
+		return false
+	}
+	return true
+}
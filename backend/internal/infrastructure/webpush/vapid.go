@@ -0,0 +1,94 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// vapidTokenDuration is the lifetime of the VAPID JWT (RFC 8292 caps it at 24h).
+const vapidTokenDuration = 12 * time.Hour
+
+type vapidKey struct {
+	private   *ecdsa.PrivateKey
+	publicRaw []byte
+}
+
+// GenerateVAPIDKeys creates a new P-256 key pair encoded as base64url,
+// in the format expected by WEBPUSH_VAPID_PUBLIC_KEY / WEBPUSH_VAPID_PRIVATE_KEY.
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate VAPID key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+func parseVAPIDKey(publicKey, privateKey string) (*vapidKey, error) {
+	d, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(privateKey, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key encoding: %w", err)
+	}
+
+	ecdhKey, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	pub := ecdhKey.PublicKey().Bytes()
+
+	if publicKey != "" {
+		configured, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(publicKey, "="))
+		if err != nil {
+			return nil, fmt.Errorf("invalid VAPID public key encoding: %w", err)
+		}
+		if string(configured) != string(pub) {
+			return nil, fmt.Errorf("VAPID public key does not match private key")
+		}
+	}
+
+	// pub is an uncompressed point: 0x04 || X || Y
+	priv := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}
+
+	return &vapidKey{private: priv, publicRaw: pub}, nil
+}
+
+func (k *vapidKey) publicKeyString() string {
+	return base64.RawURLEncoding.EncodeToString(k.publicRaw)
+}
+
+// authorization builds the "vapid t=..., k=..." Authorization header for an endpoint.
+func (k *vapidKey) authorization(endpoint, subject string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	claims := jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{u.Scheme + "://" + u.Host},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(vapidTokenDuration)),
+		Subject:   subject,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(k.private)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+
+	return "vapid t=" + token + ", k=" + k.publicKeyString(), nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"noroi/internal/domain/value"
+
+	"github.com/google/uuid"
+)
+
+// StageFunnelStep aggregates the selection stages of one name across a
+// category of the user's applications. Counts are of applications.
+type StageFunnelStep struct {
+	Category value.ApplicationCategory
+	Name     string
+	Reached  int
+	Passed   int
+	Failed   int
+	Pending  int
+	PassRate *float64 // passed / (passed + failed); nil before any result
+	// Conversion is Reached relative to the first step of the category
+	Conversion *float64
+	// MedianDaysToNext is the median time from a passed stage to the next one
+	MedianDaysToNext *float64
+}
+
+// ApplicationOutcome counts the applications of one category by how they ended
+type ApplicationOutcome struct {
+	Category     value.ApplicationCategory
+	Applications int
+	Active       int      // todo, scheduled or in_progress
+	Offers       int      // done, main only: a done internship or info session is no offer
+	Rejected     int      // rejected
+	Withdrawn    int      // withdrawn
+	OfferRate    *float64 // nil outside main
+}
+
+// StatusDuration is the median time applications of a category stayed in a status
+type StatusDuration struct {
+	Category   value.ApplicationCategory
+	Status     value.ApplicationStatus
+	Samples    int
+	MedianDays float64
+}
+
+// WeeklyActivity counts what happened in one week (Monday to Sunday in the
+// user's time zone)
+type WeeklyActivity struct {
+	WeekStart       time.Time // midnight on Monday, as a date
+	Applications    int       // applications added
+	StagesScheduled int       // selection stages dated in the week
+	StatusChanges   int
+	Offers          int // changes to done, main only as in ApplicationOutcome
+	Rejections      int // changes to rejected
+	Withdrawals     int // changes to withdrawn
+}
+
+type ApplicationAnalyticsRepository interface {
+	// StageFunnel returns the funnel steps of each category, in stage order
+	StageFunnel(ctx context.Context, userID uuid.UUID) ([]*StageFunnelStep, error)
+	Outcomes(ctx context.Context, userID uuid.UUID) ([]*ApplicationOutcome, error)
+	// StatusDurations covers the statuses applications have already left
+	StatusDurations(ctx context.Context, userID uuid.UUID) ([]*StatusDuration, error)
+	// WeeklyActivity returns the given number of weeks up to the one containing
+	// now, oldest first, with weeks in timeZone (an IANA zone name)
+	WeeklyActivity(ctx context.Context, userID uuid.UUID, now time.Time, timeZone string, weeks int) ([]*WeeklyActivity, error)
+}
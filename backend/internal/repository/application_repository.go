@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"noroi/internal/domain/entity"
 	"noroi/internal/domain/value"
@@ -9,9 +10,71 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApplicationQuery struct {
+	UserID         uuid.UUID
+	Category       value.ApplicationCategory // optional
+	Status         value.ApplicationStatus   // optional
+	ScheduledFrom  *time.Time                // optional: scheduled_at >= ScheduledFrom
+	ScheduledUntil *time.Time                // optional: scheduled_at < ScheduledUntil
+	Descending     bool                      // sort by scheduled_at descending (unscheduled last either way)
+	Limit          int
+	Offset         int
+}
+
+type ApplicationWithCompany struct {
+	Application *entity.Application
+	Company     *entity.Company
+}
+
+// ApplicationImport is everything one confirmed import saves
+type ApplicationImport struct {
+	Companies []*entity.Company
+	Created   []*entity.Application
+	Updated   []*ApplicationUpdate
+}
+
+// ApplicationUpdate is an application to update with the status changes to record
+type ApplicationUpdate struct {
+	Application *entity.Application
+	Changes     []*entity.ApplicationStatusChange
+}
+
+// ApplicationCount is the number of a user's applications with one category and status
+type ApplicationCount struct {
+	Category value.ApplicationCategory
+	Status   value.ApplicationStatus
+	Count    int
+}
+
 type ApplicationRepository interface {
+	// Create inserts the application and records its initial status in the status history
 	Create(ctx context.Context, app *entity.Application) error
-	Update(ctx context.Context, app *entity.Application) error
+	// Update saves the application and appends the given status changes to its history in one transaction
+	Update(ctx context.Context, app *entity.Application, changes ...*entity.ApplicationStatusChange) error
 	FindByID(ctx context.Context, id uuid.UUID) (*entity.Application, error)
 	FindByUserAndCompany(ctx context.Context, userID, companyID uuid.UUID, category value.ApplicationCategory) (*entity.Application, error)
+	// FindByStageExternalUID returns the user's application, of any company or
+	// category, that has a stage imported from externalUID; nil if none
+	FindByStageExternalUID(ctx context.Context, userID uuid.UUID, externalUID string) (*ApplicationWithCompany, error)
+	// SaveImport creates the companies and applications of an import and
+	// updates the existing applications in one transaction; nothing is saved
+	// if any part fails
+	SaveImport(ctx context.Context, imp *ApplicationImport) error
+	// List returns one page of the user's applications and the total number matching the filters
+	List(ctx context.Context, query ApplicationQuery) ([]*ApplicationWithCompany, int, error)
+	// FindScheduled returns the user's open applications (not done, withdrawn or rejected)
+	// where the application itself or a pending stage is scheduled in [from, until),
+	// with their companies and stages. until is optional. At most limit
+	// applications are returned, those with the earliest event in the range
+	// first; truncated reports that more matched.
+	FindScheduled(ctx context.Context, userID uuid.UUID, from time.Time, until *time.Time, limit int) (results []*ApplicationWithCompany, truncated bool, err error)
+	// FindForCalendar returns the user's applications of any status where the
+	// application itself or any stage is scheduled at or after since, with their
+	// companies and stages
+	FindForCalendar(ctx context.Context, userID uuid.UUID, since time.Time) ([]*ApplicationWithCompany, error)
+	// CountByCategoryAndStatus counts the user's applications per category and
+	// status; combinations without applications are left out
+	CountByCategoryAndStatus(ctx context.Context, userID uuid.UUID) ([]*ApplicationCount, error)
+	// FindStatusHistory returns the application's status changes, oldest first
+	FindStatusHistory(ctx context.Context, applicationID uuid.UUID) ([]*entity.ApplicationStatusChange, error)
 }
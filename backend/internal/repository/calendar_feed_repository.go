@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"noroi/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+type CalendarFeedRepository interface {
+	// Save stores the user's feed, replacing the token of an existing one
+	Save(ctx context.Context, feed *entity.CalendarFeed) error
+	// FindByUserID returns the user's feed, or nil if there is none
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*entity.CalendarFeed, error)
+	// FindByTokenHash returns the feed whose token hashes to tokenHash, or nil
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.CalendarFeed, error)
+	// RecordAccess sets the time the feed was last fetched
+	RecordAccess(ctx context.Context, userID uuid.UUID, at time.Time) error
+	// Delete removes the user's feed so that its URL stops working
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
@@ -14,7 +14,7 @@ type CompanyQuery struct {
 	Offset   int
 	FilterMy bool
 	Category string // optional: main|intern|info
-	Status   string // optional: todo|scheduled|in_progress|done|withdrawn
+	Status   string // optional: todo|scheduled|in_progress|done|withdrawn|rejected
 	Search   string // optional: partial match on company name
 }
 
@@ -25,5 +25,7 @@ type CompanyWithApplication struct {
 
 type CompanyRepository interface {
 	FindWithUserApplication(ctx context.Context, query CompanyQuery) ([]*CompanyWithApplication, error)
+	// FindByID returns nil if the company does not exist
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.Company, error)
 	Create(ctx context.Context, company *entity.Company) error
 }
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"noroi/internal/domain/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CurseRecord is a curse given or received by the exporting user. The other
+// party is not included: curses are anonymous to their recipient.
+type CurseRecord struct {
+	PostID    uuid.UUID
+	CreatedAt time.Time
+}
+
+// ApplicationRecord is an application with its company name and selection stages
+type ApplicationRecord struct {
+	Application *entity.Application
+	CompanyName string
+}
+
+// RitualParticipationRecord is a ritual the user took part in, including the points earned
+type RitualParticipationRecord struct {
+	Participant     *entity.RitualParticipant
+	RitualStartTime time.Time
+	RitualStatus    string
+}
+
+// PersonalData is everything a user has stored, read from a single snapshot
+type PersonalData struct {
+	Posts                []*entity.Post
+	CursesGiven          []*CurseRecord
+	CursesReceived       []*CurseRecord
+	Applications         []*ApplicationRecord
+	RitualParticipations []*RitualParticipationRecord
+}
+
+type DataExportRepository interface {
+	// Create stores a new export job
+	Create(ctx context.Context, export *entity.DataExport) error
+
+	// FindByUserID returns the user's most recent export jobs, newest first
+	FindByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.DataExport, error)
+
+	// FindByTokenHash finds the export whose download token hashes to tokenHash
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.DataExport, error)
+
+	// ClaimNext leases one due job and increments its attempt counter. Rows locked
+	// by another worker are skipped, and a job whose worker crashed becomes due
+	// again after lease. A crashed job that already used maxAttempts is marked
+	// failed instead of being claimed again. Returns nil when there is nothing to do.
+	ClaimNext(ctx context.Context, lease time.Duration, maxAttempts int) (*entity.DataExport, error)
+
+	// FindExpired returns up to limit ready exports whose link expired before now
+	FindExpired(ctx context.Context, now time.Time, limit int) ([]*entity.DataExport, error)
+
+	// Update persists the outcome of an attempt or an expiry
+	Update(ctx context.Context, export *entity.DataExport) error
+
+	// CollectPersonalData reads everything the user has stored, apart from the
+	// profile itself, in one read-only transaction
+	CollectPersonalData(ctx context.Context, userID uuid.UUID) (*PersonalData, error)
+}
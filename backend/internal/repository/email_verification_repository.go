@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"noroi/internal/domain/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EmailVerificationRepository interface {
+	// Create stores a new verification token
+	Create(ctx context.Context, token *entity.EmailVerificationToken) error
+
+	// FindByHash finds a verification token by its hash
+	FindByHash(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error)
+
+	// CountSince counts verification tokens issued to the user since the given time
+	CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+
+	// Consume marks the token used and stores the user's email_verified_at in
+	// one transaction. Every other outstanding token of the user is invalidated too.
+	// It returns false if the token was already used.
+	Consume(ctx context.Context, token *entity.EmailVerificationToken, user *entity.User) (bool, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"noroi/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+type LoginEventRepository interface {
+	// Create records a login event
+	Create(ctx context.Context, event *entity.LoginEvent) error
+
+	// FindByUserID retrieves the user's most recent login events
+	FindByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.LoginEvent, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"noroi/internal/domain/entity"
+	"time"
+)
+
+type LoginThrottleRepository interface {
+	// LockedUntil returns when the lock on key ends, or the zero time if it is not locked
+	LockedUntil(ctx context.Context, scope entity.LoginThrottleScope, key string) (time.Time, error)
+
+	// RecordFailure atomically counts a failed login for key and returns the
+	// number of consecutive failures. Failures before resetBefore are forgotten.
+	RecordFailure(ctx context.Context, scope entity.LoginThrottleScope, key string, now, resetBefore time.Time) (int, error)
+
+	// Lock locks key until the given time (never shortening an existing lock)
+	Lock(ctx context.Context, scope entity.LoginThrottleScope, key string, until time.Time) error
+
+	// Reset clears the failure counter for key
+	Reset(ctx context.Context, scope entity.LoginThrottleScope, key string) error
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"noroi/internal/domain/entity"
+	"time"
+)
+
+type MailOutboxRepository interface {
+	// Enqueue stores a mail for asynchronous delivery. A mail whose DedupKey
+	// was already enqueued is silently dropped.
+	Enqueue(ctx context.Context, mail *entity.OutboundMail) error
+
+	// ClaimDue leases up to limit pending mails whose next attempt is due and
+	// increments their attempt counter. Rows locked by another worker are skipped,
+	// and a lease that is never resolved (crashed worker) expires after lease.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.OutboundMail, error)
+
+	// Update persists the outcome of a delivery attempt
+	Update(ctx context.Context, mail *entity.OutboundMail) error
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"noroi/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+type MFARepository interface {
+	// FindByUserID finds the user's TOTP settings
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error)
+
+	// SavePending stores a new, not yet confirmed secret, replacing any earlier
+	// pending one. It never overwrites an enabled secret.
+	SavePending(ctx context.Context, mfa *entity.UserMFA) error
+
+	// Enable confirms the pending secret, records the step of the confirming
+	// code and replaces the recovery codes, in one transaction
+	Enable(ctx context.Context, userID uuid.UUID, step int64, codes []*entity.MFARecoveryCode) error
+
+	// Disable removes the TOTP settings and recovery codes
+	Disable(ctx context.Context, userID uuid.UUID) error
+
+	// UseStep records step as the last accepted one. It returns false if a code
+	// for this step or a later one was already accepted (replay).
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+
+	// UseRecoveryCode consumes an unused recovery code. It returns false if no
+	// unused code matches.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+
+	// ReplaceRecoveryCodes discards all recovery codes and stores new ones
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*entity.MFARecoveryCode) error
+
+	// CountUnusedRecoveryCodes counts the recovery codes still available
+	CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error)
+}
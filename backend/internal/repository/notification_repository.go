@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"noroi/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+type NotificationRepository interface {
+	// ClaimBroadcast records a broadcast key and reports whether this caller claimed it first.
+	// Used so that only one replica sends a given broadcast (e.g. the ritual start for a day).
+	ClaimBroadcast(ctx context.Context, key string) (bool, error)
+
+	// SaveInbox stores n in the user's inbox. A notification whose DedupKey is
+	// already stored is skipped without an error.
+	SaveInbox(ctx context.Context, n *entity.InboxNotification) error
+	// FindInbox returns the user's inbox, newest first
+	FindInbox(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*entity.InboxNotification, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"noroi/internal/domain/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PasswordResetRepository interface {
+	// Create stores a new reset token
+	Create(ctx context.Context, token *entity.PasswordResetToken) error
+
+	// FindByHash finds a reset token by its hash
+	FindByHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error)
+
+	// CountSince counts reset tokens issued to the user since the given time
+	CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+
+	// Consume marks the token used and stores the user's new password in one
+	// transaction. Every other outstanding token of the user is invalidated too.
+	// It returns false if the token was already used.
+	Consume(ctx context.Context, token *entity.PasswordResetToken, user *entity.User) (bool, error)
+}
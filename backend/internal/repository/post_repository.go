@@ -29,6 +29,10 @@ type PostRepository interface {
 	// FindByUserID retrieves all posts by a specific user
 	FindByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*entity.Post, error)
 
+	// FindPublicByUserID retrieves the posts shown on a user's public profile.
+	// Anonymous posts are never included.
+	FindPublicByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*entity.Post, error)
+
 	// Update updates an existing post
 	Update(ctx context.Context, post *entity.Post) error
 
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"noroi/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+type PushSubscriptionRepository interface {
+	// Upsert creates a subscription or re-binds an existing endpoint to the given user
+	Upsert(ctx context.Context, sub *entity.PushSubscription) error
+
+	// FindByUserID retrieves all subscriptions for a user
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.PushSubscription, error)
+
+	// Delete removes a subscription by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// DeleteByUserAndEndpoint removes a user's subscription for an endpoint
+	DeleteByUserAndEndpoint(ctx context.Context, userID uuid.UUID, endpoint string) error
+
+	// RecordDelivery updates delivery bookkeeping after a send attempt
+	RecordDelivery(ctx context.Context, id uuid.UUID, success bool) error
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+
+	"github.com/google/uuid"
+)
+
+// DueReminder is a claimed reminder with what its channel needs to deliver it.
+// ClaimedAttempts and LeaseUntil identify the claim, so that its result is
+// only saved while the claim is still current.
+type DueReminder struct {
+	Reminder        *entity.Reminder
+	UserID          uuid.UUID
+	Email           string
+	CompanyName     string
+	ClaimedAttempts int
+	LeaseUntil      time.Time
+}
+
+// UserReminder is a reminder with the application it belongs to
+type UserReminder struct {
+	Reminder    *entity.Reminder
+	CompanyName string
+	Category    value.ApplicationCategory
+}
+
+type ReminderRepository interface {
+	Create(ctx context.Context, reminder *entity.Reminder) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.Reminder, error)
+	// FindByApplicationID returns the application's reminders ordered by target time
+	FindByApplicationID(ctx context.Context, applicationID uuid.UUID) ([]*entity.Reminder, error)
+	// FindPendingByUserID returns the pending reminders of all the user's
+	// applications ordered by their next firing
+	FindPendingByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Reminder, error)
+	// FindPendingBefore returns up to limit of the user's pending reminders
+	// firing before until, with their applications, ordered by their next firing
+	FindPendingBefore(ctx context.Context, userID uuid.UUID, until time.Time, limit int) ([]*UserReminder, error)
+	// CountPendingBefore returns the number of the user's pending reminders firing before until
+	CountPendingBefore(ctx context.Context, userID uuid.UUID, until time.Time) (int, error)
+	Update(ctx context.Context, reminder *entity.Reminder) error
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ClaimDue leases up to limit pending reminders that are due and increments
+	// their attempt counter. Rows locked by another worker are skipped, and a
+	// lease that is never resolved (crashed worker) expires after lease.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*DueReminder, error)
+	// UpdateClaimed saves the result of a claimed delivery. It reports false
+	// without saving when the reminder changed after the claim (acknowledged,
+	// snoozed, or claimed again after the lease expired).
+	UpdateClaimed(ctx context.Context, due *DueReminder) (bool, error)
+}
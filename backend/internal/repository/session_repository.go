@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"noroi/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+type SessionRepository interface {
+	// Create creates a session together with its first refresh token
+	Create(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error
+
+	// FindByID finds a session by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.Session, error)
+
+	// FindActiveByUserID retrieves a user's sessions that are neither revoked nor expired
+	FindActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error)
+
+	// FindRefreshToken finds a refresh token by its hash
+	FindRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+
+	// Rotate marks used as consumed and stores next in one transaction.
+	// It returns false without storing next if used was already consumed (concurrent reuse).
+	Rotate(ctx context.Context, used *entity.RefreshToken, next *entity.RefreshToken) (bool, error)
+
+	// Revoke revokes a single session and therefore its whole refresh token family
+	Revoke(ctx context.Context, id uuid.UUID, reason entity.SessionRevokeReason) error
+
+	// RevokeAllByUserID revokes every active session of a user except keepID (uuid.Nil keeps none)
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID, keepID uuid.UUID, reason entity.SessionRevokeReason) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"noroi/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+type UserPreferenceRepository interface {
+	// FindByUserID loads a user's preferences; keys that were never saved keep their defaults
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*entity.UserPreferences, error)
+
+	// Save stores every preference key in one transaction
+	Save(ctx context.Context, preferences *entity.UserPreferences) error
+
+	// SaveSettings stores the user's privacy and notification columns together
+	// with every preference key in one transaction
+	SaveSettings(ctx context.Context, user *entity.User, preferences *entity.UserPreferences) error
+}
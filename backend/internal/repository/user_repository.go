@@ -18,11 +18,27 @@ type UserRepository interface {
 	// FindByEmail finds a user by email
 	FindByEmail(ctx context.Context, email value.Email) (*entity.User, error)
 
-	// Update updates an existing user
+	// Update updates an existing user. A username change is copied to the
+	// user's posts (posts.username) in the same transaction. The role and
+	// email_verified_at are not written; they change only through UpdateRole
+	// and email verification.
 	Update(ctx context.Context, user *entity.User) error
 
-	// Delete soft deletes a user
-	Delete(ctx context.Context, id uuid.UUID) error
+	// UpdatePasswordHash replaces only the stored password hash, e.g. when it is
+	// upgraded to the current hashing scheme
+	UpdatePasswordHash(ctx context.Context, user *entity.User) error
+
+	// UpdateRole replaces only the user's role
+	UpdateRole(ctx context.Context, id uuid.UUID, role value.Role) error
+
+	// Delete soft deletes a user (call user.Delete first) in one transaction:
+	// the email address is released for re-registration, credentials are
+	// cleared and private data such as job applications is purged. Posts and
+	// curses are kept.
+	Delete(ctx context.Context, user *entity.User) error
+
+	// FindByHandle finds an active user by normalised handle
+	FindByHandle(ctx context.Context, handle value.Handle) (*entity.User, error)
 
 	// ExistsByEmail checks if a user with the given email exists
 	ExistsByEmail(ctx context.Context, email value.Email) (bool, error)
@@ -30,4 +46,10 @@ type UserRepository interface {
 	// GetUserStats retrieves statistics for a user
 	// Returns: posts count, total curses received, days since account creation
 	GetUserStats(ctx context.Context, userID uuid.UUID) (posts int, curses int, days int, err error)
+
+	// GetPublicUserStats is GetUserStats for other viewers: anonymous posts are not counted
+	GetPublicUserStats(ctx context.Context, userID uuid.UUID) (posts int, curses int, days int, err error)
+
+	// FindIDsForRitualNotification retrieves IDs of active users who opted in to ritual notifications
+	FindIDsForRitualNotification(ctx context.Context, offset, limit int) ([]uuid.UUID, error)
 }
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdminUsecase holds operations available under /admin
+type AdminUsecase struct {
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+}
+
+func NewAdminUsecase(userRepo repository.UserRepository, sessionRepo repository.SessionRepository) *AdminUsecase {
+	return &AdminUsecase{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+type ChangeRoleInput struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type AdminUserResponse struct {
+	ID            string   `json:"id"`
+	Email         string   `json:"email"`
+	Username      string   `json:"username"`
+	Handle        string   `json:"handle"`
+	Role          string   `json:"role"`
+	Permissions   []string `json:"permissions"`
+	EmailVerified bool     `json:"email_verified"`
+	CreatedAt     string   `json:"created_at"`
+}
+
+// GetUser returns a user's account and access level
+func (uc *AdminUsecase) GetUser(ctx context.Context, userID uuid.UUID) (*AdminUserResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toAdminUserResponse(user), nil
+}
+
+// ChangeUserRole assigns a role to another user. Tokens carry a snapshot of
+// the role, so a user who loses permissions is signed out everywhere instead
+// of keeping them until the next refresh.
+func (uc *AdminUsecase) ChangeUserRole(ctx context.Context, actorID, userID uuid.UUID, input ChangeRoleInput) (*AdminUserResponse, error) {
+	role, err := value.NewRole(input.Role)
+	if err != nil {
+		return nil, err
+	}
+	// Prevent admins from locking themselves (or the last admin) out
+	if actorID == userID {
+		return nil, errors.ErrCannotChangeOwnRole
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return toAdminUserResponse(user), nil
+	}
+
+	demoted := false
+	for _, p := range user.Role.Permissions() {
+		if !role.HasPermission(p) {
+			demoted = true
+			break
+		}
+	}
+
+	user.ChangeRole(role)
+	if err := uc.userRepo.UpdateRole(ctx, user.ID, user.Role); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	if demoted {
+		if err := uc.sessionRepo.RevokeAllByUserID(ctx, user.ID, uuid.Nil, entity.SessionRevokeReasonRoleChanged); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	return toAdminUserResponse(user), nil
+}
+
+func toAdminUserResponse(user *entity.User) *AdminUserResponse {
+	return &AdminUserResponse{
+		ID:            user.ID.String(),
+		Email:         user.Email.String(),
+		Username:      user.Username,
+		Handle:        user.Handle.String(),
+		Role:          user.Role.String(),
+		Permissions:   permissionNames(user.Role),
+		EmailVerified: user.IsEmailVerified(),
+		CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// permissionNames lists the permissions granted to role as strings, as they
+// appear in access tokens and responses
+func permissionNames(role value.Role) []string {
+	perms := role.Permissions()
+	names := make([]string, len(perms))
+	for i, p := range perms {
+		names[i] = string(p)
+	}
+	return names
+}
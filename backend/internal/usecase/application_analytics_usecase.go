@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"noroi/internal/domain/value"
+	"noroi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAnalyticsWeeks = 12
+	maxAnalyticsWeeks     = 52
+)
+
+// ApplicationAnalyticsUsecase reports how the user's selections have gone.
+// The figures are aggregated by the repository; this only shapes them.
+type ApplicationAnalyticsUsecase struct {
+	analyticsRepo  repository.ApplicationAnalyticsRepository
+	preferenceRepo repository.UserPreferenceRepository
+}
+
+func NewApplicationAnalyticsUsecase(
+	analyticsRepo repository.ApplicationAnalyticsRepository,
+	preferenceRepo repository.UserPreferenceRepository,
+) *ApplicationAnalyticsUsecase {
+	return &ApplicationAnalyticsUsecase{
+		analyticsRepo:  analyticsRepo,
+		preferenceRepo: preferenceRepo,
+	}
+}
+
+type ApplicationAnalyticsResponse struct {
+	TimeZone   string                       `json:"time_zone"`
+	Categories []*CategoryAnalyticsResponse `json:"categories"` // main, intern, info
+	Weekly     []*WeeklyActivityResponse    `json:"weekly"`     // oldest first, ending with this week
+}
+
+type CategoryAnalyticsResponse struct {
+	Category     string `json:"category"`
+	Applications int    `json:"applications"`
+	Active       int    `json:"active"`
+	Offers       int    `json:"offers"`
+	Rejected     int    `json:"rejected"`
+	Withdrawn    int    `json:"withdrawn"`
+	// Offers are done main applications; intern and info have none.
+	// OfferRate is offers among finished applications (done, withdrawn or
+	// rejected), nil outside main.
+	OfferRate       *float64                  `json:"offer_rate"`
+	Funnel          []*FunnelStepResponse     `json:"funnel"`
+	StatusDurations []*StatusDurationResponse `json:"status_durations"`
+}
+
+// FunnelStepResponse counts applications that reached a stage of this name
+type FunnelStepResponse struct {
+	Stage        string   `json:"stage"`
+	Applications int      `json:"applications"`
+	Passed       int      `json:"passed"`
+	Failed       int      `json:"failed"`
+	Pending      int      `json:"pending"`
+	PassRate     *float64 `json:"pass_rate"`  // passed / (passed + failed)
+	Conversion   *float64 `json:"conversion"` // applications relative to the first step
+	// MedianDaysToNextStage is measured from passed stages to the next stage
+	MedianDaysToNextStage *float64 `json:"median_days_to_next_stage"`
+}
+
+type StatusDurationResponse struct {
+	Status     string  `json:"status"`
+	MedianDays float64 `json:"median_days"`
+	Samples    int     `json:"samples"`
+}
+
+type WeeklyActivityResponse struct {
+	WeekStart       string `json:"week_start"` // Monday (YYYY-MM-DD)
+	Applications    int    `json:"applications"`
+	StagesScheduled int    `json:"stages_scheduled"`
+	StatusChanges   int    `json:"status_changes"`
+	Offers          int    `json:"offers"`
+	Rejections      int    `json:"rejections"`
+	Withdrawals     int    `json:"withdrawals"`
+}
+
+// Analytics returns per-category funnels and outcomes and the activity of the
+// last weeks (12 when weeks is 0) in the user's time zone
+func (uc *ApplicationAnalyticsUsecase) Analytics(ctx context.Context, userID uuid.UUID, weeks int) (*ApplicationAnalyticsResponse, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+	if weeks == 0 {
+		weeks = defaultAnalyticsWeeks
+	}
+	if weeks < 1 || weeks > maxAnalyticsWeeks {
+		return nil, errors.New("invalid weeks")
+	}
+
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	loc := preferences.Location()
+
+	steps, err := uc.analyticsRepo.StageFunnel(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	outcomes, err := uc.analyticsRepo.Outcomes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	durations, err := uc.analyticsRepo.StatusDurations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	activity, err := uc.analyticsRepo.WeeklyActivity(ctx, userID, time.Now(), loc.String(), weeks)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := []value.ApplicationCategory{
+		value.ApplicationCategoryMain, value.ApplicationCategoryIntern, value.ApplicationCategoryInfo,
+	}
+	response := &ApplicationAnalyticsResponse{
+		TimeZone:   loc.String(),
+		Categories: make([]*CategoryAnalyticsResponse, 0, len(categories)),
+		Weekly:     make([]*WeeklyActivityResponse, 0, len(activity)),
+	}
+	byCategory := make(map[value.ApplicationCategory]*CategoryAnalyticsResponse, len(categories))
+	for _, category := range categories {
+		item := &CategoryAnalyticsResponse{
+			Category:        string(category),
+			Funnel:          []*FunnelStepResponse{},
+			StatusDurations: []*StatusDurationResponse{},
+		}
+		byCategory[category] = item
+		response.Categories = append(response.Categories, item)
+	}
+
+	for _, outcome := range outcomes {
+		item, ok := byCategory[outcome.Category]
+		if !ok {
+			continue
+		}
+		item.Applications = outcome.Applications
+		item.Active = outcome.Active
+		item.Offers = outcome.Offers
+		item.Rejected = outcome.Rejected
+		item.Withdrawn = outcome.Withdrawn
+		item.OfferRate = roundOptional(outcome.OfferRate)
+	}
+	for _, step := range steps {
+		item, ok := byCategory[step.Category]
+		if !ok {
+			continue
+		}
+		item.Funnel = append(item.Funnel, &FunnelStepResponse{
+			Stage:                 step.Name,
+			Applications:          step.Reached,
+			Passed:                step.Passed,
+			Failed:                step.Failed,
+			Pending:               step.Pending,
+			PassRate:              roundOptional(step.PassRate),
+			Conversion:            roundOptional(step.Conversion),
+			MedianDaysToNextStage: roundOptional(step.MedianDaysToNext),
+		})
+	}
+	for _, duration := range durations {
+		item, ok := byCategory[duration.Category]
+		if !ok {
+			continue
+		}
+		item.StatusDurations = append(item.StatusDurations, &StatusDurationResponse{
+			Status:     string(duration.Status),
+			MedianDays: roundTo(duration.MedianDays, 2),
+			Samples:    duration.Samples,
+		})
+	}
+
+	for _, week := range activity {
+		response.Weekly = append(response.Weekly, &WeeklyActivityResponse{
+			WeekStart:       week.WeekStart.Format(dateLayout),
+			Applications:    week.Applications,
+			StagesScheduled: week.StagesScheduled,
+			StatusChanges:   week.StatusChanges,
+			Offers:          week.Offers,
+			Rejections:      week.Rejections,
+			Withdrawals:     week.Withdrawals,
+		})
+	}
+
+	return response, nil
+}
+
+// roundOptional rounds an optional figure to two decimal places
+func roundOptional(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	rounded := roundTo(*v, 2)
+	return &rounded
+}
+
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
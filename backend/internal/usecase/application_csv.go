@@ -0,0 +1,626 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/encoding/japanese"
+)
+
+const (
+	// maxCSVImportRows bounds the data rows of one CSV import
+	maxCSVImportRows = 1000
+	// maxCompanyNameLength is the length of companies.name
+	maxCompanyNameLength = 255
+
+	importActionUnchanged = "unchanged"
+)
+
+// applicationCSVHeader is the column order of GET /applications/export.csv.
+// POST /applications/import/csv reads the same columns back.
+var applicationCSVHeader = []string{
+	"id", "company_id", "company_name", "category", "status", "status_from_stages",
+	"scheduled_at", "duration_minutes", "travel_buffer_minutes", "color_tag", "completed",
+	"motivation", "what_to_do", "job_axis", "strengths", "created_at", "updated_at",
+}
+
+// csvReadOnlyColumns are written by the exports and ignored by the import
+var csvReadOnlyColumns = map[string]bool{
+	"id": true, "application_id": true, "completed": true, "created_at": true, "updated_at": true,
+}
+
+// csvImportColumns are the columns the import reads. recruitment_url, industry
+// and location are only used for companies the import creates.
+var csvImportColumns = map[string]bool{
+	"company_id": true, "company_name": true, "recruitment_url": true, "industry": true, "location": true,
+	"category": true, "status": true, "status_from_stages": true, "scheduled_at": true,
+	"duration_minutes": true, "travel_buffer_minutes": true, "color_tag": true,
+	"motivation": true, "what_to_do": true, "job_axis": true, "strengths": true,
+}
+
+// ExportCSV writes the applications List returns for input to w as CSV, one
+// page at a time so that the whole list is never held in memory. Limit and
+// Offset are ignored.
+func (uc *ApplicationUsecase) ExportCSV(ctx context.Context, userID uuid.UUID, input ListApplicationsInput, w io.Writer) error {
+	input.Limit = csvExportPageSize
+	input.Offset = 0
+	page, total, err := uc.List(ctx, userID, input)
+	if err != nil {
+		return err
+	}
+
+	cw, err := newCSVWriter(w, applicationCSVHeader)
+	if err != nil {
+		return err
+	}
+	for {
+		for _, item := range page {
+			if err := cw.Write(applicationCSVRow(item)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+
+		input.Offset += len(page)
+		if len(page) == 0 || input.Offset >= total {
+			return nil
+		}
+		if page, total, err = uc.List(ctx, userID, input); err != nil {
+			return err
+		}
+	}
+}
+
+func applicationCSVRow(item *ApplicationListItem) []string {
+	durationMinutes := ""
+	if item.DurationMinutes != nil {
+		durationMinutes = strconv.Itoa(*item.DurationMinutes)
+	}
+	return []string{
+		item.ID, item.CompanyID, item.Company.Name, item.Category, item.Status,
+		strconv.FormatBool(item.StatusFromStages), optionalString(item.ScheduledAt),
+		durationMinutes, strconv.Itoa(item.TravelBufferMinutes), item.ColorTag,
+		strconv.FormatBool(item.Completed), item.Motivation, item.WhatToDo, item.JobAxis,
+		item.Strengths, item.CreatedAt, item.UpdatedAt,
+	}
+}
+
+// ImportCSVInput holds an uploaded CSV file. With DryRun the rows are
+// validated and matched but nothing is saved.
+type ImportCSVInput struct {
+	File   io.Reader
+	DryRun bool
+}
+
+type CSVImportResponse struct {
+	DryRun   bool             `json:"dry_run"`
+	Imported bool             `json:"imported"`
+	Summary  CSVImportSummary `json:"summary"`
+	Rows     []*CSVImportRow  `json:"rows"`
+	Errors   []*CSVRowError   `json:"errors"`
+	// IgnoredColumns lists header cells the import does not know
+	IgnoredColumns []string `json:"ignored_columns,omitempty"`
+}
+
+type CSVImportSummary struct {
+	Rows         int `json:"rows"`
+	Created      int `json:"created"`
+	Updated      int `json:"updated"`
+	Unchanged    int `json:"unchanged"`
+	NewCompanies int `json:"new_companies"`
+	Errors       int `json:"errors"` // rows with at least one error
+}
+
+// CSVImportRow is the outcome for one data row. Row is the row number as a
+// spreadsheet shows it (the header is row 1); Action is create, update,
+// unchanged or error.
+type CSVImportRow struct {
+	Row         int                `json:"row"`
+	Action      string             `json:"action"`
+	Company     *ImportedCompany   `json:"company,omitempty"`
+	Application *ImportedReference `json:"application,omitempty"`
+}
+
+// CSVRowError is one problem in a row; Column is empty for the row as a whole
+type CSVRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// csvRecord is one data row with the header it is read by
+type csvRecord struct {
+	row     int
+	columns map[string]int
+	cells   []string
+	errors  []*CSVRowError
+}
+
+// ImportCSV creates or updates the user's applications from a CSV file, one
+// per (company, category). Companies are matched by company_id, or by name
+// ignoring legal forms and spelling variants, and created if none matches.
+// Every row is validated first; if any row has an error nothing is saved.
+// A column missing from the header leaves that field of existing applications
+// as it is, while an empty cell clears it, except for status and color_tag.
+func (uc *ApplicationUsecase) ImportCSV(ctx context.Context, userID uuid.UUID, input ImportCSVInput) (*CSVImportResponse, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	data, err := io.ReadAll(input.File)
+	if err != nil {
+		return nil, err
+	}
+	text, err := decodeCSVText(data)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("invalid csv file: no header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv file: %w", err)
+	}
+	columns, ignored, err := csvHeaderColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*csvRecord
+	for row := 2; ; row++ {
+		cells, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv file: %w", err)
+		}
+		if strings.TrimSpace(strings.Join(cells, "")) == "" {
+			continue // blank lines spreadsheets leave at the end
+		}
+		if len(records) == maxCSVImportRows {
+			return nil, fmt.Errorf("invalid csv file: more than %d rows", maxCSVImportRows)
+		}
+		records = append(records, &csvRecord{row: row, columns: columns, cells: cells})
+	}
+
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := newImportState(userID)
+	response := &CSVImportResponse{
+		DryRun:         input.DryRun,
+		Rows:           make([]*CSVImportRow, 0, len(records)),
+		Errors:         []*CSVRowError{},
+		IgnoredColumns: ignored,
+	}
+	rowsByKey := make(map[string]int)
+	statusChanges := make(map[uuid.UUID][]*entity.ApplicationStatusChange)
+
+	for _, record := range records {
+		item, changes, err := uc.importCSVRecord(ctx, state, record, preferences.Location(), rowsByKey)
+		if err != nil {
+			return nil, err
+		}
+		if len(record.errors) > 0 {
+			item.Action = importActionError
+		}
+		if len(changes) > 0 {
+			id, _ := uuid.Parse(item.Application.ID)
+			statusChanges[id] = changes
+		}
+		response.Rows = append(response.Rows, item)
+		response.Errors = append(response.Errors, record.errors...)
+
+		switch item.Action {
+		case importActionCreate:
+			response.Summary.Created++
+		case importActionUpdate:
+			response.Summary.Updated++
+		case importActionUnchanged:
+			response.Summary.Unchanged++
+		case importActionError:
+			response.Summary.Errors++
+		}
+	}
+	response.Summary.Rows = len(records)
+	for id := range state.newCompanies {
+		if state.used(id) {
+			response.Summary.NewCompanies++
+		}
+	}
+
+	if input.DryRun || response.Summary.Errors > 0 {
+		return response, nil
+	}
+
+	// Each application is saved on its own; importing the file again after a
+	// failure part way through picks up where it stopped
+	for _, company := range state.companies {
+		if !state.newCompanies[company.ID] || !state.used(company.ID) {
+			continue
+		}
+		if err := uc.companyRepo.Create(ctx, company); err != nil {
+			return nil, err
+		}
+	}
+	for _, app := range state.changedApps {
+		if state.newApps[app.ID] {
+			uc.deriveStatus(app) // the initial status is recorded on create
+			if err := uc.appRepo.Create(ctx, app); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		changes := append(statusChanges[app.ID], uc.deriveStatus(app)...)
+		if err := uc.appRepo.Update(ctx, app, changes...); err != nil {
+			return nil, err
+		}
+	}
+
+	response.Imported = true
+	return response, nil
+}
+
+// importCSVRecord validates one row and applies it to a new or existing
+// application in memory. Problems are recorded on the record; the returned
+// error is for failures to read existing data.
+func (uc *ApplicationUsecase) importCSVRecord(ctx context.Context, state *importState, record *csvRecord, loc *time.Location, rowsByKey map[string]int) (*CSVImportRow, []*entity.ApplicationStatusChange, error) {
+	item := &CSVImportRow{Row: record.row}
+
+	company, err := uc.importCSVCompany(ctx, state, record)
+	if err != nil {
+		return nil, nil, err
+	}
+	if company != nil {
+		item.Company = &ImportedCompany{ID: company.ID.String(), Name: company.Name, IsNew: state.newCompanies[company.ID]}
+	}
+
+	s, _ := record.cell("category")
+	category := value.ApplicationCategory(strings.ToLower(s))
+	if s == "" {
+		record.fail("category", "category is required")
+	} else if err := category.Validate(); err != nil {
+		record.fail("category", "invalid category: use main, intern or info")
+	}
+
+	var status value.ApplicationStatus
+	if s, _ := record.cell("status"); s != "" {
+		status = value.ApplicationStatus(strings.ToLower(s))
+		if err := status.Validate(); err != nil {
+			record.fail("status", "invalid status: use todo, scheduled, in_progress, done, withdrawn or rejected")
+		}
+	}
+	var colorTag value.ColorTag
+	if s, _ := record.cell("color_tag"); s != "" {
+		colorTag = value.ColorTag(strings.ToLower(s))
+		if err := colorTag.Validate(); err != nil {
+			record.fail("color_tag", "invalid color_tag: use orange or purple")
+		}
+	}
+	var statusFromStages *bool
+	if s, ok := record.cell("status_from_stages"); ok && s != "" {
+		if b, err := strconv.ParseBool(s); err == nil {
+			statusFromStages = &b
+		} else {
+			record.fail("status_from_stages", "invalid status_from_stages: use true or false")
+		}
+	}
+
+	var scheduledAt *time.Time
+	s, hasScheduledAt := record.cell("scheduled_at")
+	if s != "" {
+		if t, err := parseCSVTime(s, loc); err == nil {
+			scheduledAt = &t
+		} else {
+			record.fail("scheduled_at", "invalid scheduled_at: use RFC3339 or YYYY-MM-DD HH:MM")
+		}
+	}
+	var length ScheduleLengthInput
+	length.DurationMinutes = record.minutes("duration_minutes")
+	length.TravelBufferMinutes = record.minutes("travel_buffer_minutes")
+
+	if company == nil || len(record.errors) > 0 {
+		return item, nil, nil
+	}
+
+	key := company.ID.String() + "/" + string(category)
+	if first, ok := rowsByKey[key]; ok {
+		record.fail("", fmt.Sprintf("same company and category as row %d", first))
+		return item, nil, nil
+	}
+	rowsByKey[key] = record.row
+
+	var app *entity.Application
+	if !state.newCompanies[company.ID] {
+		existing, err := uc.appRepo.FindByUserAndCompany(ctx, state.userID, company.ID, category)
+		if err != nil {
+			return nil, nil, err
+		}
+		app = existing
+	}
+
+	if app == nil {
+		if status == "" {
+			status = value.ApplicationStatusToDo
+		}
+		if colorTag == "" {
+			colorTag = value.ColorTagOrange
+		}
+		app = entity.NewApplication(state.userID, company.ID, category, status, scheduledAt, colorTag)
+		duration, buffer, err := length.resolve(app.ScheduledAt, nil, 0)
+		if err != nil {
+			record.fail(csvLengthColumn(err), err.Error())
+			return item, nil, nil
+		}
+		app.SetDuration(duration, buffer)
+		app.UpdateNotes(record.text("motivation", ""), record.text("what_to_do", ""), record.text("job_axis", ""), record.text("strengths", ""))
+		if statusFromStages != nil {
+			app.StatusFromStages = *statusFromStages
+		}
+
+		state.newApps[app.ID] = true
+		state.apps[key] = app
+		state.changedApps = append(state.changedApps, app)
+		item.Action = importActionCreate
+		item.Application = &ImportedReference{ID: app.ID.String(), Category: string(app.Category), IsNew: true}
+		return item, nil, nil
+	}
+	item.Application = &ImportedReference{ID: app.ID.String(), Category: string(app.Category)}
+
+	// Validate everything before changing the application
+	start := app.ScheduledAt
+	if hasScheduledAt {
+		start = scheduledAt
+	}
+	duration, buffer, err := length.resolve(start, app.Duration, app.TravelBuffer)
+	if err != nil {
+		record.fail(csvLengthColumn(err), err.Error())
+		return item, nil, nil
+	}
+	var path []value.ApplicationStatus
+	if status != "" && status != app.Status {
+		p, ok := uc.progress.TransitionPath(app.Status, status)
+		if !ok {
+			record.fail("status", fmt.Sprintf("invalid status: cannot change from %s to %s", app.Status, status))
+			return item, nil, nil
+		}
+		path = p
+	}
+
+	before := *app
+	var changes []*entity.ApplicationStatusChange
+	for _, next := range path {
+		if change := app.UpdateStatus(next); change != nil {
+			changes = append(changes, change)
+		}
+	}
+	if hasScheduledAt {
+		app.Reschedule(start)
+	}
+	app.SetDuration(duration, buffer)
+	if colorTag != "" {
+		app.ColorTag = colorTag
+	}
+	app.UpdateNotes(
+		record.text("motivation", app.Motivation), record.text("what_to_do", app.WhatToDo),
+		record.text("job_axis", app.JobAxis), record.text("strengths", app.Strengths),
+	)
+	if statusFromStages != nil {
+		app.StatusFromStages = *statusFromStages
+	}
+
+	if sameCSVFields(&before, app) {
+		item.Action = importActionUnchanged
+		return item, nil, nil
+	}
+	state.apps[key] = app
+	state.changedApps = append(state.changedApps, app)
+	item.Action = importActionUpdate
+	return item, changes, nil
+}
+
+// importCSVCompany resolves the company of a row by company_id, or by
+// company_name among the existing companies, proposing a new one if none matches
+func (uc *ApplicationUsecase) importCSVCompany(ctx context.Context, state *importState, record *csvRecord) (*entity.Company, error) {
+	if s, _ := record.cell("company_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			record.fail("company_id", "invalid company_id")
+			return nil, nil
+		}
+		for _, company := range state.known {
+			if company.ID == id {
+				return company, nil
+			}
+		}
+		company, err := uc.companyRepo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if company == nil {
+			record.fail("company_id", "company not found")
+			return nil, nil
+		}
+		state.known = append(state.known, company)
+		return company, nil
+	}
+
+	name, _ := record.cell("company_name")
+	switch {
+	case name == "":
+		record.fail("company_name", "company_name or company_id is required")
+		return nil, nil
+	case utf8.RuneCountInString(name) > maxCompanyNameLength || uc.companies.Key(name) == "":
+		record.fail("company_name", "invalid company_name")
+		return nil, nil
+	}
+
+	company, err := uc.findCompany(ctx, state, name)
+	if err != nil || company != nil {
+		return company, err
+	}
+	return state.addCompany(uc.companies, entity.NewCompany(name,
+		record.optional("recruitment_url"), record.optional("industry"), record.optional("location"),
+	)), nil
+}
+
+// csvHeaderColumns maps the normalised header names to their positions
+func csvHeaderColumns(header []string) (columns map[string]int, ignored []string, err error) {
+	columns = make(map[string]int, len(header))
+	for i, cell := range header {
+		name := strings.ToLower(strings.TrimSpace(cell))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		if name == "" {
+			continue
+		}
+		if _, dup := columns[name]; dup {
+			return nil, nil, fmt.Errorf("invalid csv file: duplicate column %q", name)
+		}
+		columns[name] = i
+		if !csvImportColumns[name] && !csvReadOnlyColumns[name] {
+			ignored = append(ignored, cell)
+		}
+	}
+
+	if _, ok := columns["category"]; !ok {
+		return nil, nil, errors.New("invalid csv file: missing column category")
+	}
+	_, hasID := columns["company_id"]
+	_, hasName := columns["company_name"]
+	if !hasID && !hasName {
+		return nil, nil, errors.New("invalid csv file: missing column company_name or company_id")
+	}
+	return columns, ignored, nil
+}
+
+// decodeCSVText strips a UTF-8 BOM. Text that is not UTF-8 is read as
+// Shift_JIS, which Excel on Japanese Windows saves CSV files in.
+func decodeCSVText(data []byte) (string, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(data)
+	if err != nil || bytes.ContainsRune(decoded, utf8.RuneError) {
+		return "", errors.New("invalid csv file: save it as UTF-8")
+	}
+	return string(decoded), nil
+}
+
+// csvTimeLayouts are the local date-time formats spreadsheets write, read in
+// the user's time zone; a date alone means the start of that day
+var csvTimeLayouts = []string{
+	"2006-1-2 15:04:05", "2006-1-2 15:04", "2006-1-2T15:04:05", "2006-1-2T15:04",
+	"2006/1/2 15:04:05", "2006/1/2 15:04", "2006-1-2", "2006/1/2",
+}
+
+func parseCSVTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, errors.New("invalid time")
+}
+
+// csvLengthColumn names the column a ScheduleLengthInput error is about
+func csvLengthColumn(err error) string {
+	if strings.Contains(err.Error(), "travel_buffer_minutes") {
+		return "travel_buffer_minutes"
+	}
+	return "duration_minutes"
+}
+
+func sameCSVFields(a, b *entity.Application) bool {
+	return a.Status == b.Status &&
+		a.StatusFromStages == b.StatusFromStages &&
+		sameTime(a.ScheduledAt, b.ScheduledAt) &&
+		sameDuration(a.Duration, b.Duration) &&
+		a.TravelBuffer == b.TravelBuffer &&
+		a.ColorTag == b.ColorTag &&
+		a.Motivation == b.Motivation &&
+		a.WhatToDo == b.WhatToDo &&
+		a.JobAxis == b.JobAxis &&
+		a.Strengths == b.Strengths
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// cell returns the trimmed value of a column and whether the file has that column
+func (r *csvRecord) cell(column string) (string, bool) {
+	i, ok := r.columns[column]
+	if !ok {
+		return "", false
+	}
+	if i >= len(r.cells) {
+		return "", true
+	}
+	return strings.TrimSpace(unescapeCSVFormula(r.cells[i])), true
+}
+
+// text returns a note column, or current when the file has no such column
+func (r *csvRecord) text(column, current string) string {
+	if s, ok := r.cell(column); ok {
+		return s
+	}
+	return current
+}
+
+func (r *csvRecord) optional(column string) *string {
+	if s, _ := r.cell(column); s != "" {
+		return &s
+	}
+	return nil
+}
+
+// minutes reads a whole number of minutes; an empty cell is 0, which clears
+// the value, and a missing column is nil, which keeps it
+func (r *csvRecord) minutes(column string) *int {
+	s, ok := r.cell(column)
+	if !ok {
+		return nil
+	}
+	n := 0
+	if s != "" {
+		var err error
+		if n, err = strconv.Atoi(s); err != nil {
+			r.fail(column, "invalid "+column)
+			return nil
+		}
+	}
+	return &n
+}
+
+func (r *csvRecord) fail(column, message string) {
+	r.errors = append(r.errors, &CSVRowError{Row: r.row, Column: column, Message: message})
+}
@@ -0,0 +1,572 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"noroi/internal/domain/domain_service"
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+	"noroi/internal/repository"
+	"noroi/pkg/ical"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// maxImportEvents bounds the events read from one .ics file
+	maxImportEvents = 200
+	// maxImportNotes is the longest stage note built from an event's location and description
+	maxImportNotes = 2000
+	// maxExternalUIDLength is the longest UID stored for dedup (selection_stages.external_uid)
+	maxExternalUIDLength = 255
+)
+
+// ImportICSInput holds an uploaded .ics file and how to import it. Without
+// Confirm nothing is saved and the response is a preview of what would be.
+type ImportICSInput struct {
+	File     io.Reader
+	Category string   // main | intern | info; guessed per event when empty
+	Exclude  []string // UIDs of events to leave out
+	Confirm  bool
+}
+
+type ICSImportResponse struct {
+	Confirmed bool             `json:"confirmed"`
+	Summary   ICSImportSummary `json:"summary"`
+	Items     []*ICSImportItem `json:"items"`
+}
+
+type ICSImportSummary struct {
+	NewCompanies    int `json:"new_companies"`
+	NewApplications int `json:"new_applications"`
+	NewStages       int `json:"new_stages"`
+	UpdatedStages   int `json:"updated_stages"`
+	Skipped         int `json:"skipped"`
+	Errors          int `json:"errors"`
+}
+
+// ICSImportItem is the outcome for one event. Action is create, update, skip or
+// error; Reason explains skip and error.
+type ICSImportItem struct {
+	UID         string             `json:"uid"`
+	Summary     string             `json:"summary"`
+	StartsAt    *string            `json:"starts_at,omitempty"`
+	EndsAt      *string            `json:"ends_at,omitempty"`
+	Action      string             `json:"action"`
+	Reason      string             `json:"reason,omitempty"`
+	Company     *ImportedCompany   `json:"company,omitempty"`
+	Application *ImportedReference `json:"application,omitempty"`
+	Stage       *ImportedReference `json:"stage,omitempty"`
+}
+
+// ImportedCompany and ImportedReference identify what an event or CSV row
+// resolved to. The IDs of new items in a preview are provisional.
+type ImportedCompany struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	IsNew bool   `json:"is_new"`
+}
+
+type ImportedReference struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`     // stage name
+	Category string `json:"category,omitempty"` // application category
+	IsNew    bool   `json:"is_new"`
+}
+
+const (
+	importActionCreate = "create"
+	importActionUpdate = "update"
+	importActionSkip   = "skip"
+	importActionError  = "error"
+)
+
+// importState is the state of one import: companies and applications are shared
+// by the events or rows that resolve to them, so a file never creates the same one twice
+type importState struct {
+	userID       uuid.UUID
+	companies    map[string]*entity.Company // by CompanyMatcher key
+	newCompanies map[uuid.UUID]bool
+	searched     map[string]bool                // search terms already looked up
+	known        []*entity.Company              // existing companies found so far
+	apps         map[string]*entity.Application // by company ID + category
+	newApps      map[uuid.UUID]bool
+	changedApps  []*entity.Application // in first-touched order
+}
+
+// ImportICS matches the events of an .ics file to companies, applications and
+// selection stages. Events already imported (same UID, or the same stage name
+// and time) are skipped, and a moved event updates its stage, so importing a
+// file again is harmless.
+func (uc *ApplicationUsecase) ImportICS(ctx context.Context, userID uuid.UUID, input ImportICSInput) (*ICSImportResponse, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	var category value.ApplicationCategory
+	if input.Category != "" {
+		category = value.ApplicationCategory(strings.ToLower(input.Category))
+		if err := category.Validate(); err != nil {
+			return nil, errors.New("invalid category")
+		}
+	}
+
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := ical.Decode(input.File, preferences.Location())
+	if errors.Is(err, ical.ErrNotCalendar) {
+		return nil, errors.New("invalid ics file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(calendar.Events) > maxImportEvents {
+		return nil, errors.New("invalid ics file: too many events")
+	}
+
+	excluded := make(map[string]bool, len(input.Exclude))
+	for _, uid := range input.Exclude {
+		excluded[uid] = true
+	}
+
+	state := newImportState(userID)
+	response := &ICSImportResponse{Items: make([]*ICSImportItem, 0, len(calendar.Events))}
+	seen := make(map[string]bool)
+
+	for i := range calendar.Events {
+		event := &calendar.Events[i]
+		item := &ICSImportItem{UID: event.UID, Summary: event.Summary}
+		response.Items = append(response.Items, item)
+
+		switch {
+		case event.Start.IsZero():
+			item.Action, item.Reason = importActionError, "missing or invalid start time"
+		case event.UID != "" && seen[event.UID]:
+			// Recurrence overrides share the UID of the first instance
+			item.Action, item.Reason = importActionSkip, "duplicate event in file"
+		case excluded[event.UID]:
+			item.Action, item.Reason = importActionSkip, "excluded"
+		case event.Status == ical.StatusCancelled:
+			item.Action, item.Reason = importActionSkip, "cancelled"
+		default:
+			if err := uc.importEvent(ctx, state, event, category, item); err != nil {
+				return nil, err
+			}
+		}
+		seen[event.UID] = true
+
+		switch item.Action {
+		case importActionCreate:
+			response.Summary.NewStages++
+		case importActionUpdate:
+			response.Summary.UpdatedStages++
+		case importActionSkip:
+			response.Summary.Skipped++
+		case importActionError:
+			response.Summary.Errors++
+		}
+	}
+
+	for _, app := range state.changedApps {
+		if state.newApps[app.ID] {
+			response.Summary.NewApplications++
+		}
+	}
+	for id := range state.newCompanies {
+		if state.used(id) {
+			response.Summary.NewCompanies++
+		}
+	}
+
+	if !input.Confirm {
+		return response, nil
+	}
+
+	// Everything is saved in one transaction, so a failure leaves neither
+	// orphan companies nor half an import behind
+	imp := &repository.ApplicationImport{}
+	for _, company := range state.companies {
+		if state.newCompanies[company.ID] && state.used(company.ID) {
+			imp.Companies = append(imp.Companies, company)
+		}
+	}
+	for _, app := range state.changedApps {
+		if state.newApps[app.ID] {
+			uc.deriveStatus(app) // the initial status is recorded on create
+			imp.Created = append(imp.Created, app)
+			continue
+		}
+		imp.Updated = append(imp.Updated, &repository.ApplicationUpdate{Application: app, Changes: uc.deriveStatus(app)})
+	}
+	if err := uc.appRepo.SaveImport(ctx, imp); err != nil {
+		return nil, err
+	}
+
+	response.Confirmed = true
+	return response, nil
+}
+
+// importEvent resolves the company, application and stage of one event and
+// applies the change to the in-memory application
+func (uc *ApplicationUsecase) importEvent(ctx context.Context, state *importState, event *ical.Event, category value.ApplicationCategory, item *ICSImportItem) error {
+	start := event.Start.UTC()
+	startsAt := start.Format(time.RFC3339)
+	item.StartsAt = &startsAt
+
+	var duration *time.Duration
+	if d := event.End.Sub(event.Start); !event.AllDay && d >= time.Minute && d <= domain_service.MaxEventDuration {
+		d = d.Truncate(time.Minute)
+		duration = &d
+		endsAt := start.Add(d).Format(time.RFC3339)
+		item.EndsAt = &endsAt
+	}
+
+	app, company, err := uc.importedApplication(ctx, state, event.UID)
+	if err != nil {
+		return err
+	}
+	if app == nil {
+		company, err = uc.importCompany(ctx, state, event)
+		if err != nil {
+			return err
+		}
+		if company == nil {
+			item.Action, item.Reason = importActionError, "company name not found in event"
+			return nil
+		}
+
+		if category == "" {
+			category = guessCategory(event.Summary)
+		}
+		app, err = uc.importApplication(ctx, state, company, category)
+		if err != nil {
+			return err
+		}
+	}
+	item.Company = &ImportedCompany{ID: company.ID.String(), Name: company.Name, IsNew: state.newCompanies[company.ID]}
+	item.Application = &ImportedReference{ID: app.ID.String(), Category: string(app.Category), IsNew: state.newApps[app.ID]}
+
+	name := importStageName(event.Summary)
+	var stage *entity.SelectionStage
+	for i := range app.Stages {
+		s := &app.Stages[i]
+		sameUID := event.UID != "" && s.ExternalUID != nil && *s.ExternalUID == event.UID
+		sameSlot := s.Name == name && s.ScheduledAt != nil && s.ScheduledAt.Equal(start)
+		if sameUID || sameSlot {
+			stage = s
+			break
+		}
+	}
+
+	switch {
+	case stage != nil && stage.ScheduledAt != nil && stage.ScheduledAt.Equal(start) && sameDuration(stage.Duration, duration):
+		item.Action, item.Reason = importActionSkip, "already imported"
+	case stage != nil:
+		// The invitation was moved: follow it
+		stage.Reschedule(&start)
+		stage.SetDuration(duration, stage.TravelBuffer)
+		item.Action = importActionUpdate
+		state.touch(app)
+	default:
+		created, err := app.AddStage(name, &start, value.SelectionStagePending, importNotes(event))
+		if err != nil {
+			item.Action, item.Reason = importActionError, err.Error()
+			return nil
+		}
+		created.SetDuration(duration, 0)
+		if event.UID != "" && len(event.UID) <= maxExternalUIDLength {
+			uid := event.UID
+			created.ExternalUID = &uid
+		}
+		stage = created
+		item.Action = importActionCreate
+		state.touch(app)
+	}
+	item.Stage = &ImportedReference{ID: stage.ID.String(), Name: stage.Name, IsNew: item.Action == importActionCreate}
+	return nil
+}
+
+// importedApplication finds the user's application that already holds a stage
+// imported from uid, whatever company or category it was filed under, so that
+// importing an event again never adds a second stage for it. nil if none.
+func (uc *ApplicationUsecase) importedApplication(ctx context.Context, state *importState, uid string) (*entity.Application, *entity.Company, error) {
+	if uid == "" || len(uid) > maxExternalUIDLength {
+		return nil, nil, nil
+	}
+
+	found, err := uc.appRepo.FindByStageExternalUID(ctx, state.userID, uid)
+	if err != nil || found == nil {
+		return nil, nil, err
+	}
+
+	// Keep using the copy this import already changed, if any
+	key := found.Company.ID.String() + "/" + string(found.Application.Category)
+	if app, ok := state.apps[key]; ok {
+		return app, found.Company, nil
+	}
+	state.apps[key] = found.Application
+	return found.Application, found.Company, nil
+}
+
+// importCompany finds the company an event is for among the existing ones, or
+// proposes a new company named after the most specific candidate. The
+// organizer is only matched against existing companies: anyone can send an
+// invitation under any name, so it never names a new company.
+func (uc *ApplicationUsecase) importCompany(ctx context.Context, state *importState, event *ical.Event) (*entity.Company, error) {
+	candidates := companyCandidates(event)
+	lookups := candidates
+	if organizer := organizerName(event); organizer != "" {
+		lookups = append(append([]string{}, candidates...), organizer)
+	}
+	for _, candidate := range lookups {
+		company, err := uc.findCompany(ctx, state, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if company != nil {
+			return company, nil
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	return state.addCompany(uc.companies, entity.NewCompany(candidates[0], nil, nil, nil)), nil
+}
+
+// findCompany looks a name up among the companies of this import and the
+// existing ones, ignoring legal forms and spelling variants. nil if none matches.
+func (uc *ApplicationUsecase) findCompany(ctx context.Context, state *importState, name string) (*entity.Company, error) {
+	key := uc.companies.Key(name)
+	if company, ok := state.companies[key]; ok {
+		return company, nil
+	}
+
+	term := uc.companies.Name(name)
+	if !state.searched[term] {
+		state.searched[term] = true
+		results, err := uc.companyRepo.FindWithUserApplication(ctx, repository.CompanyQuery{
+			UserID: state.userID,
+			Search: term,
+			Limit:  20,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			state.known = append(state.known, result.Company)
+		}
+	}
+	if company := uc.companies.Match(name, state.known); company != nil {
+		state.companies[key] = company
+		return company, nil
+	}
+	return nil, nil
+}
+
+func (uc *ApplicationUsecase) importApplication(ctx context.Context, state *importState, company *entity.Company, category value.ApplicationCategory) (*entity.Application, error) {
+	key := company.ID.String() + "/" + string(category)
+	if app, ok := state.apps[key]; ok {
+		return app, nil
+	}
+
+	var app *entity.Application
+	if !state.newCompanies[company.ID] {
+		existing, err := uc.appRepo.FindByUserAndCompany(ctx, state.userID, company.ID, category)
+		if err != nil {
+			return nil, err
+		}
+		app = existing
+	}
+	if app == nil {
+		app = entity.NewApplication(state.userID, company.ID, category, value.ApplicationStatusToDo, nil, value.ColorTagOrange)
+		app.StatusFromStages = true
+		state.newApps[app.ID] = true
+	}
+	state.apps[key] = app
+	return app, nil
+}
+
+func newImportState(userID uuid.UUID) *importState {
+	return &importState{
+		userID:       userID,
+		companies:    make(map[string]*entity.Company),
+		newCompanies: make(map[uuid.UUID]bool),
+		searched:     make(map[string]bool),
+		apps:         make(map[string]*entity.Application),
+		newApps:      make(map[uuid.UUID]bool),
+	}
+}
+
+// addCompany proposes a new company; it is only saved if an application uses it
+func (s *importState) addCompany(matcher domain_service.CompanyMatcher, company *entity.Company) *entity.Company {
+	s.companies[matcher.Key(company.Name)] = company
+	s.newCompanies[company.ID] = true
+	return company
+}
+
+func (s *importState) touch(app *entity.Application) {
+	for _, changed := range s.changedApps {
+		if changed == app {
+			return
+		}
+	}
+	app.UpdatedAt = time.Now()
+	s.changedApps = append(s.changedApps, app)
+}
+
+// used reports whether a proposed company ended up with a saved application
+func (s *importState) used(companyID uuid.UUID) bool {
+	for _, app := range s.changedApps {
+		if app.CompanyID == companyID {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// bracketedName matches 【ABC株式会社】, 「ABC」 and [ABC] in a summary
+	bracketedName = regexp.MustCompile(`【([^】]+)】|「([^」]+)」|\[([^\]]+)\]`)
+	// legalFormName matches a company name written with its legal form
+	legalFormName = regexp.MustCompile(`(?:株式会社|有限会社|合同会社|\(株\))\s*[^\s/|:・,、]+|[^\s/|:・,、【「\[]+\s*(?:株式会社|有限会社|合同会社|\(株\))|[A-Za-z0-9&.\- ]+\s*(?:Inc\.?|Co\.,? ?Ltd\.?|Ltd\.?|Corp(?:oration)?\.?|LLC|K\.K\.)`)
+	// organizerNoise is the department part of an organizer name such as "ABC株式会社 人事部"
+	organizerNoise = regexp.MustCompile(`(?i)\s*(?:人事部|人事|採用担当|採用チーム|採用|新卒採用|recruiting|recruitment|talent acquisition|hr)\s*(?:担当|チーム|team)?$`)
+)
+
+// companyCandidates returns the possible company names found in the summary and
+// description of an event, most specific first. The text is NFKC normalised so
+// full-width names match too.
+func companyCandidates(event *ical.Event) []string {
+	summary := norm.NFKC.String(event.Summary)
+	var candidates []string
+	add := func(s string) {
+		s = strings.TrimSpace(s)
+		if s == "" || utf8.RuneCountInString(s) > 100 {
+			return
+		}
+		for _, c := range candidates {
+			if c == s {
+				return
+			}
+		}
+		candidates = append(candidates, s)
+	}
+
+	for _, m := range bracketedName.FindAllStringSubmatch(summary, -1) {
+		for _, group := range m[1:] {
+			if group != "" && importStageKeyword(group) == "" {
+				add(group)
+			}
+		}
+	}
+	for _, m := range legalFormName.FindAllString(summary, -1) {
+		add(trimStageSuffix(m))
+	}
+	for _, m := range legalFormName.FindAllString(norm.NFKC.String(event.Description), 1) {
+		add(trimStageSuffix(m))
+	}
+	return candidates
+}
+
+// organizerName is the organizer of an event without its department, e.g.
+// "ABC株式会社" for "ABC株式会社 人事部"
+func organizerName(event *ical.Event) string {
+	name := strings.TrimSpace(organizerNoise.ReplaceAllString(norm.NFKC.String(event.Organizer), ""))
+	if utf8.RuneCountInString(name) > 100 {
+		return ""
+	}
+	return name
+}
+
+// trimStageSuffix cuts "株式会社ABC一次面接のご案内" down to "株式会社ABC"
+func trimStageSuffix(s string) string {
+	lower := strings.ToLower(s)
+	end := len(s)
+	for _, k := range importStageKeywords {
+		if i := strings.Index(lower, k.keyword); i > 0 && i < end {
+			end = i
+		}
+	}
+	return strings.TrimRight(s[:end], "の 　")
+}
+
+// importStageKeywords are the stage names recognised in a summary, most specific first
+var importStageKeywords = []struct{ keyword, name string }{
+	{"最終面接", "最終面接"}, {"役員面接", "役員面接"},
+	{"四次面接", "四次面接"}, {"三次面接", "三次面接"}, {"二次面接", "二次面接"}, {"一次面接", "一次面接"},
+	{"グループディスカッション", "グループディスカッション"}, {"グループ面接", "グループ面接"},
+	{"webテスト", "Webテスト"}, {"適性検査", "適性検査"}, {"筆記試験", "筆記試験"},
+	{"エントリーシート", "ES"}, {"面接", "面接"}, {"面談", "面談"},
+	{"説明会", "説明会"}, {"セミナー", "セミナー"}, {"インターン", "インターン"},
+	{"final interview", "Final interview"}, {"interview", "Interview"},
+	{"info session", "Info session"}, {"internship", "Internship"},
+}
+
+func importStageKeyword(s string) string {
+	lower := strings.ToLower(s)
+	for _, k := range importStageKeywords {
+		if strings.Contains(lower, k.keyword) {
+			return k.name
+		}
+	}
+	return ""
+}
+
+// importStageName names the stage after a known keyword, or the summary itself
+func importStageName(summary string) string {
+	if name := importStageKeyword(summary); name != "" {
+		return name
+	}
+	name := strings.TrimSpace(summary)
+	if name == "" {
+		return "予定"
+	}
+	if utf8.RuneCountInString(name) > maxStageNameLength {
+		name = string([]rune(name)[:maxStageNameLength])
+	}
+	return name
+}
+
+func guessCategory(summary string) value.ApplicationCategory {
+	lower := strings.ToLower(summary)
+	switch {
+	case strings.Contains(lower, "インターン"), strings.Contains(lower, "intern"):
+		return value.ApplicationCategoryIntern
+	case strings.Contains(lower, "説明会"), strings.Contains(lower, "セミナー"),
+		strings.Contains(lower, "info session"), strings.Contains(lower, "seminar"):
+		return value.ApplicationCategoryInfo
+	default:
+		return value.ApplicationCategoryMain
+	}
+}
+
+func importNotes(event *ical.Event) *string {
+	parts := make([]string, 0, 3)
+	for _, s := range []string{event.Location, event.URL, event.Description} {
+		if s = strings.TrimSpace(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	notes := strings.Join(parts, "\n")
+	if utf8.RuneCountInString(notes) > maxImportNotes {
+		notes = string([]rune(notes)[:maxImportNotes])
+	}
+	return optionalNotes(&notes)
+}
+
+func sameDuration(a, b *time.Duration) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,242 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"noroi/internal/domain/domain_service"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+	apperrors "noroi/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// ScheduleLengthInput holds the optional length of a scheduled application or
+// stage. DurationMinutes and EndsAt are two ways to set the same value; 0 or ""
+// clears it.
+type ScheduleLengthInput struct {
+	DurationMinutes     *int
+	EndsAt              *string // RFC3339, after scheduled_at
+	TravelBufferMinutes *int
+}
+
+// ScheduleConflictsInput holds the raw query parameters of GET /schedule/conflicts
+type ScheduleConflictsInput struct {
+	From string // YYYY-MM-DD or RFC3339; defaults to now
+	To   string // YYYY-MM-DD (whole day included) or RFC3339; optional
+}
+
+type ScheduleEventResponse struct {
+	Type                string  `json:"type"` // application | stage
+	ApplicationID       string  `json:"application_id"`
+	StageID             *string `json:"stage_id,omitempty"`
+	CompanyName         string  `json:"company_name"`
+	StageName           string  `json:"stage_name,omitempty"`
+	StartsAt            string  `json:"starts_at"`
+	EndsAt              string  `json:"ends_at"`
+	TravelBufferMinutes int     `json:"travel_buffer_minutes"`
+}
+
+type ScheduleConflictResponse struct {
+	Kind   string                   `json:"kind"` // overlap | travel_buffer
+	Events []*ScheduleEventResponse `json:"events"`
+}
+
+// scheduleQueryLimit bounds the applications loaded to look for conflicts
+const scheduleQueryLimit = 500
+
+// scheduleSearchMargin widens a schedule query so that events starting earlier
+// but still running (plus their travel time) are included
+const scheduleSearchMargin = domain_service.MaxEventDuration + domain_service.MaxTravelBuffer
+
+// ListConflicts returns the overlapping events of the user's open applications
+// that have not ended before from. truncated reports that the range held more
+// than scheduleQueryLimit applications, so conflicts among the rest are missing.
+func (uc *ApplicationUsecase) ListConflicts(ctx context.Context, userID uuid.UUID, input ScheduleConflictsInput) (conflicts []*ScheduleConflictResponse, truncated bool, err error) {
+	if userID == uuid.Nil {
+		return nil, false, errors.New("user id is required")
+	}
+
+	from := time.Now()
+	var until *time.Time
+	if input.From != "" || input.To != "" {
+		preferences, err := uc.preferenceRepo.FindByUserID(ctx, userID)
+		if err != nil {
+			return nil, false, err
+		}
+		loc := preferences.Location()
+
+		if input.From != "" {
+			if from, err = parseRangeBound(input.From, loc, false); err != nil {
+				return nil, false, apperrors.ErrInvalidFrom
+			}
+		}
+		if input.To != "" {
+			to, err := parseRangeBound(input.To, loc, true)
+			if err != nil {
+				return nil, false, apperrors.ErrInvalidTo
+			}
+			if !from.Before(to) {
+				return nil, false, apperrors.ErrInvalidDateRange
+			}
+			until = &to
+		}
+	}
+
+	results, truncated, err := uc.appRepo.FindScheduled(ctx, userID, from.Add(-scheduleSearchMargin), until, scheduleQueryLimit)
+	if err != nil {
+		return nil, false, err
+	}
+
+	events, companies := uc.scheduleEvents(results)
+	current := events[:0]
+	for _, event := range events {
+		if event.End.After(from) {
+			current = append(current, event)
+		}
+	}
+
+	return toConflictResponses(uc.scheduler.Conflicts(current), companies), truncated, nil
+}
+
+// scheduleWarnings reports the saved application's conflicts with the user's
+// other plans. Warnings never block a change, so a failed lookup is only logged.
+func (uc *ApplicationUsecase) scheduleWarnings(ctx context.Context, app *entity.Application) []*ScheduleConflictResponse {
+	own := uc.scheduler.Events(app)
+	if len(own) == 0 {
+		return nil
+	}
+
+	from, until := own[0].Start, own[0].End
+	for _, event := range own[1:] {
+		if event.Start.Before(from) {
+			from = event.Start
+		}
+		if event.End.After(until) {
+			until = event.End
+		}
+	}
+	from = from.Add(-scheduleSearchMargin)
+	until = until.Add(domain_service.MaxTravelBuffer)
+
+	results, _, err := uc.appRepo.FindScheduled(ctx, app.UserID, from, &until, scheduleQueryLimit)
+	if err != nil {
+		log.Printf("schedule: failed to check conflicts for application %s: %v", app.ID, err)
+		return nil
+	}
+
+	events, companies := uc.scheduleEvents(results)
+	return toConflictResponses(uc.scheduler.ConflictsOf(app.ID, events), companies)
+}
+
+func (uc *ApplicationUsecase) scheduleEvents(results []*repository.ApplicationWithCompany) ([]domain_service.ScheduleEvent, map[uuid.UUID]string) {
+	var events []domain_service.ScheduleEvent
+	companies := make(map[uuid.UUID]string, len(results))
+	for _, item := range results {
+		events = append(events, uc.scheduler.Events(item.Application)...)
+		companies[item.Application.ID] = item.Company.Name
+	}
+	return events, companies
+}
+
+// resolve applies the input to the current duration and travel buffer of an
+// event starting at start
+func (in ScheduleLengthInput) resolve(start *time.Time, duration *time.Duration, buffer time.Duration) (*time.Duration, time.Duration, error) {
+	if in.DurationMinutes != nil && in.EndsAt != nil {
+		return nil, 0, errors.New("invalid duration: set either duration_minutes or ends_at")
+	}
+
+	if in.DurationMinutes != nil {
+		minutes := *in.DurationMinutes
+		switch {
+		case minutes == 0:
+			duration = nil
+		case minutes < 0 || time.Duration(minutes)*time.Minute > domain_service.MaxEventDuration:
+			return nil, 0, errors.New("invalid duration_minutes")
+		default:
+			d := time.Duration(minutes) * time.Minute
+			duration = &d
+		}
+	}
+
+	if in.EndsAt != nil {
+		if *in.EndsAt == "" {
+			duration = nil
+		} else {
+			end, err := time.Parse(time.RFC3339, *in.EndsAt)
+			if err != nil {
+				return nil, 0, errors.New("invalid ends_at format")
+			}
+			if start == nil {
+				return nil, 0, errors.New("invalid ends_at: scheduled_at is required")
+			}
+			d := end.Sub(*start).Truncate(time.Minute)
+			if d < time.Minute || d > domain_service.MaxEventDuration {
+				return nil, 0, errors.New("invalid ends_at: must be after scheduled_at and within 24 hours")
+			}
+			duration = &d
+		}
+	}
+
+	if in.TravelBufferMinutes != nil {
+		minutes := *in.TravelBufferMinutes
+		if minutes < 0 || time.Duration(minutes)*time.Minute > domain_service.MaxTravelBuffer {
+			return nil, 0, errors.New("invalid travel_buffer_minutes")
+		}
+		buffer = time.Duration(minutes) * time.Minute
+	}
+
+	return duration, buffer, nil
+}
+
+func (in ScheduleLengthInput) isSet() bool {
+	return in.DurationMinutes != nil || in.EndsAt != nil || in.TravelBufferMinutes != nil
+}
+
+// scheduleLengthFields formats the duration of an event for a response
+func scheduleLengthFields(start *time.Time, duration *time.Duration) (minutes *int, endsAt *string) {
+	if duration == nil {
+		return nil, nil
+	}
+	m := int(*duration / time.Minute)
+	minutes = &m
+	if start != nil {
+		end := start.Add(*duration).UTC().Format(time.RFC3339)
+		endsAt = &end
+	}
+	return minutes, endsAt
+}
+
+func toConflictResponses(conflicts []domain_service.ScheduleConflict, companies map[uuid.UUID]string) []*ScheduleConflictResponse {
+	responses := make([]*ScheduleConflictResponse, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		responses = append(responses, &ScheduleConflictResponse{
+			Kind: string(conflict.Kind),
+			Events: []*ScheduleEventResponse{
+				toScheduleEventResponse(conflict.A, companies),
+				toScheduleEventResponse(conflict.B, companies),
+			},
+		})
+	}
+	return responses
+}
+
+func toScheduleEventResponse(event domain_service.ScheduleEvent, companies map[uuid.UUID]string) *ScheduleEventResponse {
+	response := &ScheduleEventResponse{
+		Type:                string(event.Kind),
+		ApplicationID:       event.ApplicationID.String(),
+		CompanyName:         companies[event.ApplicationID],
+		StageName:           event.Name,
+		StartsAt:            event.Start.UTC().Format(time.RFC3339),
+		EndsAt:              event.End.UTC().Format(time.RFC3339),
+		TravelBufferMinutes: int(event.Buffer / time.Minute),
+	}
+	if event.StageID != nil {
+		stageID := event.StageID.String()
+		response.StageID = &stageID
+	}
+	return response
+}
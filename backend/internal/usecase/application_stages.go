@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+
+	"github.com/google/uuid"
+)
+
+// maxStageNameLength is the longest stage name accepted, in characters
+const maxStageNameLength = 100
+
+type CreateStageInput struct {
+	Name        string
+	ScheduledAt *string
+	Status      string
+	Notes       *string
+	ScheduleLengthInput
+}
+
+// UpdateStageInput changes only the fields that are set. An empty ScheduledAt
+// or Notes clears it.
+type UpdateStageInput struct {
+	Name        *string
+	ScheduledAt *string
+	Status      *string
+	Notes       *string
+	ScheduleLengthInput
+}
+
+type SelectionStageResponse struct {
+	ID                  string  `json:"id"`
+	Name                string  `json:"name"`
+	ScheduledAt         *string `json:"scheduled_at,omitempty"`
+	DurationMinutes     *int    `json:"duration_minutes,omitempty"`
+	EndsAt              *string `json:"ends_at,omitempty"`
+	TravelBufferMinutes int     `json:"travel_buffer_minutes"`
+	Status              string  `json:"status"`
+	Notes               *string `json:"notes,omitempty"`
+	Position            int     `json:"position"`
+	CreatedAt           string  `json:"created_at"`
+	UpdatedAt           string  `json:"updated_at"`
+}
+
+// ApplicationStagesResponse is returned by every stage change, since adding,
+// removing or reordering one stage can move the others and the application status
+type ApplicationStagesResponse struct {
+	ApplicationID    string                    `json:"application_id"`
+	Status           string                    `json:"status"`
+	StatusFromStages bool                      `json:"status_from_stages"`
+	Stages           []*SelectionStageResponse `json:"stages"`
+
+	// Warnings lists schedule conflicts with the user's other applications;
+	// only set when a stage is added or updated
+	Warnings []*ScheduleConflictResponse `json:"warnings,omitempty"`
+}
+
+// ListStages returns the application's stages in display order
+func (uc *ApplicationUsecase) ListStages(ctx context.Context, userID, appID uuid.UUID) (*ApplicationStagesResponse, error) {
+	app, err := uc.findOwned(ctx, userID, appID)
+	if err != nil {
+		return nil, err
+	}
+	return toStagesResponse(app), nil
+}
+
+func (uc *ApplicationUsecase) AddStage(ctx context.Context, userID, appID uuid.UUID, input CreateStageInput) (*ApplicationStagesResponse, error) {
+	app, err := uc.findOwned(ctx, userID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := parseStageName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	status := value.SelectionStagePending
+	if input.Status != "" {
+		status = value.SelectionStageStatus(strings.ToLower(input.Status))
+		if err := status.Validate(); err != nil {
+			return nil, errors.New("invalid stage status")
+		}
+	}
+
+	var scheduledAt *time.Time
+	if input.ScheduledAt != nil && *input.ScheduledAt != "" {
+		t, err := time.Parse(time.RFC3339, *input.ScheduledAt)
+		if err != nil {
+			return nil, errors.New("invalid scheduled_at format")
+		}
+		t = t.UTC()
+		scheduledAt = &t
+	}
+
+	duration, buffer, err := input.ScheduleLengthInput.resolve(scheduledAt, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	stage, err := app.AddStage(name, scheduledAt, status, optionalNotes(input.Notes))
+	if err != nil {
+		return nil, err
+	}
+	stage.SetDuration(duration, buffer)
+
+	if err := uc.saveStages(ctx, app); err != nil {
+		return nil, err
+	}
+	response := toStagesResponse(app)
+	response.Warnings = uc.scheduleWarnings(ctx, app)
+	return response, nil
+}
+
+func (uc *ApplicationUsecase) UpdateStage(ctx context.Context, userID, appID, stageID uuid.UUID, input UpdateStageInput) (*ApplicationStagesResponse, error) {
+	app, err := uc.findOwned(ctx, userID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	stage := app.Stage(stageID)
+	if stage == nil {
+		return nil, errors.New("stage not found")
+	}
+
+	if input.Name != nil {
+		name, err := parseStageName(*input.Name)
+		if err != nil {
+			return nil, err
+		}
+		stage.Rename(name)
+	}
+
+	if input.Status != nil {
+		status := value.SelectionStageStatus(strings.ToLower(*input.Status))
+		if err := status.Validate(); err != nil {
+			return nil, errors.New("invalid stage status")
+		}
+		stage.UpdateStatus(status)
+	}
+
+	if input.ScheduledAt != nil {
+		if *input.ScheduledAt == "" {
+			stage.Reschedule(nil)
+		} else {
+			t, err := time.Parse(time.RFC3339, *input.ScheduledAt)
+			if err != nil {
+				return nil, errors.New("invalid scheduled_at format")
+			}
+			t = t.UTC()
+			stage.Reschedule(&t)
+		}
+	}
+
+	if input.ScheduleLengthInput.isSet() {
+		duration, buffer, err := input.ScheduleLengthInput.resolve(stage.ScheduledAt, stage.Duration, stage.TravelBuffer)
+		if err != nil {
+			return nil, err
+		}
+		stage.SetDuration(duration, buffer)
+	}
+
+	if input.Notes != nil {
+		stage.UpdateNotes(optionalNotes(input.Notes))
+	}
+
+	app.UpdatedAt = time.Now()
+	if err := uc.saveStages(ctx, app); err != nil {
+		return nil, err
+	}
+	response := toStagesResponse(app)
+	response.Warnings = uc.scheduleWarnings(ctx, app)
+	return response, nil
+}
+
+func (uc *ApplicationUsecase) DeleteStage(ctx context.Context, userID, appID, stageID uuid.UUID) (*ApplicationStagesResponse, error) {
+	app, err := uc.findOwned(ctx, userID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !app.RemoveStage(stageID) {
+		return nil, errors.New("stage not found")
+	}
+
+	if err := uc.saveStages(ctx, app); err != nil {
+		return nil, err
+	}
+	return toStagesResponse(app), nil
+}
+
+// ReorderStages puts the stages in the order of stageIDs, which must list every stage once
+func (uc *ApplicationUsecase) ReorderStages(ctx context.Context, userID, appID uuid.UUID, stageIDs []string) (*ApplicationStagesResponse, error) {
+	app, err := uc.findOwned(ctx, userID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(stageIDs))
+	for _, s := range stageIDs {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, errors.New("invalid stage id")
+		}
+		ids = append(ids, id)
+	}
+
+	if err := app.ReorderStages(ids); err != nil {
+		return nil, err
+	}
+
+	if err := uc.saveStages(ctx, app); err != nil {
+		return nil, err
+	}
+	return toStagesResponse(app), nil
+}
+
+// findOwned loads one of the user's applications, stages included
+func (uc *ApplicationUsecase) findOwned(ctx context.Context, userID, appID uuid.UUID) (*entity.Application, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	app, err := uc.appRepo.FindByID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, errors.New("application not found")
+	}
+	if app.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+	return app, nil
+}
+
+// saveStages stores the application together with its stages, moving the
+// status along first when it follows the stages
+func (uc *ApplicationUsecase) saveStages(ctx context.Context, app *entity.Application) error {
+	return uc.appRepo.Update(ctx, app, uc.deriveStatus(app)...)
+}
+
+// deriveStatus moves an application with StatusFromStages towards the status its
+// stages imply, one allowed transition at a time. A status the policy cannot
+// reach from the current one (e.g. going back from done) is left as is.
+func (uc *ApplicationUsecase) deriveStatus(app *entity.Application) []*entity.ApplicationStatusChange {
+	if !app.StatusFromStages {
+		return nil
+	}
+	target, ok := uc.progress.StatusFromStages(app.Stages)
+	if !ok {
+		return nil
+	}
+	path, ok := uc.progress.TransitionPath(app.Status, target)
+	if !ok {
+		return nil
+	}
+
+	changes := make([]*entity.ApplicationStatusChange, 0, len(path))
+	for _, status := range path {
+		if change := app.UpdateStatus(status); change != nil {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func parseStageName(s string) (string, error) {
+	name := strings.TrimSpace(s)
+	if name == "" || utf8.RuneCountInString(name) > maxStageNameLength {
+		return "", errors.New("invalid stage name")
+	}
+	return name, nil
+}
+
+func optionalNotes(notes *string) *string {
+	if notes == nil || strings.TrimSpace(*notes) == "" {
+		return nil
+	}
+	n := *notes
+	return &n
+}
+
+func toStagesResponse(app *entity.Application) *ApplicationStagesResponse {
+	return &ApplicationStagesResponse{
+		ApplicationID:    app.ID.String(),
+		Status:           string(app.Status),
+		StatusFromStages: app.StatusFromStages,
+		Stages:           toStageResponses(app.Stages),
+	}
+}
+
+func toStageResponses(stages []entity.SelectionStage) []*SelectionStageResponse {
+	responses := make([]*SelectionStageResponse, 0, len(stages))
+	for _, stage := range stages {
+		response := &SelectionStageResponse{
+			ID:                  stage.ID.String(),
+			Name:                stage.Name,
+			TravelBufferMinutes: int(stage.TravelBuffer / time.Minute),
+			Status:              string(stage.Status),
+			Notes:               stage.Notes,
+			Position:            stage.Position,
+			CreatedAt:           stage.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:           stage.UpdatedAt.Format(time.RFC3339),
+		}
+		if stage.ScheduledAt != nil {
+			s := stage.ScheduledAt.UTC().Format(time.RFC3339)
+			response.ScheduledAt = &s
+		}
+		response.DurationMinutes, response.EndsAt = scheduleLengthFields(stage.ScheduledAt, stage.Duration)
+		responses = append(responses, response)
+	}
+	return responses
+}
@@ -3,66 +3,244 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	"noroi/internal/domain/domain_service"
 	"noroi/internal/domain/entity"
 	"noroi/internal/domain/value"
 	"noroi/internal/repository"
+	apperrors "noroi/pkg/errors"
 
 	"github.com/google/uuid"
 )
 
 type ApplicationUsecase struct {
-	appRepo     repository.ApplicationRepository
-	companyRepo repository.CompanyRepository
+	appRepo        repository.ApplicationRepository
+	companyRepo    repository.CompanyRepository
+	preferenceRepo repository.UserPreferenceRepository
+	progress       domain_service.ProgressPolicy
+	scheduler      domain_service.ApplicationScheduler
+	companies      domain_service.CompanyMatcher
 }
 
-func NewApplicationUsecase(appRepo repository.ApplicationRepository, companyRepo repository.CompanyRepository) *ApplicationUsecase {
+func NewApplicationUsecase(appRepo repository.ApplicationRepository, companyRepo repository.CompanyRepository, preferenceRepo repository.UserPreferenceRepository) *ApplicationUsecase {
 	return &ApplicationUsecase{
-		appRepo:     appRepo,
-		companyRepo: companyRepo,
+		appRepo:        appRepo,
+		companyRepo:    companyRepo,
+		preferenceRepo: preferenceRepo,
 	}
 }
 
 type CreateApplicationInput struct {
-	CompanyID   string
-	Category    string
-	Status      string
-	ColorTag    string
-	ScheduledAt *string
-	Motivation  string
-	WhatToDo    string
-	JobAxis     string
-	Strengths   string
+	CompanyID        string
+	Category         string
+	Status           string
+	ColorTag         string
+	ScheduledAt      *string
+	Motivation       string
+	WhatToDo         string
+	JobAxis          string
+	Strengths        string
+	StatusFromStages bool
+	ScheduleLengthInput
 }
 
 type UpdateApplicationInput struct {
-	Category    string
-	Status      string
-	ColorTag    string
-	ScheduledAt *string
-	Motivation  string
-	WhatToDo    string
-	JobAxis     string
-	Strengths   string
+	Category         string
+	Status           string
+	ColorTag         string
+	ScheduledAt      *string
+	Motivation       string
+	WhatToDo         string
+	JobAxis          string
+	Strengths        string
+	StatusFromStages *bool
+	ScheduleLengthInput
 }
 
 type ApplicationResponse struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"user_id"`
-	CompanyID   string  `json:"company_id"`
-	Category    string  `json:"category"`
-	Status      string  `json:"status"`
-	ScheduledAt *string `json:"scheduled_at,omitempty"`
-	ColorTag    string  `json:"color_tag"`
-	Completed   bool    `json:"completed"`
-	Motivation  string  `json:"motivation"`
-	WhatToDo    string  `json:"what_to_do"`
-	JobAxis     string  `json:"job_axis"`
-	Strengths   string  `json:"strengths"`
-	CreatedAt   string  `json:"created_at"`
-	UpdatedAt   string  `json:"updated_at"`
+	ID                  string  `json:"id"`
+	UserID              string  `json:"user_id"`
+	CompanyID           string  `json:"company_id"`
+	Category            string  `json:"category"`
+	Status              string  `json:"status"`
+	ScheduledAt         *string `json:"scheduled_at,omitempty"`
+	DurationMinutes     *int    `json:"duration_minutes,omitempty"`
+	EndsAt              *string `json:"ends_at,omitempty"`
+	TravelBufferMinutes int     `json:"travel_buffer_minutes"`
+	ColorTag            string  `json:"color_tag"`
+	Completed           bool    `json:"completed"`
+	Motivation          string  `json:"motivation"`
+	WhatToDo            string  `json:"what_to_do"`
+	JobAxis             string  `json:"job_axis"`
+	Strengths           string  `json:"strengths"`
+	StatusFromStages    bool    `json:"status_from_stages"`
+	CreatedAt           string  `json:"created_at"`
+	UpdatedAt           string  `json:"updated_at"`
+
+	// Warnings lists schedule conflicts with the user's other applications;
+	// only set in answers to create and update
+	Warnings []*ScheduleConflictResponse `json:"warnings,omitempty"`
+}
+
+// ListApplicationsInput holds the raw query parameters of GET /applications.
+// Dates are interpreted in the user's time zone.
+type ListApplicationsInput struct {
+	Date     string // "today" or YYYY-MM-DD; cannot be combined with From/To
+	Category string
+	Status   string
+	From     string // YYYY-MM-DD (start of day) or RFC3339, inclusive
+	To       string // YYYY-MM-DD (whole day included) or RFC3339, exclusive
+	Sort     string // "scheduled_at" (default) or "-scheduled_at"
+	Limit    int
+	Offset   int
+}
+
+// ApplicationDetailResponse is one application with its stages and status timeline
+type ApplicationDetailResponse struct {
+	*ApplicationResponse
+	AllowedNextStatuses []string                  `json:"allowed_next_statuses"`
+	Stages              []*SelectionStageResponse `json:"stages"`
+	Timeline            []*StatusChangeResponse   `json:"timeline"`
+}
+
+type StatusChangeResponse struct {
+	ID         string  `json:"id"`
+	FromStatus *string `json:"from_status"` // null for the status the application was created with
+	ToStatus   string  `json:"to_status"`
+	ChangedAt  string  `json:"changed_at"`
+}
+
+type ApplicationListItem struct {
+	*ApplicationResponse
+	Company *CompanyResponse `json:"company"`
+}
+
+const dateLayout = "2006-01-02"
+
+func (uc *ApplicationUsecase) List(ctx context.Context, userID uuid.UUID, input ListApplicationsInput) ([]*ApplicationListItem, int, error) {
+	if userID == uuid.Nil {
+		return nil, 0, errors.New("user id is required")
+	}
+
+	query := repository.ApplicationQuery{
+		UserID: userID,
+		Limit:  input.Limit,
+		Offset: input.Offset,
+	}
+
+	if input.Category != "" {
+		category := value.ApplicationCategory(strings.ToLower(input.Category))
+		if err := category.Validate(); err != nil {
+			return nil, 0, apperrors.ErrInvalidApplicationCategory
+		}
+		query.Category = category
+	}
+	if input.Status != "" {
+		status := value.ApplicationStatus(strings.ToLower(input.Status))
+		if err := status.Validate(); err != nil {
+			return nil, 0, apperrors.ErrInvalidApplicationStatus
+		}
+		query.Status = status
+	}
+
+	switch input.Sort {
+	case "", "scheduled_at":
+	case "-scheduled_at":
+		query.Descending = true
+	default:
+		return nil, 0, apperrors.ErrInvalidSort
+	}
+
+	if input.Date != "" || input.From != "" || input.To != "" {
+		preferences, err := uc.preferenceRepo.FindByUserID(ctx, userID)
+		if err != nil {
+			return nil, 0, err
+		}
+		loc := preferences.Location()
+
+		if input.Date != "" {
+			if input.From != "" || input.To != "" {
+				return nil, 0, fmt.Errorf("%w: cannot be combined with from/to", apperrors.ErrInvalidDate)
+			}
+			start, err := parseDay(input.Date, loc)
+			if err != nil {
+				return nil, 0, err
+			}
+			end := start.AddDate(0, 0, 1)
+			query.ScheduledFrom = &start
+			query.ScheduledUntil = &end
+		}
+		if input.From != "" {
+			from, err := parseRangeBound(input.From, loc, false)
+			if err != nil {
+				return nil, 0, apperrors.ErrInvalidFrom
+			}
+			query.ScheduledFrom = &from
+		}
+		if input.To != "" {
+			to, err := parseRangeBound(input.To, loc, true)
+			if err != nil {
+				return nil, 0, apperrors.ErrInvalidTo
+			}
+			query.ScheduledUntil = &to
+		}
+		if query.ScheduledFrom != nil && query.ScheduledUntil != nil && !query.ScheduledFrom.Before(*query.ScheduledUntil) {
+			return nil, 0, apperrors.ErrInvalidDateRange
+		}
+	}
+
+	results, total, err := uc.appRepo.List(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]*ApplicationListItem, 0, len(results))
+	for _, item := range results {
+		items = append(items, &ApplicationListItem{
+			ApplicationResponse: uc.toResponse(item.Application),
+			Company: &CompanyResponse{
+				ID:             item.Company.ID.String(),
+				Name:           item.Company.Name,
+				RecruitmentURL: item.Company.RecruitmentURL,
+				Industry:       item.Company.Industry,
+				Location:       item.Company.Location,
+			},
+		})
+	}
+
+	return items, total, nil
+}
+
+// parseDay returns the start of "today" or a YYYY-MM-DD day in loc
+func parseDay(s string, loc *time.Location) (time.Time, error) {
+	if strings.ToLower(s) == "today" {
+		now := time.Now().In(loc)
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc), nil
+	}
+	day, err := time.ParseInLocation(dateLayout, s, loc)
+	if err != nil {
+		return time.Time{}, apperrors.ErrInvalidDate
+	}
+	return day, nil
+}
+
+// parseRangeBound parses a from/to value. A bare date used as the upper bound
+// includes that whole day.
+func parseRangeBound(s string, loc *time.Location, upper bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation(dateLayout, s, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if upper {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
 }
 
 func (uc *ApplicationUsecase) Create(ctx context.Context, userID uuid.UUID, input CreateApplicationInput) (*ApplicationResponse, error) {
@@ -116,18 +294,29 @@ func (uc *ApplicationUsecase) Create(ctx context.Context, userID uuid.UUID, inpu
 		if err != nil {
 			return nil, errors.New("invalid scheduled_at format")
 		}
+		t = t.UTC()
 		scheduledAt = &t
 	}
 
 	// Create application
 	app := entity.NewApplication(userID, companyID, category, status, scheduledAt, colorTag)
 	app.UpdateNotes(input.Motivation, input.WhatToDo, input.JobAxis, input.Strengths)
+	app.StatusFromStages = input.StatusFromStages
+
+	// Length of the event and travel time around it
+	duration, buffer, err := input.ScheduleLengthInput.resolve(app.ScheduledAt, app.Duration, app.TravelBuffer)
+	if err != nil {
+		return nil, err
+	}
+	app.SetDuration(duration, buffer)
 
 	if err := uc.appRepo.Create(ctx, app); err != nil {
 		return nil, err
 	}
 
-	return uc.toResponse(app), nil
+	response := uc.toResponse(app)
+	response.Warnings = uc.scheduleWarnings(ctx, app)
+	return response, nil
 }
 
 func (uc *ApplicationUsecase) Update(ctx context.Context, userID, appID uuid.UUID, input UpdateApplicationInput) (*ApplicationResponse, error) {
@@ -158,13 +347,19 @@ func (uc *ApplicationUsecase) Update(ctx context.Context, userID, appID uuid.UUI
 		app.Category = category
 	}
 
-	// Update status if provided
+	// Update status if provided; the same status again is not a transition
+	var statusChanges []*entity.ApplicationStatusChange
 	if input.Status != "" {
 		status := value.ApplicationStatus(strings.ToLower(input.Status))
 		if err := status.Validate(); err != nil {
 			return nil, errors.New("invalid status")
 		}
-		app.UpdateStatus(status)
+		if status != app.Status {
+			if err := uc.progress.ValidateStatusTransition(app.Status, status); err != nil {
+				return nil, err
+			}
+			statusChanges = append(statusChanges, app.UpdateStatus(status))
+		}
 	}
 
 	// Update color tag if provided
@@ -185,18 +380,84 @@ func (uc *ApplicationUsecase) Update(ctx context.Context, userID, appID uuid.UUI
 			if err != nil {
 				return nil, errors.New("invalid scheduled_at format")
 			}
+			t = t.UTC()
 			app.Reschedule(&t)
 		}
 	}
 
+	// Update length of the event and travel time around it
+	if input.ScheduleLengthInput.isSet() {
+		duration, buffer, err := input.ScheduleLengthInput.resolve(app.ScheduledAt, app.Duration, app.TravelBuffer)
+		if err != nil {
+			return nil, err
+		}
+		app.SetDuration(duration, buffer)
+	}
+
 	// Update notes
 	app.UpdateNotes(input.Motivation, input.WhatToDo, input.JobAxis, input.Strengths)
 
-	if err := uc.appRepo.Update(ctx, app); err != nil {
+	// Let the status follow the stages from now on, or stop doing so
+	if input.StatusFromStages != nil {
+		app.StatusFromStages = *input.StatusFromStages
+	}
+	statusChanges = append(statusChanges, uc.deriveStatus(app)...)
+
+	if err := uc.appRepo.Update(ctx, app, statusChanges...); err != nil {
+		return nil, err
+	}
+
+	response := uc.toResponse(app)
+	response.Warnings = uc.scheduleWarnings(ctx, app)
+	return response, nil
+}
+
+// Get returns one of the user's applications with its status timeline, oldest first
+func (uc *ApplicationUsecase) Get(ctx context.Context, userID, appID uuid.UUID) (*ApplicationDetailResponse, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	app, err := uc.appRepo.FindByID(ctx, appID)
+	if err != nil {
 		return nil, err
 	}
+	if app == nil {
+		return nil, errors.New("application not found")
+	}
+	if app.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+
+	history, err := uc.appRepo.FindStatusHistory(ctx, app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := uc.progress.AllowedNextStatuses(app.Status)
+	detail := &ApplicationDetailResponse{
+		ApplicationResponse: uc.toResponse(app),
+		AllowedNextStatuses: make([]string, 0, len(allowed)),
+		Stages:              toStageResponses(app.Stages),
+		Timeline:            make([]*StatusChangeResponse, 0, len(history)),
+	}
+	for _, status := range allowed {
+		detail.AllowedNextStatuses = append(detail.AllowedNextStatuses, string(status))
+	}
+	for _, change := range history {
+		item := &StatusChangeResponse{
+			ID:        change.ID.String(),
+			ToStatus:  string(change.ToStatus),
+			ChangedAt: change.ChangedAt.Format(time.RFC3339),
+		}
+		if change.FromStatus != nil {
+			from := string(*change.FromStatus)
+			item.FromStatus = &from
+		}
+		detail.Timeline = append(detail.Timeline, item)
+	}
 
-	return uc.toResponse(app), nil
+	return detail, nil
 }
 
 func (uc *ApplicationUsecase) toResponse(app *entity.Application) *ApplicationResponse {
@@ -205,21 +466,26 @@ func (uc *ApplicationUsecase) toResponse(app *entity.Application) *ApplicationRe
 		s := app.ScheduledAt.UTC().Format(time.RFC3339)
 		scheduledAt = &s
 	}
+	durationMinutes, endsAt := scheduleLengthFields(app.ScheduledAt, app.Duration)
 
 	return &ApplicationResponse{
-		ID:          app.ID.String(),
-		UserID:      app.UserID.String(),
-		CompanyID:   app.CompanyID.String(),
-		Category:    string(app.Category),
-		Status:      string(app.Status),
-		ScheduledAt: scheduledAt,
-		ColorTag:    string(app.ColorTag),
-		Completed:   app.Completed,
-		Motivation:  app.Motivation,
-		WhatToDo:    app.WhatToDo,
-		JobAxis:     app.JobAxis,
-		Strengths:   app.Strengths,
-		CreatedAt:   app.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   app.UpdatedAt.Format(time.RFC3339),
+		ID:                  app.ID.String(),
+		UserID:              app.UserID.String(),
+		CompanyID:           app.CompanyID.String(),
+		Category:            string(app.Category),
+		Status:              string(app.Status),
+		ScheduledAt:         scheduledAt,
+		DurationMinutes:     durationMinutes,
+		EndsAt:              endsAt,
+		TravelBufferMinutes: int(app.TravelBuffer / time.Minute),
+		ColorTag:            string(app.ColorTag),
+		Completed:           app.Completed,
+		Motivation:          app.Motivation,
+		WhatToDo:            app.WhatToDo,
+		JobAxis:             app.JobAxis,
+		Strengths:           app.Strengths,
+		StatusFromStages:    app.StatusFromStages,
+		CreatedAt:           app.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:           app.UpdatedAt.Format(time.RFC3339),
 	}
 }
@@ -3,35 +3,53 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"log"
 	"noroi/internal/domain/entity"
 	"noroi/internal/domain/value"
 	"noroi/internal/repository"
 	"noroi/pkg/errors"
 	"noroi/pkg/jwt"
+	"noroi/pkg/token"
 	"strconv"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type AuthUsecase struct {
 	userRepo       repository.UserRepository
 	curseStyleRepo repository.CurseStyleRepository
+	sessionRepo    repository.SessionRepository
 	jwtManager     *jwt.Manager
+	verifications  *EmailVerificationUsecase
+	loginGuard     *LoginGuard
+	mfa            *MFAUsecase
 }
 
 func NewAuthUsecase(
 	userRepo repository.UserRepository,
 	curseStyleRepo repository.CurseStyleRepository,
+	sessionRepo repository.SessionRepository,
 	jwtManager *jwt.Manager,
+	verifications *EmailVerificationUsecase,
+	loginGuard *LoginGuard,
+	mfa *MFAUsecase,
 ) *AuthUsecase {
 	return &AuthUsecase{
 		userRepo:       userRepo,
 		curseStyleRepo: curseStyleRepo,
+		sessionRepo:    sessionRepo,
 		jwtManager:     jwtManager,
+		verifications:  verifications,
+		loginGuard:     loginGuard,
+		mfa:            mfa,
 	}
 }
 
 type RegisterInput struct {
 	Email      string `json:"email"`
 	Username   string `json:"username"`
+	Handle     string `json:"handle"` // optional; generated when empty
 	Password   string `json:"password"`
 	Age        string `json:"age"` // Frontend sends as string
 	Gender     string `json:"gender"`
@@ -43,23 +61,56 @@ type LoginInput struct {
 	Password string `json:"password"`
 }
 
+// ClientInfo describes the device a session is created from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// AuthResponse carries either the token pair or, for accounts with 2FA, an
+// MFA challenge token to exchange at /auth/mfa/verify.
 type AuthResponse struct {
-	User         *UserResponse `json:"user"`
-	AccessToken  string        `json:"access_token"`
-	RefreshToken string        `json:"refresh_token"`
+	User         *UserResponse `json:"user,omitempty"`
+	AccessToken  string        `json:"access_token,omitempty"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
+	MFARequired  bool          `json:"mfa_required,omitempty"`
+	MFAToken     string        `json:"mfa_token,omitempty"`
+}
+
+type VerifyMFAInput struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+	Current    bool   `json:"current"`
 }
 
 type UserResponse struct {
-	ID           string `json:"id"`
-	Email        string `json:"email"`
-	Username     string `json:"username"`
-	Age          int    `json:"age"`
-	Gender       string `json:"gender"`
-	CurseStyleID string `json:"curse_style_id"`
-	Points       int    `json:"points"`
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	Username      string `json:"username"`
+	Handle        string `json:"handle"`
+	Age           int    `json:"age"`
+	Gender        string `json:"gender"`
+	CurseStyleID  string `json:"curse_style_id"`
+	Points        int    `json:"points"`
+	EmailVerified bool   `json:"email_verified"`
+	Role          string `json:"role"`
 }
 
-func (uc *AuthUsecase) Register(ctx context.Context, input RegisterInput) (*AuthResponse, error) {
+func (uc *AuthUsecase) Register(ctx context.Context, input RegisterInput, client ClientInfo) (*AuthResponse, error) {
 	// Validate email first
 	email, err := value.NewEmail(input.Email)
 	if err != nil {
@@ -82,7 +133,7 @@ func (uc *AuthUsecase) Register(ctx context.Context, input RegisterInput) (*Auth
 	}
 
 	// Validate and hash password (NewPassword already hashes it)
-	password, err := value.NewPassword(input.Password)
+	password, err := value.NewPassword(input.Password, email.String(), input.Username, input.Handle)
 	if err != nil {
 		return nil, err
 	}
@@ -109,78 +160,341 @@ func (uc *AuthUsecase) Register(ctx context.Context, input RegisterInput) (*Auth
 		curseStyle.ID,
 	)
 
+	if input.Handle != "" {
+		handle, err := value.NewHandle(input.Handle)
+		if err != nil {
+			return nil, err
+		}
+		user.Handle = handle
+	}
+
 	// Save user to database
 	if err := uc.userRepo.Create(ctx, user); err != nil {
+		if err == errors.ErrHandleTaken {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// Generate JWT tokens
-	tokens, err := uc.jwtManager.GenerateTokenPair(user.ID)
+	// Verification mail is best-effort; the user can request another one
+	if err := uc.verifications.SendVerification(ctx, user); err != nil {
+		log.Printf("failed to send verification mail for user %s: %v", user.ID, err)
+	}
+
+	// Start a session and generate tokens
+	tokens, err := uc.startSession(ctx, user, client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+		return nil, err
 	}
 
 	return &AuthResponse{
 		User: &UserResponse{
-			ID:           user.ID.String(),
-			Email:        user.Email.String(),
-			Username:     user.Username,
-			Age:          user.Age,
-			Gender:       string(user.Gender),
-			CurseStyleID: user.CurseStyleID.String(),
-			Points:       user.Points,
+			ID:            user.ID.String(),
+			Email:         user.Email.String(),
+			Username:      user.Username,
+			Handle:        user.Handle.String(),
+			Age:           user.Age,
+			Gender:        string(user.Gender),
+			CurseStyleID:  user.CurseStyleID.String(),
+			Points:        user.Points,
+			EmailVerified: user.IsEmailVerified(),
+			Role:          user.Role.String(),
 		},
 		AccessToken:  tokens.AccessToken,
 		RefreshToken: tokens.RefreshToken,
 	}, nil
 }
 
-func (uc *AuthUsecase) Login(ctx context.Context, input LoginInput) (*AuthResponse, error) {
+func (uc *AuthUsecase) Login(ctx context.Context, input LoginInput, client ClientInfo) (*AuthResponse, error) {
 	// Validate email
 	email, err := value.NewEmail(input.Email)
 	if err != nil {
 		return nil, err
 	}
 
-	// Find user by email
+	// Find user by email (a missing user is handled below, after the lock check)
 	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil && err != errors.ErrUserNotFound {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	var userID *uuid.UUID
+	if user != nil {
+		userID = &user.ID
+	}
+
+	// Refuse locked-out accounts and IPs before spending CPU on a password hash
+	if err := uc.loginGuard.Check(ctx, email.String(), client); err != nil {
+		uc.loginGuard.RecordFailure(ctx, userID, email.String(), client, entity.LoginFailureThrottled)
+		return nil, err
+	}
+
+	// Verify password. Unknown emails get a dummy compare so that response
+	// timing does not reveal which emails are registered.
+	if user == nil {
+		value.CompareDummy(input.Password)
+		uc.loginGuard.RecordFailure(ctx, nil, email.String(), client, entity.LoginFailureInvalidCredentials)
+		return nil, errors.ErrInvalidCredentials
+	}
+	if !user.Password.Compare(input.Password) {
+		uc.loginGuard.RecordFailure(ctx, userID, email.String(), client, entity.LoginFailureInvalidCredentials)
+		return nil, errors.ErrInvalidCredentials
+	}
+	uc.upgradePasswordHash(ctx, user, input.Password)
+
+	// With 2FA the password only earns a short-lived challenge. The account
+	// failure counter is kept until the second step succeeds, so that wrong
+	// codes cannot be reset by re-entering the password.
+	mfaEnabled, err := uc.mfa.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check two-factor authentication: %w", err)
+	}
+	if mfaEnabled {
+		mfaToken, err := uc.jwtManager.GenerateToken(jwt.TokenTypeMFAChallenge, user.ID, uuid.Nil, entity.MFAChallengeLifetime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate mfa token: %w", err)
+		}
+		return &AuthResponse{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	return uc.completeLogin(ctx, user, client)
+}
+
+// VerifyMFA completes a two-step login with a TOTP or recovery code
+func (uc *AuthUsecase) VerifyMFA(ctx context.Context, input VerifyMFAInput, client ClientInfo) (*AuthResponse, error) {
+	claims, err := uc.jwtManager.ValidateToken(input.MFAToken, jwt.TokenTypeMFAChallenge)
+	if err != nil {
+		return nil, errors.ErrInvalidToken
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, claims.UserID)
 	if err != nil {
 		if err == errors.ErrUserNotFound {
-			return nil, errors.ErrInvalidCredentials
+			return nil, errors.ErrInvalidToken
 		}
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
 
-	// Verify password
-	if !user.Password.Compare(input.Password) {
-		return nil, errors.ErrInvalidCredentials
+	// Wrong codes count against the same lock as wrong passwords
+	if err := uc.loginGuard.Check(ctx, user.Email.String(), client); err != nil {
+		uc.loginGuard.RecordFailure(ctx, &user.ID, user.Email.String(), client, entity.LoginFailureThrottled)
+		return nil, err
+	}
+	if err := uc.mfa.Verify(ctx, user.ID, input.Code); err != nil {
+		if err == errors.ErrInvalidMFACode {
+			uc.loginGuard.RecordFailure(ctx, &user.ID, user.Email.String(), client, entity.LoginFailureInvalidMFACode)
+		}
+		return nil, err
 	}
 
-	// Generate JWT tokens
-	tokens, err := uc.jwtManager.GenerateTokenPair(user.ID)
+	return uc.completeLogin(ctx, user, client)
+}
+
+func (uc *AuthUsecase) completeLogin(ctx context.Context, user *entity.User, client ClientInfo) (*AuthResponse, error) {
+	uc.loginGuard.RecordSuccess(ctx, user.ID, user.Email.String(), client)
+
+	// Start a session and generate tokens
+	tokens, err := uc.startSession(ctx, user, client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+		return nil, err
 	}
 
 	return &AuthResponse{
 		User: &UserResponse{
-			ID:           user.ID.String(),
-			Email:        user.Email.String(),
-			Username:     user.Username,
-			Age:          user.Age,
-			Gender:       string(user.Gender),
-			CurseStyleID: user.CurseStyleID.String(),
-			Points:       user.Points,
+			ID:            user.ID.String(),
+			Email:         user.Email.String(),
+			Username:      user.Username,
+			Handle:        user.Handle.String(),
+			Age:           user.Age,
+			Gender:        string(user.Gender),
+			CurseStyleID:  user.CurseStyleID.String(),
+			Points:        user.Points,
+			EmailVerified: user.IsEmailVerified(),
+			Role:          user.Role.String(),
 		},
 		AccessToken:  tokens.AccessToken,
 		RefreshToken: tokens.RefreshToken,
 	}, nil
 }
 
-func (uc *AuthUsecase) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
-	newAccessToken, err := uc.jwtManager.RefreshAccessToken(refreshToken)
+// RefreshToken rotates a refresh token: the presented token is consumed and a
+// new access/refresh pair is issued for the same session. Presenting a token
+// that was already consumed means it leaked, so the whole session is revoked.
+func (uc *AuthUsecase) RefreshToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	current, err := uc.sessionRepo.FindRefreshToken(ctx, token.Hash(refreshToken))
+	if err != nil {
+		if err == errors.ErrInvalidToken {
+			return nil, errors.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+
+	session, err := uc.sessionRepo.FindByID(ctx, current.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find session: %w", err)
+	}
+
+	now := time.Now()
+	if !session.IsActive(now) {
+		return nil, errors.ErrInvalidToken
+	}
+	if current.UsedAt != nil {
+		return nil, uc.revokeReusedSession(ctx, session)
+	}
+	if !now.Before(current.ExpiresAt) {
+		return nil, errors.ErrInvalidToken
+	}
+
+	// Reload the user so that role changes apply from the next access token
+	user, err := uc.userRepo.FindByID(ctx, session.UserID)
+	if err != nil {
+		if err == errors.ErrUserNotFound {
+			return nil, errors.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	plain, hash, err := token.Generate()
+	if err != nil {
+		return nil, err
+	}
+	next := entity.NewRefreshToken(session, hash)
+
+	rotated, err := uc.sessionRepo.Rotate(ctx, current, next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// Lost the race against another use of the same token
+		return nil, uc.revokeReusedSession(ctx, session)
+	}
+
+	accessToken, err := uc.generateAccessToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: plain,
+	}, nil
+}
+
+// Logout revokes the session that owns refreshToken. Unknown tokens are ignored
+// so that logging out is idempotent.
+func (uc *AuthUsecase) Logout(ctx context.Context, refreshToken string) error {
+	current, err := uc.sessionRepo.FindRefreshToken(ctx, token.Hash(refreshToken))
+	if err != nil {
+		if err == errors.ErrInvalidToken {
+			return nil
+		}
+		return fmt.Errorf("failed to find refresh token: %w", err)
+	}
+
+	if err := uc.sessionRepo.Revoke(ctx, current.SessionID, entity.SessionRevokeReasonLogout); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListSessions returns the user's active sessions (logged-in devices)
+func (uc *AuthUsecase) ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]*SessionResponse, error) {
+	sessions, err := uc.sessionRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	responses := make([]*SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, &SessionResponse{
+			ID:         session.ID.String(),
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+			LastUsedAt: session.LastUsedAt.Format(time.RFC3339),
+			ExpiresAt:  session.ExpiresAt.Format(time.RFC3339),
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return responses, nil
+}
+
+// ListLoginEvents returns the user's recent successful and failed logins
+func (uc *AuthUsecase) ListLoginEvents(ctx context.Context, userID uuid.UUID) ([]*LoginEventResponse, error) {
+	return uc.loginGuard.ListEvents(ctx, userID)
+}
+
+// RevokeSession signs out one of the user's devices
+func (uc *AuthUsecase) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := uc.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return errors.ErrSessionNotFound
+	}
+
+	return uc.sessionRepo.Revoke(ctx, session.ID, entity.SessionRevokeReasonUserRevoked)
+}
+
+// RevokeOtherSessions signs out every device except the current one
+func (uc *AuthUsecase) RevokeOtherSessions(ctx context.Context, userID, currentSessionID uuid.UUID) error {
+	return uc.sessionRepo.RevokeAllByUserID(ctx, userID, currentSessionID, entity.SessionRevokeReasonUserRevoked)
+}
+
+func (uc *AuthUsecase) startSession(ctx context.Context, user *entity.User, client ClientInfo) (*TokenResponse, error) {
+	plain, hash, err := token.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	session := entity.NewSession(user.ID, client.UserAgent, client.IPAddress)
+	if err := uc.sessionRepo.Create(ctx, session, entity.NewRefreshToken(session, hash)); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	accessToken, err := uc.generateAccessToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: plain,
+	}, nil
+}
+
+// generateAccessToken issues an access token carrying the user's current role and permissions
+func (uc *AuthUsecase) generateAccessToken(user *entity.User, sessionID uuid.UUID) (string, error) {
+	accessToken, err := uc.jwtManager.GenerateAccessToken(user.ID, sessionID, user.Role.String(), permissionNames(user.Role))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+	return accessToken, nil
+}
+
+func (uc *AuthUsecase) revokeReusedSession(ctx context.Context, session *entity.Session) error {
+	log.Printf("refresh token reuse detected for session %s (user %s); revoking session", session.ID, session.UserID)
+	if err := uc.sessionRepo.Revoke(ctx, session.ID, entity.SessionRevokeReasonTokenReuse); err != nil {
+		return err
+	}
+	return errors.ErrRefreshTokenReused
+}
+
+// upgradePasswordHash rehashes a verified password whose hash uses a legacy
+// scheme or outdated parameters. Failures are logged; the login still succeeds.
+func (uc *AuthUsecase) upgradePasswordHash(ctx context.Context, user *entity.User, plainPassword string) {
+	if !user.Password.NeedsRehash() {
+		return
+	}
+
+	password, err := user.Password.Rehash(plainPassword)
 	if err != nil {
-		return "", errors.ErrInvalidToken
+		log.Printf("failed to rehash password for user %s: %v", user.ID, err)
+		return
+	}
+	user.ChangePassword(password)
+	if err := uc.userRepo.UpdatePasswordHash(ctx, user); err != nil {
+		log.Printf("failed to store upgraded password hash for user %s: %v", user.ID, err)
 	}
-	return newAccessToken, nil
 }
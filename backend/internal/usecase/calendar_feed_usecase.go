@@ -0,0 +1,291 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"noroi/internal/domain/domain_service"
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+	"noroi/internal/repository"
+	"noroi/pkg/ical"
+	"noroi/pkg/token"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// calendarFeedHistory is how far back the feed includes past events
+	calendarFeedHistory = 90 * 24 * time.Hour
+	calendarProdID      = "-//Noroi//Applications//JA"
+)
+
+// CalendarFeedUsecase serves the user's applications, selection stages and
+// reminders as an iCalendar subscription behind a secret URL
+type CalendarFeedUsecase struct {
+	feedRepo       repository.CalendarFeedRepository
+	appRepo        repository.ApplicationRepository
+	reminderRepo   repository.ReminderRepository
+	preferenceRepo repository.UserPreferenceRepository
+	feedBaseURL    string
+	appURL         string
+}
+
+// NewCalendarFeedUsecase creates the use case. feedBaseURL is the public base
+// URL of this API, used to build the feed URL; appURL is the frontend base URL
+// that events link to and may be empty.
+func NewCalendarFeedUsecase(
+	feedRepo repository.CalendarFeedRepository,
+	appRepo repository.ApplicationRepository,
+	reminderRepo repository.ReminderRepository,
+	preferenceRepo repository.UserPreferenceRepository,
+	feedBaseURL string,
+	appURL string,
+) *CalendarFeedUsecase {
+	return &CalendarFeedUsecase{
+		feedRepo:       feedRepo,
+		appRepo:        appRepo,
+		reminderRepo:   reminderRepo,
+		preferenceRepo: preferenceRepo,
+		feedBaseURL:    strings.TrimRight(feedBaseURL, "/"),
+		appURL:         strings.TrimRight(appURL, "/"),
+	}
+}
+
+// CalendarFeedResponse describes the user's feed. The URLs contain the token
+// and are only returned right after it is (re)generated.
+type CalendarFeedResponse struct {
+	Enabled        bool    `json:"enabled"`
+	URL            *string `json:"url,omitempty"`
+	WebcalURL      *string `json:"webcal_url,omitempty"`
+	CreatedAt      *string `json:"created_at,omitempty"`
+	LastAccessedAt *string `json:"last_accessed_at,omitempty"`
+}
+
+// Get reports whether the user has a feed and when it was last fetched
+func (uc *CalendarFeedUsecase) Get(ctx context.Context, userID uuid.UUID) (*CalendarFeedResponse, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	feed, err := uc.feedRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return &CalendarFeedResponse{Enabled: false}, nil
+	}
+	return toCalendarFeedResponse(feed), nil
+}
+
+// Regenerate issues a new feed token; the URL of an earlier token stops working
+func (uc *CalendarFeedUsecase) Regenerate(ctx context.Context, userID uuid.UUID) (*CalendarFeedResponse, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	plain, hash, err := token.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	feed := entity.NewCalendarFeed(userID, hash)
+	if err := uc.feedRepo.Save(ctx, feed); err != nil {
+		return nil, err
+	}
+
+	response := toCalendarFeedResponse(feed)
+	url := uc.feedBaseURL + "/api/v1/calendar/" + plain + ".ics"
+	webcal := "webcal://" + strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	response.URL = &url
+	response.WebcalURL = &webcal
+	return response, nil
+}
+
+// Disable removes the user's feed
+func (uc *CalendarFeedUsecase) Disable(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return errors.New("user id is required")
+	}
+	return uc.feedRepo.Delete(ctx, userID)
+}
+
+// Render returns the iCalendar document of the feed with the given token
+func (uc *CalendarFeedUsecase) Render(ctx context.Context, plainToken string) ([]byte, error) {
+	feed, err := uc.feedRepo.FindByTokenHash(ctx, token.Hash(plainToken))
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return nil, errors.New("calendar feed not found")
+	}
+
+	now := time.Now().UTC()
+	if feed.ShouldRecordAccess(now) {
+		if err := uc.feedRepo.RecordAccess(ctx, feed.UserID, now); err != nil {
+			log.Printf("calendar: failed to record access for user %s: %v", feed.UserID, err)
+		}
+	}
+
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, feed.UserID)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := uc.appRepo.FindForCalendar(ctx, feed.UserID, now.Add(-calendarFeedHistory))
+	if err != nil {
+		return nil, err
+	}
+	reminders, err := uc.reminderRepo.FindPendingByUserID(ctx, feed.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &ical.Calendar{
+		ProdID:   calendarProdID,
+		Name:     calendarName[preferences.Locale],
+		Location: preferences.Location(),
+		Events:   uc.calendarEvents(apps, reminders, preferences.Locale, now.Add(-calendarFeedHistory)),
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.Encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// calendarEvents turns the scheduled applications and stages starting after
+// since into events. A reminder becomes an alarm on the first event of its
+// application at or after its firing time, or on the last one.
+func (uc *CalendarFeedUsecase) calendarEvents(apps []*repository.ApplicationWithCompany, reminders []*entity.Reminder, locale value.Locale, since time.Time) []ical.Event {
+	var events []ical.Event
+	byApplication := make(map[uuid.UUID][]int)
+
+	for _, item := range apps {
+		app := item.Application
+		url := ""
+		if uc.appURL != "" {
+			url = uc.appURL + "/applications/" + app.ID.String()
+		}
+		cancelled := app.Status == value.ApplicationStatusWithdrawn || app.Status == value.ApplicationStatusRejected
+
+		if app.ScheduledAt != nil && !app.ScheduledAt.Before(since) {
+			byApplication[app.ID] = append(byApplication[app.ID], len(events))
+			events = append(events, ical.Event{
+				UID:          "application-" + app.ID.String() + "@noroi",
+				Start:        *app.ScheduledAt,
+				End:          app.ScheduledAt.Add(eventDuration(app.Duration)),
+				Summary:      item.Company.Name + categoryLabel(app.Category, locale),
+				URL:          url,
+				Status:       eventStatus(cancelled),
+				LastModified: app.UpdatedAt,
+			})
+		}
+
+		for _, stage := range app.Stages {
+			if stage.ScheduledAt == nil || stage.ScheduledAt.Before(since) {
+				continue
+			}
+			description := ""
+			if stage.Notes != nil {
+				description = *stage.Notes
+			}
+			byApplication[app.ID] = append(byApplication[app.ID], len(events))
+			events = append(events, ical.Event{
+				UID:          "stage-" + stage.ID.String() + "@noroi",
+				Start:        *stage.ScheduledAt,
+				End:          stage.ScheduledAt.Add(eventDuration(stage.Duration)),
+				Summary:      item.Company.Name + " " + stage.Name,
+				Description:  description,
+				URL:          url,
+				Status:       eventStatus(cancelled && stage.Status == value.SelectionStagePending),
+				LastModified: stage.UpdatedAt,
+			})
+		}
+	}
+
+	for _, reminder := range reminders {
+		indexes := byApplication[reminder.ApplicationID]
+		if len(indexes) == 0 {
+			continue
+		}
+		target := -1
+		for _, i := range indexes {
+			if events[i].Start.Before(reminder.NextAttemptAt) {
+				continue
+			}
+			if target == -1 || events[i].Start.Before(events[target].Start) {
+				target = i
+			}
+		}
+		if target == -1 {
+			for _, i := range indexes {
+				if target == -1 || events[i].Start.After(events[target].Start) {
+					target = i
+				}
+			}
+		}
+		events[target].Alarms = append(events[target].Alarms, ical.Alarm{
+			Trigger:     reminder.NextAttemptAt,
+			Description: reminder.Message,
+		})
+	}
+
+	return events
+}
+
+var calendarName = map[value.Locale]string{
+	value.LocaleJa: "Noroi 就活予定",
+	value.LocaleEn: "Noroi applications",
+}
+
+var categoryLabels = map[value.Locale]map[value.ApplicationCategory]string{
+	value.LocaleJa: {
+		value.ApplicationCategoryMain:   "（本選考）",
+		value.ApplicationCategoryIntern: "（インターン）",
+		value.ApplicationCategoryInfo:   "（説明会）",
+	},
+	value.LocaleEn: {
+		value.ApplicationCategoryMain:   " (application)",
+		value.ApplicationCategoryIntern: " (internship)",
+		value.ApplicationCategoryInfo:   " (info session)",
+	},
+}
+
+func categoryLabel(category value.ApplicationCategory, locale value.Locale) string {
+	if labels, ok := categoryLabels[locale]; ok {
+		return labels[category]
+	}
+	return categoryLabels[value.DefaultLocale][category]
+}
+
+func eventDuration(d *time.Duration) time.Duration {
+	if d == nil {
+		return domain_service.DefaultEventDuration
+	}
+	return *d
+}
+
+func eventStatus(cancelled bool) string {
+	if cancelled {
+		return ical.StatusCancelled
+	}
+	return ical.StatusConfirmed
+}
+
+func toCalendarFeedResponse(feed *entity.CalendarFeed) *CalendarFeedResponse {
+	createdAt := feed.CreatedAt.Format(time.RFC3339)
+	response := &CalendarFeedResponse{
+		Enabled:   true,
+		CreatedAt: &createdAt,
+	}
+	if feed.LastAccessedAt != nil {
+		s := feed.LastAccessedAt.Format(time.RFC3339)
+		response.LastAccessedAt = &s
+	}
+	return response
+}
@@ -2,7 +2,9 @@ package usecase
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
+	"io"
 	"strings"
 	"time"
 
@@ -147,3 +149,121 @@ func (uc *CompanyUsecase) List(ctx context.Context, userID uuid.UUID, input Comp
 
 	return res, nil
 }
+
+// csvExportPageSize is the page size used to stream a CSV export
+const csvExportPageSize = 100
+
+// companyCSVHeader is the column order of GET /companies/export.csv. A company
+// has one row per application of the user, or one row without application columns.
+var companyCSVHeader = []string{
+	"company_id", "company_name", "recruitment_url", "industry", "location",
+	"application_id", "category", "status", "scheduled_at", "color_tag",
+	"motivation", "what_to_do", "job_axis", "strengths", "updated_at",
+}
+
+// ExportCSV writes the companies List returns for input to w as CSV, one page
+// at a time so that the whole list is never held in memory. Limit and Offset
+// are ignored.
+func (uc *CompanyUsecase) ExportCSV(ctx context.Context, userID uuid.UUID, input CompanyListInput, w io.Writer) error {
+	input.Limit = csvExportPageSize
+	input.Offset = 0
+	page, err := uc.List(ctx, userID, input)
+	if err != nil {
+		return err
+	}
+
+	cw, err := newCSVWriter(w, companyCSVHeader)
+	if err != nil {
+		return err
+	}
+	for {
+		for _, item := range page {
+			if err := cw.Write(companyCSVRow(item)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		if len(page) < csvExportPageSize {
+			return nil
+		}
+
+		input.Offset += len(page)
+		if page, err = uc.List(ctx, userID, input); err != nil {
+			return err
+		}
+	}
+}
+
+func companyCSVRow(item *CompanyWithApplicationResponse) []string {
+	row := []string{
+		item.Company.ID, item.Company.Name, optionalString(item.Company.RecruitmentURL),
+		optionalString(item.Company.Industry), optionalString(item.Company.Location),
+	}
+	app := item.MyApplication
+	if app == nil {
+		return append(row, make([]string, len(companyCSVHeader)-len(row))...)
+	}
+	return append(row,
+		app.ID, app.Category, app.Status, optionalString(app.ScheduledAt), app.ColorTag,
+		app.Motivation, app.WhatToDo, app.JobAxis, app.Strengths, app.UpdatedAt,
+	)
+}
+
+// newCSVWriter starts a CSV document with a UTF-8 BOM, so that spreadsheet apps
+// detect the encoding, and the header row
+func newCSVWriter(w io.Writer, header []string) (*csvWriter, error) {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	cw := &csvWriter{Writer: csv.NewWriter(w)}
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// csvWriter writes user-entered text so that spreadsheet apps show it as text
+// instead of running it as a formula (CSV injection)
+type csvWriter struct {
+	*csv.Writer
+}
+
+func (w *csvWriter) Write(record []string) error {
+	escaped := make([]string, len(record))
+	for i, cell := range record {
+		escaped[i] = escapeCSVFormula(cell)
+	}
+	return w.Writer.Write(escaped)
+}
+
+func (w *csvWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvFormulaPrefixes are the first characters that make a spreadsheet read a cell as a formula
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// escapeCSVFormula prefixes a cell that would start a formula with a quote
+func escapeCSVFormula(cell string) string {
+	if cell != "" && strings.ContainsRune(csvFormulaPrefixes, rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// unescapeCSVFormula undoes escapeCSVFormula, so that an exported file imports unchanged
+func unescapeCSVFormula(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(cell[1])) {
+		return cell[1:]
+	}
+	return cell
+}
@@ -11,17 +11,20 @@ import (
 )
 
 type CurseUsecase struct {
-	postRepo  repository.PostRepository
-	curseRepo repository.CurseRepository
+	postRepo      repository.PostRepository
+	curseRepo     repository.CurseRepository
+	notifications *NotificationUsecase
 }
 
 func NewCurseUsecase(
 	postRepo repository.PostRepository,
 	curseRepo repository.CurseRepository,
+	notifications *NotificationUsecase,
 ) *CurseUsecase {
 	return &CurseUsecase{
-		postRepo:  postRepo,
-		curseRepo: curseRepo,
+		postRepo:      postRepo,
+		curseRepo:     curseRepo,
+		notifications: notifications,
 	}
 }
 
@@ -57,6 +60,15 @@ func (uc *CurseUsecase) CursePost(ctx context.Context, userID, postID uuid.UUID)
 		return fmt.Errorf("failed to increment curse count: %w", err)
 	}
 
+	// Let the author know (respects their notify_curse setting)
+	uc.notifications.Publish(&Notification{
+		UserID: post.UserID,
+		Kind:   NotificationKindCurse,
+		Title:  "怨念が届きました",
+		Body:   "あなたの投稿に誰かが怨念を送りました。",
+		URL:    "/posts/" + post.ID.String(),
+	})
+
 	return nil
 }
 
@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"noroi/internal/domain/domain_service"
+	"noroi/internal/domain/value"
+	"noroi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// dashboardUpcomingDays is how many days after today the upcoming list covers
+	dashboardUpcomingDays = 7
+	// dashboardOverdueHistory is how far back overdue items are looked for
+	dashboardOverdueHistory = 90 * 24 * time.Hour
+	// maxDashboardReminders bounds the reminders listed
+	maxDashboardReminders = 50
+)
+
+// DashboardUsecase gathers what the "today" screen shows in one call
+type DashboardUsecase struct {
+	appRepo        repository.ApplicationRepository
+	reminderRepo   repository.ReminderRepository
+	preferenceRepo repository.UserPreferenceRepository
+	scheduler      domain_service.ApplicationScheduler
+}
+
+func NewDashboardUsecase(
+	appRepo repository.ApplicationRepository,
+	reminderRepo repository.ReminderRepository,
+	preferenceRepo repository.UserPreferenceRepository,
+) *DashboardUsecase {
+	return &DashboardUsecase{
+		appRepo:        appRepo,
+		reminderRepo:   reminderRepo,
+		preferenceRepo: preferenceRepo,
+	}
+}
+
+type TodayDashboardResponse struct {
+	Date      string               `json:"date"` // today in the user's time zone (YYYY-MM-DD)
+	TimeZone  string               `json:"time_zone"`
+	Overdue   []*DashboardItem     `json:"overdue"`
+	Today     []*DashboardItem     `json:"today"`
+	Upcoming  []*DashboardItem     `json:"upcoming"` // the next 7 days after today
+	Reminders []*DashboardReminder `json:"reminders"`
+	Counts    DashboardCounts      `json:"counts"`
+	// Truncated reports that more applications were scheduled than the
+	// dashboard loads; the latest items are missing
+	Truncated bool `json:"truncated"`
+}
+
+// DashboardItem is a scheduled application or pending selection stage
+type DashboardItem struct {
+	Type          string  `json:"type"` // application | stage
+	Date          string  `json:"date"` // day of StartsAt in the user's time zone
+	ApplicationID string  `json:"application_id"`
+	StageID       *string `json:"stage_id,omitempty"`
+	CompanyID     string  `json:"company_id"`
+	CompanyName   string  `json:"company_name"`
+	Category      string  `json:"category"`
+	Status        string  `json:"status"` // application status
+	StageName     string  `json:"stage_name,omitempty"`
+	StartsAt      string  `json:"starts_at"`
+	EndsAt        string  `json:"ends_at"`
+	ColorTag      string  `json:"color_tag"`
+}
+
+type DashboardReminder struct {
+	*ReminderResponse
+	CompanyName string `json:"company_name"`
+	Category    string `json:"category"`
+}
+
+// DashboardCounts holds the numbers of the header widgets. The application
+// counts cover all of the user's applications and list every status and
+// category, including those with none.
+type DashboardCounts struct {
+	Applications        int                       `json:"applications"`
+	ByStatus            map[string]int            `json:"by_status"`
+	ByCategory          map[string]int            `json:"by_category"`
+	ByCategoryAndStatus map[string]map[string]int `json:"by_category_and_status"`
+	Overdue             int                       `json:"overdue"`
+	Today               int                       `json:"today"`
+	Upcoming            int                       `json:"upcoming"`
+	Reminders           int                       `json:"reminders"`
+}
+
+// Today returns the user's schedule around the current day in their time zone.
+// Overdue are pending stages dated before today and applications dated before
+// today that have not started (todo or scheduled); finished (done, withdrawn or
+// rejected) applications never appear.
+func (uc *DashboardUsecase) Today(ctx context.Context, userID uuid.UUID) (*TodayDashboardResponse, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	loc := preferences.Location()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	tomorrow := today.AddDate(0, 0, 1)
+	until := today.AddDate(0, 0, dashboardUpcomingDays+1)
+	since := today.Add(-dashboardOverdueHistory)
+
+	results, truncated, err := uc.appRepo.FindScheduled(ctx, userID, since, &until, scheduleQueryLimit)
+	if err != nil {
+		return nil, err
+	}
+	reminders, err := uc.reminderRepo.FindPendingBefore(ctx, userID, until, maxDashboardReminders)
+	if err != nil {
+		return nil, err
+	}
+	reminderCount, err := uc.reminderRepo.CountPendingBefore(ctx, userID, until)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := uc.appRepo.CountByCategoryAndStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TodayDashboardResponse{
+		Date:      today.Format(dateLayout),
+		TimeZone:  loc.String(),
+		Overdue:   []*DashboardItem{},
+		Today:     []*DashboardItem{},
+		Upcoming:  []*DashboardItem{},
+		Reminders: make([]*DashboardReminder, 0, len(reminders)),
+		Counts:    toDashboardCounts(counts),
+		Truncated: truncated,
+	}
+
+	for _, result := range results {
+		app := result.Application
+		for _, event := range uc.scheduler.Events(app) {
+			// An application found by one event may have others outside the window
+			if event.Start.Before(since) || !event.Start.Before(until) {
+				continue
+			}
+			item := toDashboardItem(event, result, loc)
+			switch {
+			case !event.Start.Before(tomorrow):
+				response.Upcoming = append(response.Upcoming, item)
+			case !event.Start.Before(today):
+				response.Today = append(response.Today, item)
+			case event.Kind == domain_service.ScheduleEventStage || !applicationStarted(app.Status):
+				response.Overdue = append(response.Overdue, item)
+			}
+		}
+	}
+	for _, items := range [][]*DashboardItem{response.Overdue, response.Today, response.Upcoming} {
+		sort.SliceStable(items, func(i, j int) bool { return items[i].StartsAt < items[j].StartsAt })
+	}
+
+	for _, item := range reminders {
+		response.Reminders = append(response.Reminders, &DashboardReminder{
+			ReminderResponse: toReminderResponse(item.Reminder),
+			CompanyName:      item.CompanyName,
+			Category:         string(item.Category),
+		})
+	}
+
+	response.Counts.Overdue = len(response.Overdue)
+	response.Counts.Today = len(response.Today)
+	response.Counts.Upcoming = len(response.Upcoming)
+	response.Counts.Reminders = reminderCount
+	return response, nil
+}
+
+// applicationStarted reports whether the selection is under way, after which
+// the application's own date having passed is expected
+func applicationStarted(status value.ApplicationStatus) bool {
+	return status != value.ApplicationStatusToDo && status != value.ApplicationStatusScheduled
+}
+
+func toDashboardItem(event domain_service.ScheduleEvent, result *repository.ApplicationWithCompany, loc *time.Location) *DashboardItem {
+	app := result.Application
+	item := &DashboardItem{
+		Type:          string(event.Kind),
+		Date:          event.Start.In(loc).Format(dateLayout),
+		ApplicationID: app.ID.String(),
+		CompanyID:     result.Company.ID.String(),
+		CompanyName:   result.Company.Name,
+		Category:      string(app.Category),
+		Status:        string(app.Status),
+		StageName:     event.Name,
+		StartsAt:      event.Start.UTC().Format(time.RFC3339),
+		EndsAt:        event.End.UTC().Format(time.RFC3339),
+		ColorTag:      string(app.ColorTag),
+	}
+	if event.StageID != nil {
+		stageID := event.StageID.String()
+		item.StageID = &stageID
+	}
+	return item
+}
+
+func toDashboardCounts(counts []*repository.ApplicationCount) DashboardCounts {
+	statuses := []value.ApplicationStatus{
+		value.ApplicationStatusToDo, value.ApplicationStatusScheduled, value.ApplicationStatusInProgress,
+		value.ApplicationStatusDone, value.ApplicationStatusWithdrawn, value.ApplicationStatusRejected,
+	}
+	categories := []value.ApplicationCategory{
+		value.ApplicationCategoryMain, value.ApplicationCategoryIntern, value.ApplicationCategoryInfo,
+	}
+
+	result := DashboardCounts{
+		ByStatus:            make(map[string]int, len(statuses)),
+		ByCategory:          make(map[string]int, len(categories)),
+		ByCategoryAndStatus: make(map[string]map[string]int, len(categories)),
+	}
+	for _, status := range statuses {
+		result.ByStatus[string(status)] = 0
+	}
+	for _, category := range categories {
+		result.ByCategory[string(category)] = 0
+		result.ByCategoryAndStatus[string(category)] = make(map[string]int, len(statuses))
+		for _, status := range statuses {
+			result.ByCategoryAndStatus[string(category)][string(status)] = 0
+		}
+	}
+
+	for _, count := range counts {
+		result.Applications += count.Count
+		result.ByStatus[string(count.Status)] += count.Count
+		result.ByCategory[string(count.Category)] += count.Count
+		if byStatus, ok := result.ByCategoryAndStatus[string(count.Category)]; ok {
+			byStatus[string(count.Status)] += count.Count
+		}
+	}
+	return result
+}
@@ -0,0 +1,353 @@
+package usecase
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+	"strconv"
+	"time"
+)
+
+// Archive layout:
+//
+//	data.json                 everything below in one document
+//	profile.csv
+//	posts.csv
+//	curses_given.csv
+//	curses_received.csv
+//	applications.csv          one row per application, including notes
+//	selection_stages.csv
+//	ritual_participations.csv
+//	points_history.csv
+//
+// CSV files start with a UTF-8 BOM so that spreadsheet apps detect the encoding.
+
+type exportProfile struct {
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	Username      string `json:"username"`
+	Handle        string `json:"handle"`
+	Age           int    `json:"age"`
+	Gender        string `json:"gender"`
+	Points        int    `json:"points"`
+	ProfilePublic bool   `json:"profile_public"`
+	NotifyCurse   bool   `json:"notify_curse"`
+	NotifyRitual  bool   `json:"notify_ritual"`
+	EmailVerified bool   `json:"email_verified"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+type exportPost struct {
+	ID          string  `json:"id"`
+	Content     string  `json:"content"`
+	PostType    string  `json:"post_type"`
+	IsAnonymous bool    `json:"is_anonymous"`
+	CurseCount  int     `json:"curse_count"`
+	IsDeleted   bool    `json:"is_deleted"`
+	CreatedAt   string  `json:"created_at"`
+	DeletedAt   *string `json:"deleted_at,omitempty"`
+}
+
+type exportCurse struct {
+	PostID    string `json:"post_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+type exportStage struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Status      string  `json:"status"`
+	ScheduledAt *string `json:"scheduled_at,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+}
+
+type exportApplication struct {
+	ID          string         `json:"id"`
+	CompanyID   string         `json:"company_id"`
+	CompanyName string         `json:"company_name"`
+	Category    string         `json:"category"`
+	Status      string         `json:"status"`
+	ScheduledAt *string        `json:"scheduled_at,omitempty"`
+	ColorTag    string         `json:"color_tag"`
+	Completed   bool           `json:"completed"`
+	Motivation  string         `json:"motivation"`
+	WhatToDo    string         `json:"what_to_do"`
+	JobAxis     string         `json:"job_axis"`
+	Strengths   string         `json:"strengths"`
+	CreatedAt   string         `json:"created_at"`
+	UpdatedAt   string         `json:"updated_at"`
+	Stages      []*exportStage `json:"stages"`
+}
+
+type exportRitualParticipation struct {
+	RitualID     string `json:"ritual_id"`
+	StartTime    string `json:"start_time"`
+	RitualStatus string `json:"ritual_status"`
+	TotalDamage  int    `json:"total_damage"`
+	PostCount    int    `json:"post_count"`
+	CurseCount   int    `json:"curse_count"`
+	Rank         int    `json:"rank"`
+	PointsEarned int    `json:"points_earned"`
+}
+
+// exportPointsEntry is one credit to the user's points. Rituals are currently
+// the only source of points.
+type exportPointsEntry struct {
+	Date     string `json:"date"`
+	Source   string `json:"source"`
+	SourceID string `json:"source_id"`
+	Points   int    `json:"points"`
+}
+
+type exportDocument struct {
+	ExportedAt           string                       `json:"exported_at"`
+	Profile              *exportProfile               `json:"profile"`
+	Posts                []*exportPost                `json:"posts"`
+	CursesGiven          []*exportCurse               `json:"curses_given"`
+	CursesReceived       []*exportCurse               `json:"curses_received"`
+	Applications         []*exportApplication         `json:"applications"`
+	RitualParticipations []*exportRitualParticipation `json:"ritual_participations"`
+	PointsHistory        []*exportPointsEntry         `json:"points_history"`
+}
+
+func writeDataExportArchive(w io.Writer, user *entity.User, data *repository.PersonalData, now time.Time) error {
+	doc := buildExportDocument(user, data, now)
+
+	zw := zip.NewWriter(w)
+
+	jsonFile, err := zw.Create("data.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(jsonFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	csvFiles := []struct {
+		name   string
+		header []string
+		rows   [][]string
+	}{
+		{"profile.csv", []string{"id", "email", "username", "handle", "age", "gender", "points", "profile_public", "notify_curse", "notify_ritual", "email_verified", "created_at"}, [][]string{{
+			doc.Profile.ID, doc.Profile.Email, doc.Profile.Username, doc.Profile.Handle, strconv.Itoa(doc.Profile.Age), doc.Profile.Gender,
+			strconv.Itoa(doc.Profile.Points), strconv.FormatBool(doc.Profile.ProfilePublic), strconv.FormatBool(doc.Profile.NotifyCurse),
+			strconv.FormatBool(doc.Profile.NotifyRitual), strconv.FormatBool(doc.Profile.EmailVerified), doc.Profile.CreatedAt,
+		}}},
+		{"posts.csv", []string{"id", "content", "post_type", "is_anonymous", "curse_count", "is_deleted", "created_at", "deleted_at"}, postRows(doc.Posts)},
+		{"curses_given.csv", []string{"post_id", "created_at"}, curseRows(doc.CursesGiven)},
+		{"curses_received.csv", []string{"post_id", "created_at"}, curseRows(doc.CursesReceived)},
+		{"applications.csv", []string{"id", "company_id", "company_name", "category", "status", "scheduled_at", "color_tag", "completed", "motivation", "what_to_do", "job_axis", "strengths", "created_at", "updated_at"}, applicationRows(doc.Applications)},
+		{"selection_stages.csv", []string{"application_id", "company_name", "id", "name", "status", "scheduled_at", "notes"}, stageRows(doc.Applications)},
+		{"ritual_participations.csv", []string{"ritual_id", "start_time", "ritual_status", "total_damage", "post_count", "curse_count", "rank", "points_earned"}, ritualRows(doc.RitualParticipations)},
+		{"points_history.csv", []string{"date", "source", "source_id", "points"}, pointsRows(doc.PointsHistory)},
+	}
+	for _, f := range csvFiles {
+		if err := writeCSVFile(zw, f.name, f.header, f.rows); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeCSVFile(zw *zip.Writer, name string, header []string, rows [][]string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	cw, err := newCSVWriter(f, header)
+	if err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func buildExportDocument(user *entity.User, data *repository.PersonalData, now time.Time) *exportDocument {
+	doc := &exportDocument{
+		ExportedAt: now.Format(time.RFC3339),
+		Profile: &exportProfile{
+			ID:            user.ID.String(),
+			Email:         user.Email.String(),
+			Username:      user.Username,
+			Handle:        user.Handle.String(),
+			Age:           user.Age,
+			Gender:        string(user.Gender),
+			Points:        user.Points,
+			ProfilePublic: user.ProfilePublic,
+			NotifyCurse:   user.NotifyCurse,
+			NotifyRitual:  user.NotifyRitual,
+			EmailVerified: user.IsEmailVerified(),
+			CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:     user.UpdatedAt.Format(time.RFC3339),
+		},
+		Posts:                make([]*exportPost, 0, len(data.Posts)),
+		CursesGiven:          exportCurses(data.CursesGiven),
+		CursesReceived:       exportCurses(data.CursesReceived),
+		Applications:         make([]*exportApplication, 0, len(data.Applications)),
+		RitualParticipations: make([]*exportRitualParticipation, 0, len(data.RitualParticipations)),
+		PointsHistory:        make([]*exportPointsEntry, 0, len(data.RitualParticipations)),
+	}
+
+	for _, post := range data.Posts {
+		doc.Posts = append(doc.Posts, &exportPost{
+			ID:          post.ID.String(),
+			Content:     post.Content.String(),
+			PostType:    string(post.PostType),
+			IsAnonymous: post.IsAnonymous,
+			CurseCount:  post.CurseCount,
+			IsDeleted:   post.IsDeleted,
+			CreatedAt:   post.CreatedAt.Format(time.RFC3339),
+			DeletedAt:   formatOptionalTime(post.DeletedAt),
+		})
+	}
+
+	for _, record := range data.Applications {
+		app := record.Application
+		stages := make([]*exportStage, 0, len(app.Stages))
+		for _, stage := range app.Stages {
+			stages = append(stages, &exportStage{
+				ID:          stage.ID.String(),
+				Name:        stage.Name,
+				Status:      string(stage.Status),
+				ScheduledAt: formatOptionalTime(stage.ScheduledAt),
+				Notes:       stage.Notes,
+			})
+		}
+		doc.Applications = append(doc.Applications, &exportApplication{
+			ID:          app.ID.String(),
+			CompanyID:   app.CompanyID.String(),
+			CompanyName: record.CompanyName,
+			Category:    string(app.Category),
+			Status:      string(app.Status),
+			ScheduledAt: formatOptionalTime(app.ScheduledAt),
+			ColorTag:    string(app.ColorTag),
+			Completed:   app.Completed,
+			Motivation:  app.Motivation,
+			WhatToDo:    app.WhatToDo,
+			JobAxis:     app.JobAxis,
+			Strengths:   app.Strengths,
+			CreatedAt:   app.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:   app.UpdatedAt.Format(time.RFC3339),
+			Stages:      stages,
+		})
+	}
+
+	for _, record := range data.RitualParticipations {
+		p := record.Participant
+		startTime := record.RitualStartTime.Format(time.RFC3339)
+		doc.RitualParticipations = append(doc.RitualParticipations, &exportRitualParticipation{
+			RitualID:     p.RitualID.String(),
+			StartTime:    startTime,
+			RitualStatus: record.RitualStatus,
+			TotalDamage:  p.TotalDamage,
+			PostCount:    p.PostCount,
+			CurseCount:   p.CurseCount,
+			Rank:         p.Rank,
+			PointsEarned: p.PointsEarned,
+		})
+		if p.PointsEarned != 0 {
+			doc.PointsHistory = append(doc.PointsHistory, &exportPointsEntry{
+				Date:     startTime,
+				Source:   "ritual",
+				SourceID: p.RitualID.String(),
+				Points:   p.PointsEarned,
+			})
+		}
+	}
+
+	return doc
+}
+
+func exportCurses(records []*repository.CurseRecord) []*exportCurse {
+	curses := make([]*exportCurse, 0, len(records))
+	for _, record := range records {
+		curses = append(curses, &exportCurse{
+			PostID:    record.PostID.String(),
+			CreatedAt: record.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return curses
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
+func optionalString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func postRows(posts []*exportPost) [][]string {
+	rows := make([][]string, 0, len(posts))
+	for _, p := range posts {
+		rows = append(rows, []string{
+			p.ID, p.Content, p.PostType, strconv.FormatBool(p.IsAnonymous), strconv.Itoa(p.CurseCount),
+			strconv.FormatBool(p.IsDeleted), p.CreatedAt, optionalString(p.DeletedAt),
+		})
+	}
+	return rows
+}
+
+func curseRows(curses []*exportCurse) [][]string {
+	rows := make([][]string, 0, len(curses))
+	for _, c := range curses {
+		rows = append(rows, []string{c.PostID, c.CreatedAt})
+	}
+	return rows
+}
+
+func applicationRows(apps []*exportApplication) [][]string {
+	rows := make([][]string, 0, len(apps))
+	for _, a := range apps {
+		rows = append(rows, []string{
+			a.ID, a.CompanyID, a.CompanyName, a.Category, a.Status, optionalString(a.ScheduledAt), a.ColorTag,
+			strconv.FormatBool(a.Completed), a.Motivation, a.WhatToDo, a.JobAxis, a.Strengths, a.CreatedAt, a.UpdatedAt,
+		})
+	}
+	return rows
+}
+
+func stageRows(apps []*exportApplication) [][]string {
+	var rows [][]string
+	for _, a := range apps {
+		for _, s := range a.Stages {
+			rows = append(rows, []string{a.ID, a.CompanyName, s.ID, s.Name, s.Status, optionalString(s.ScheduledAt), optionalString(s.Notes)})
+		}
+	}
+	return rows
+}
+
+func ritualRows(participations []*exportRitualParticipation) [][]string {
+	rows := make([][]string, 0, len(participations))
+	for _, p := range participations {
+		rows = append(rows, []string{
+			p.RitualID, p.StartTime, p.RitualStatus, strconv.Itoa(p.TotalDamage), strconv.Itoa(p.PostCount),
+			strconv.Itoa(p.CurseCount), strconv.Itoa(p.Rank), strconv.Itoa(p.PointsEarned),
+		})
+	}
+	return rows
+}
+
+func pointsRows(entries []*exportPointsEntry) [][]string {
+	rows := make([][]string, 0, len(entries))
+	for _, e := range entries {
+		rows = append(rows, []string{e.Date, e.Source, e.SourceID, strconv.Itoa(e.Points)})
+	}
+	return rows
+}
@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"noroi/internal/domain/entity"
+	"noroi/internal/gateway"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"noroi/pkg/token"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// dataExportListLimit is how many past exports GET /users/me/exports returns
+	dataExportListLimit = 10
+	// dataExportPurgeBatchSize bounds how many expired archives are removed per run
+	dataExportPurgeBatchSize = 50
+)
+
+type DataExportUsecase struct {
+	exportRepo      repository.DataExportRepository
+	userRepo        repository.UserRepository
+	storage         gateway.FileStorage
+	mails           *MailUsecase
+	downloadBaseURL string
+}
+
+// NewDataExportUsecase creates the use case. downloadBaseURL is the public base
+// URL of this API, used to build the download link sent by mail.
+func NewDataExportUsecase(
+	exportRepo repository.DataExportRepository,
+	userRepo repository.UserRepository,
+	storage gateway.FileStorage,
+	mails *MailUsecase,
+	downloadBaseURL string,
+) *DataExportUsecase {
+	return &DataExportUsecase{
+		exportRepo:      exportRepo,
+		userRepo:        userRepo,
+		storage:         storage,
+		mails:           mails,
+		downloadBaseURL: strings.TrimRight(downloadBaseURL, "/"),
+	}
+}
+
+type DataExportResponse struct {
+	ID          string  `json:"id"`
+	Status      string  `json:"status"`
+	SizeBytes   *int64  `json:"size_bytes,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+	CompletedAt *string `json:"completed_at,omitempty"`
+	ExpiresAt   *string `json:"expires_at,omitempty"`
+}
+
+// DataExportDownload is an archive ready to be streamed to the client
+type DataExportDownload struct {
+	FileName  string
+	SizeBytes int64
+	Body      io.ReadCloser
+}
+
+// RequestExport queues an export of the user's data. While a job is still
+// running it is returned instead of queuing another one.
+func (uc *DataExportUsecase) RequestExport(ctx context.Context, userID uuid.UUID) (*DataExportResponse, error) {
+	recent, err := uc.exportRepo.FindByUserID(ctx, userID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(recent) > 0 {
+		latest := recent[0]
+		if latest.IsActive() {
+			return toDataExportResponse(latest), nil
+		}
+		if time.Since(latest.CreatedAt) < entity.DataExportMinInterval {
+			return nil, errors.ErrTooManyRequests
+		}
+	}
+
+	export := entity.NewDataExport(userID)
+	if err := uc.exportRepo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+	return toDataExportResponse(export), nil
+}
+
+// ListExports returns the user's recent exports, newest first
+func (uc *DataExportUsecase) ListExports(ctx context.Context, userID uuid.UUID) ([]*DataExportResponse, error) {
+	exports, err := uc.exportRepo.FindByUserID(ctx, userID, dataExportListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*DataExportResponse, 0, len(exports))
+	for _, export := range exports {
+		responses = append(responses, toDataExportResponse(export))
+	}
+	return responses, nil
+}
+
+// Download opens the archive for a download token. Expired, unknown and
+// not-yet-ready tokens all return errors.ErrInvalidToken.
+func (uc *DataExportUsecase) Download(ctx context.Context, plainToken string) (*DataExportDownload, error) {
+	export, err := uc.exportRepo.FindByTokenHash(ctx, token.Hash(plainToken))
+	if err != nil {
+		return nil, err
+	}
+	if !export.IsDownloadable(time.Now()) || export.FileName == nil {
+		return nil, errors.ErrInvalidToken
+	}
+
+	body, err := uc.storage.Open(ctx, *export.FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var size int64
+	if export.SizeBytes != nil {
+		size = *export.SizeBytes
+	}
+	return &DataExportDownload{
+		FileName:  fmt.Sprintf("noroi-export-%s.zip", export.CreatedAt.Format("20060102")),
+		SizeBytes: size,
+		Body:      body,
+	}, nil
+}
+
+// ProcessNext builds the archive for one due job and reports whether a job was claimed
+func (uc *DataExportUsecase) ProcessNext(ctx context.Context) (bool, error) {
+	export, err := uc.exportRepo.ClaimNext(ctx, entity.DataExportLease, entity.DataExportMaxAttempts)
+	if err != nil {
+		return false, err
+	}
+	if export == nil {
+		return false, nil
+	}
+
+	if err := uc.process(ctx, export); err != nil {
+		log.Printf("data export: attempt %d for %s failed: %v", export.Attempts, export.ID, err)
+		export.MarkFailed(err.Error())
+		if err := uc.exportRepo.Update(ctx, export); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+func (uc *DataExportUsecase) process(ctx context.Context, export *entity.DataExport) error {
+	user, err := uc.userRepo.FindByID(ctx, export.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	data, err := uc.exportRepo.CollectPersonalData(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	var archive bytes.Buffer
+	if err := writeDataExportArchive(&archive, user, data, time.Now()); err != nil {
+		return fmt.Errorf("failed to build archive: %w", err)
+	}
+
+	fileName := export.ID.String() + ".zip"
+	size, err := uc.storage.Save(ctx, fileName, &archive)
+	if err != nil {
+		return err
+	}
+
+	plain, hash, err := token.Generate()
+	if err != nil {
+		return err
+	}
+	export.MarkReady(fileName, size, hash)
+	if err := uc.exportRepo.Update(ctx, export); err != nil {
+		return err
+	}
+
+	mailData := struct {
+		Username       string
+		DownloadURL    string
+		ExpiresInHours int
+	}{
+		Username:       user.Username,
+		DownloadURL:    uc.downloadBaseURL + "/api/v1/exports/download?token=" + plain,
+		ExpiresInHours: int(entity.DataExportLinkLifetime / time.Hour),
+	}
+	// The archive is ready either way; a lost mail only means requesting again
+	if err := uc.mails.EnqueueToUser(ctx, user, gateway.MailTemplateDataExportReady, mailData); err != nil {
+		log.Printf("data export: failed to send ready mail for %s: %v", export.ID, err)
+	}
+	return nil
+}
+
+// PurgeExpired deletes archives whose download link has expired and returns how many were removed
+func (uc *DataExportUsecase) PurgeExpired(ctx context.Context) (int, error) {
+	exports, err := uc.exportRepo.FindExpired(ctx, time.Now(), dataExportPurgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, export := range exports {
+		if export.FileName != nil {
+			if err := uc.storage.Delete(ctx, *export.FileName); err != nil {
+				log.Printf("data export: failed to delete archive for %s: %v", export.ID, err)
+				continue
+			}
+		}
+		export.MarkExpired()
+		if err := uc.exportRepo.Update(ctx, export); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func toDataExportResponse(export *entity.DataExport) *DataExportResponse {
+	response := &DataExportResponse{
+		ID:        export.ID.String(),
+		Status:    string(export.Status),
+		SizeBytes: export.SizeBytes,
+		CreatedAt: export.CreatedAt.Format(time.RFC3339),
+	}
+	if export.CompletedAt != nil {
+		completedAt := export.CompletedAt.Format(time.RFC3339)
+		response.CompletedAt = &completedAt
+	}
+	if export.ExpiresAt != nil {
+		expiresAt := export.ExpiresAt.Format(time.RFC3339)
+		response.ExpiresAt = &expiresAt
+	}
+	return response
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"noroi/internal/domain/entity"
+	"noroi/internal/gateway"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"noroi/pkg/token"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EmailVerificationUsecase struct {
+	userRepo         repository.UserRepository
+	verificationRepo repository.EmailVerificationRepository
+	mails            *MailUsecase
+}
+
+func NewEmailVerificationUsecase(
+	userRepo repository.UserRepository,
+	verificationRepo repository.EmailVerificationRepository,
+	mails *MailUsecase,
+) *EmailVerificationUsecase {
+	return &EmailVerificationUsecase{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		mails:            mails,
+	}
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// SendVerification issues a verification token and mails the link to the user
+func (uc *EmailVerificationUsecase) SendVerification(ctx context.Context, user *entity.User) error {
+	plain, hash, err := token.Generate()
+	if err != nil {
+		return err
+	}
+	if err := uc.verificationRepo.Create(ctx, entity.NewEmailVerificationToken(user.ID, hash)); err != nil {
+		return err
+	}
+
+	data := struct {
+		Username       string
+		Token          string
+		ExpiresInHours int
+	}{
+		Username:       user.Username,
+		Token:          plain,
+		ExpiresInHours: int(entity.EmailVerificationTokenLifetime / time.Hour),
+	}
+	return uc.mails.EnqueueToUser(ctx, user, gateway.MailTemplateVerifyEmail, data)
+}
+
+// ResendVerification sends a new verification link to the signed-in user
+func (uc *EmailVerificationUsecase) ResendVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.IsEmailVerified() {
+		return errors.ErrEmailAlreadyVerified
+	}
+
+	issued, err := uc.verificationRepo.CountSince(ctx, user.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if issued >= entity.EmailVerificationMaxPerHour {
+		return errors.ErrTooManyRequests
+	}
+
+	return uc.SendVerification(ctx, user)
+}
+
+// VerifyEmail marks the token owner's email as verified. The welcome mail is
+// sent once the address is confirmed.
+func (uc *EmailVerificationUsecase) VerifyEmail(ctx context.Context, input VerifyEmailInput) error {
+	verification, err := uc.verificationRepo.FindByHash(ctx, token.Hash(input.Token))
+	if err != nil {
+		return err
+	}
+	if !verification.IsUsable(time.Now()) {
+		return errors.ErrInvalidToken
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, verification.UserID)
+	if err != nil {
+		if err == errors.ErrUserNotFound {
+			return errors.ErrInvalidToken
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.IsEmailVerified() {
+		return errors.ErrEmailAlreadyVerified
+	}
+
+	user.VerifyEmail()
+	consumed, err := uc.verificationRepo.Consume(ctx, verification, user)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return errors.ErrInvalidToken
+	}
+
+	// Welcome mail is best-effort; verification must not fail because of it
+	welcome := struct{ Username string }{Username: user.Username}
+	if err := uc.mails.EnqueueToUser(ctx, user, gateway.MailTemplateWelcome, welcome); err != nil {
+		log.Printf("failed to enqueue welcome mail for user %s: %v", user.ID, err)
+	}
+
+	return nil
+}
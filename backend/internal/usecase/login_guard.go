@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"noroi/internal/domain/domain_service"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// loginEventsLimit is how many recent login events a user can review
+const loginEventsLimit = 50
+
+// LoginThrottledError is returned while an account or IP is locked out
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many login attempts; retry after %s", e.RetryAfter)
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return errors.ErrTooManyRequests
+}
+
+type LoginEventResponse struct {
+	Success       bool   `json:"success"`
+	FailureReason string `json:"failure_reason,omitempty"`
+	IPAddress     string `json:"ip_address"`
+	UserAgent     string `json:"user_agent"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// LoginGuard throttles failed logins per account and per IP and records login
+// events. Counters live in the database so every replica sees the same state.
+type LoginGuard struct {
+	throttleRepo repository.LoginThrottleRepository
+	eventRepo    repository.LoginEventRepository
+	policy       domain_service.LoginThrottlePolicy
+}
+
+func NewLoginGuard(
+	throttleRepo repository.LoginThrottleRepository,
+	eventRepo repository.LoginEventRepository,
+) *LoginGuard {
+	return &LoginGuard{
+		throttleRepo: throttleRepo,
+		eventRepo:    eventRepo,
+	}
+}
+
+// Check returns a *LoginThrottledError if the account or the client IP is locked
+func (g *LoginGuard) Check(ctx context.Context, email string, client ClientInfo) error {
+	now := time.Now()
+	var retryAfter time.Duration
+	for scope, key := range throttleKeys(email, client) {
+		lockedUntil, err := g.throttleRepo.LockedUntil(ctx, scope, key)
+		if err != nil {
+			return err
+		}
+		if wait := lockedUntil.Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure counts the failure against the account and the IP, locking
+// them when the policy says so, and records the event.
+func (g *LoginGuard) RecordFailure(ctx context.Context, userID *uuid.UUID, email string, client ClientInfo, reason entity.LoginFailureReason) {
+	// Throttled attempts are not counted again, so a lock does not extend itself
+	if reason != entity.LoginFailureThrottled {
+		now := time.Now()
+		for scope, key := range throttleKeys(email, client) {
+			failures, err := g.throttleRepo.RecordFailure(ctx, scope, key, now, now.Add(-g.policy.Window(scope)))
+			if err != nil {
+				log.Printf("login guard: %v", err)
+				continue
+			}
+			if lock := g.policy.LockDuration(scope, failures); lock > 0 {
+				if err := g.throttleRepo.Lock(ctx, scope, key, now.Add(lock)); err != nil {
+					log.Printf("login guard: %v", err)
+				}
+			}
+		}
+	}
+
+	g.recordEvent(ctx, entity.NewLoginEvent(userID, email, client.IPAddress, client.UserAgent, &reason))
+}
+
+// RecordSuccess clears the account's failure counter and records the event.
+// The IP counter is kept so that an attacker cannot reset it with their own account.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, userID uuid.UUID, email string, client ClientInfo) {
+	if err := g.throttleRepo.Reset(ctx, entity.LoginThrottleScopeAccount, email); err != nil {
+		log.Printf("login guard: %v", err)
+	}
+
+	g.recordEvent(ctx, entity.NewLoginEvent(&userID, email, client.IPAddress, client.UserAgent, nil))
+}
+
+// ListEvents returns the user's recent login events
+func (g *LoginGuard) ListEvents(ctx context.Context, userID uuid.UUID) ([]*LoginEventResponse, error) {
+	events, err := g.eventRepo.FindByUserID(ctx, userID, loginEventsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login events: %w", err)
+	}
+
+	responses := make([]*LoginEventResponse, 0, len(events))
+	for _, event := range events {
+		response := &LoginEventResponse{
+			Success:   event.Success,
+			IPAddress: event.IPAddress,
+			UserAgent: event.UserAgent,
+			CreatedAt: event.CreatedAt.Format(time.RFC3339),
+		}
+		if event.FailureReason != nil {
+			response.FailureReason = string(*event.FailureReason)
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+func (g *LoginGuard) recordEvent(ctx context.Context, event *entity.LoginEvent) {
+	if err := g.eventRepo.Create(ctx, event); err != nil {
+		log.Printf("login guard: %v", err)
+	}
+}
+
+func throttleKeys(email string, client ClientInfo) map[entity.LoginThrottleScope]string {
+	keys := map[entity.LoginThrottleScope]string{
+		entity.LoginThrottleScopeAccount: email,
+	}
+	if client.IPAddress != "" {
+		keys[entity.LoginThrottleScopeIP] = client.IPAddress
+	}
+	return keys
+}
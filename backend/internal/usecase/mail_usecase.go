@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+	"noroi/internal/gateway"
+	"noroi/internal/repository"
+	"time"
+)
+
+const (
+	// mailClaimBatchSize is the number of outbox rows claimed per dispatch round
+	mailClaimBatchSize = 20
+	// mailClaimLease is how long a claimed mail stays invisible to other workers
+	mailClaimLease = 5 * time.Minute
+	// mailSendTimeout bounds a single delivery attempt
+	mailSendTimeout = time.Minute
+)
+
+// MailUsecase renders localized mails into the persistent outbox and delivers
+// them in the background, so request handlers never wait on the SMTP server.
+type MailUsecase struct {
+	outboxRepo     repository.MailOutboxRepository
+	preferenceRepo repository.UserPreferenceRepository
+	renderer       gateway.MailRenderer
+	mailer         gateway.Mailer
+}
+
+func NewMailUsecase(
+	outboxRepo repository.MailOutboxRepository,
+	preferenceRepo repository.UserPreferenceRepository,
+	renderer gateway.MailRenderer,
+	mailer gateway.Mailer,
+) *MailUsecase {
+	return &MailUsecase{
+		outboxRepo:     outboxRepo,
+		preferenceRepo: preferenceRepo,
+		renderer:       renderer,
+		mailer:         mailer,
+	}
+}
+
+// EnqueueToUser queues a mail to the user's address in the language they chose.
+func (uc *MailUsecase) EnqueueToUser(ctx context.Context, user *entity.User, template gateway.MailTemplate, data any) error {
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	return uc.enqueue(ctx, nil, user.Email, preferences.Locale, template, data)
+}
+
+// Enqueue renders template for the recipient's locale and stores it for delivery.
+func (uc *MailUsecase) Enqueue(ctx context.Context, to value.Email, locale value.Locale, template gateway.MailTemplate, data any) error {
+	return uc.enqueue(ctx, nil, to, locale, template, data)
+}
+
+// EnqueueOnce is Enqueue for callers that may retry: only the first mail
+// enqueued with dedupKey is kept.
+func (uc *MailUsecase) EnqueueOnce(ctx context.Context, dedupKey string, to value.Email, locale value.Locale, template gateway.MailTemplate, data any) error {
+	return uc.enqueue(ctx, &dedupKey, to, locale, template, data)
+}
+
+func (uc *MailUsecase) enqueue(ctx context.Context, dedupKey *string, to value.Email, locale value.Locale, template gateway.MailTemplate, data any) error {
+	if locale.Validate() != nil {
+		locale = value.DefaultLocale
+	}
+
+	rendered, err := uc.renderer.Render(template, locale, data)
+	if err != nil {
+		return fmt.Errorf("failed to render mail: %w", err)
+	}
+
+	mail := entity.NewOutboundMail(to.String(), string(template), rendered.Subject, rendered.TextBody, rendered.HTMLBody)
+	mail.DedupKey = dedupKey
+	if err := uc.outboxRepo.Enqueue(ctx, mail); err != nil {
+		return fmt.Errorf("failed to enqueue mail: %w", err)
+	}
+
+	return nil
+}
+
+// DeliverDue sends one batch of due outbox mails and returns how many were claimed.
+func (uc *MailUsecase) DeliverDue(ctx context.Context) (int, error) {
+	mails, err := uc.outboxRepo.ClaimDue(ctx, mailClaimBatchSize, mailClaimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, mail := range mails {
+		sendCtx, cancel := context.WithTimeout(ctx, mailSendTimeout)
+		err := uc.mailer.Send(sendCtx, &gateway.Mail{
+			To:       mail.To,
+			Subject:  mail.Subject,
+			TextBody: mail.TextBody,
+			HTMLBody: mail.HTMLBody,
+		})
+		cancel()
+
+		if err != nil {
+			log.Printf("mail: attempt %d for %s (%s) failed: %v", mail.Attempts, mail.ID, mail.Template, err)
+			mail.MarkFailed(err.Error())
+		} else {
+			mail.MarkSent()
+		}
+
+		if err := uc.outboxRepo.Update(ctx, mail); err != nil {
+			log.Printf("mail: %v", err)
+		}
+	}
+
+	return len(mails), nil
+}
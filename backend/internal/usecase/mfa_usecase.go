@@ -0,0 +1,304 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"noroi/internal/domain/domain_service"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"noroi/pkg/token"
+	"noroi/pkg/totp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// mfaIssuer is the account issuer shown in authenticator apps
+const mfaIssuer = "Noroi"
+
+type MFAUsecase struct {
+	userRepo     repository.UserRepository
+	mfaRepo      repository.MFARepository
+	throttleRepo repository.LoginThrottleRepository
+	policy       domain_service.LoginThrottlePolicy
+}
+
+func NewMFAUsecase(
+	userRepo repository.UserRepository,
+	mfaRepo repository.MFARepository,
+	throttleRepo repository.LoginThrottleRepository,
+) *MFAUsecase {
+	return &MFAUsecase{
+		userRepo:     userRepo,
+		mfaRepo:      mfaRepo,
+		throttleRepo: throttleRepo,
+	}
+}
+
+type MFACodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type EnrollMFAInput struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type DisableMFAInput struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type RegenerateRecoveryCodesInput struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFAStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+type MFAEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Status reports whether 2FA is enabled for the user
+func (uc *MFAUsecase) Status(ctx context.Context, userID uuid.UUID) (*MFAStatusResponse, error) {
+	enabled, err := uc.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return &MFAStatusResponse{}, nil
+	}
+
+	remaining, err := uc.mfaRepo.CountUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAStatusResponse{Enabled: true, RecoveryCodesRemaining: remaining}, nil
+}
+
+// IsEnabled reports whether the user has confirmed a TOTP secret
+func (uc *MFAUsecase) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	mfa, err := uc.mfaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if err == errors.ErrMFANotEnrolled {
+			return false, nil
+		}
+		return false, err
+	}
+	return mfa.IsEnabled(), nil
+}
+
+// Enroll starts enrolment with a new secret. 2FA is not enforced until the
+// user confirms a code generated from it. The password is required so that an
+// access token alone cannot replace the second factor.
+func (uc *MFAUsecase) Enroll(ctx context.Context, userID uuid.UUID, input EnrollMFAInput) (*MFAEnrollmentResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.Password.Compare(input.Password) {
+		return nil, errors.ErrInvalidCredentials
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.mfaRepo.SavePending(ctx, entity.NewUserMFA(user.ID, secret)); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(mfaIssuer, user.Email.String(), secret),
+	}, nil
+}
+
+// Confirm enables 2FA once the user proves their authenticator works, and
+// returns the recovery codes. They are shown only this once.
+func (uc *MFAUsecase) Confirm(ctx context.Context, userID uuid.UUID, input MFACodeInput) (*MFARecoveryCodesResponse, error) {
+	mfa, err := uc.mfaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.IsEnabled() {
+		return nil, errors.ErrMFAAlreadyEnabled
+	}
+
+	if err := uc.checkThrottle(ctx, userID); err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(mfa.Secret, input.Code, time.Now())
+	if !ok {
+		uc.recordFailure(ctx, userID)
+		return nil, errors.ErrInvalidMFACode
+	}
+	uc.resetFailures(ctx, userID)
+
+	plain, codes, err := generateRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.mfaRepo.Enable(ctx, userID, step, codes); err != nil {
+		return nil, err
+	}
+
+	return &MFARecoveryCodesResponse{RecoveryCodes: plain}, nil
+}
+
+// Disable turns 2FA off. It requires both the password and a current code.
+func (uc *MFAUsecase) Disable(ctx context.Context, userID uuid.UUID, input DisableMFAInput) error {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.Password.Compare(input.Password) {
+		return errors.ErrInvalidCredentials
+	}
+
+	if err := uc.Verify(ctx, userID, input.Code); err != nil {
+		return err
+	}
+
+	return uc.mfaRepo.Disable(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes. Like Disable it requires
+// both the password and a current code.
+func (uc *MFAUsecase) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, input RegenerateRecoveryCodesInput) (*MFARecoveryCodesResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.Password.Compare(input.Password) {
+		return nil, errors.ErrInvalidCredentials
+	}
+
+	if err := uc.Verify(ctx, userID, input.Code); err != nil {
+		return nil, err
+	}
+
+	plain, codes, err := generateRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.mfaRepo.ReplaceRecoveryCodes(ctx, userID, codes); err != nil {
+		return nil, err
+	}
+
+	return &MFARecoveryCodesResponse{RecoveryCodes: plain}, nil
+}
+
+// Verify accepts a current TOTP code or an unused recovery code. Each TOTP
+// code and each recovery code is accepted only once. Wrong codes are counted
+// per user and lock further attempts with a *LoginThrottledError.
+func (uc *MFAUsecase) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+	if err := uc.checkThrottle(ctx, userID); err != nil {
+		return err
+	}
+
+	err := uc.verify(ctx, userID, code)
+	switch err {
+	case nil:
+		uc.resetFailures(ctx, userID)
+	case errors.ErrInvalidMFACode:
+		uc.recordFailure(ctx, userID)
+	}
+	return err
+}
+
+func (uc *MFAUsecase) verify(ctx context.Context, userID uuid.UUID, code string) error {
+	mfa, err := uc.mfaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if err == errors.ErrMFANotEnrolled {
+			return errors.ErrMFANotEnabled
+		}
+		return err
+	}
+	if !mfa.IsEnabled() {
+		return errors.ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(mfa.Secret, code, time.Now())
+		if !ok {
+			return errors.ErrInvalidMFACode
+		}
+		fresh, err := uc.mfaRepo.UseStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return errors.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	used, err := uc.mfaRepo.UseRecoveryCode(ctx, userID, token.HashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// checkThrottle returns a *LoginThrottledError while code entry is locked for the user
+func (uc *MFAUsecase) checkThrottle(ctx context.Context, userID uuid.UUID) error {
+	lockedUntil, err := uc.throttleRepo.LockedUntil(ctx, entity.LoginThrottleScopeMFA, userID.String())
+	if err != nil {
+		return err
+	}
+	if wait := time.Until(lockedUntil); wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+func (uc *MFAUsecase) recordFailure(ctx context.Context, userID uuid.UUID) {
+	now := time.Now()
+	scope := entity.LoginThrottleScopeMFA
+	failures, err := uc.throttleRepo.RecordFailure(ctx, scope, userID.String(), now, now.Add(-uc.policy.Window(scope)))
+	if err != nil {
+		log.Printf("mfa throttle: %v", err)
+		return
+	}
+	if lock := uc.policy.LockDuration(scope, failures); lock > 0 {
+		if err := uc.throttleRepo.Lock(ctx, scope, userID.String(), now.Add(lock)); err != nil {
+			log.Printf("mfa throttle: %v", err)
+		}
+	}
+}
+
+func (uc *MFAUsecase) resetFailures(ctx context.Context, userID uuid.UUID) {
+	if err := uc.throttleRepo.Reset(ctx, entity.LoginThrottleScopeMFA, userID.String()); err != nil {
+		log.Printf("mfa throttle: %v", err)
+	}
+}
+
+func generateRecoveryCodes(userID uuid.UUID) ([]string, []*entity.MFARecoveryCode, error) {
+	plain := make([]string, 0, entity.MFARecoveryCodeCount)
+	codes := make([]*entity.MFARecoveryCode, 0, entity.MFARecoveryCodeCount)
+	for i := 0; i < entity.MFARecoveryCodeCount; i++ {
+		code, hash, err := token.GenerateRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		plain = append(plain, code)
+		codes = append(codes, entity.NewMFARecoveryCode(userID, hash))
+	}
+	return plain, codes, nil
+}
@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"noroi/internal/domain/entity"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// notificationQueueSize is the number of pending notifications buffered in memory
+	notificationQueueSize = 256
+	// notificationBatchSize is the page size used when broadcasting to many users
+	notificationBatchSize = 500
+)
+
+type NotificationKind string
+
+const (
+	NotificationKindCurse       NotificationKind = "curse"        // 投稿に怨念された
+	NotificationKindRitualStart NotificationKind = "ritual_start" // 丑三つ時の儀式開始
+	NotificationKindReminder    NotificationKind = "reminder"     // 応募のリマインダー
+)
+
+// Notification is a user-facing event routed through the notification pipeline.
+type Notification struct {
+	UserID uuid.UUID
+	Kind   NotificationKind
+	Title  string
+	Body   string
+	URL    string
+	Tag    string // optional: collapses repeats of the same event (at most 32 URL-safe base64 characters); defaults to Kind
+}
+
+// Notifier is one delivery channel of the notification pipeline (Web Push, ...).
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// NotificationUsecase receives notifications from other use cases, applies the
+// recipient's notification settings and quiet hours, and fans them out to
+// every Notifier.
+// Delivery happens on a background worker so request handlers never wait on
+// external push services.
+type NotificationUsecase struct {
+	userRepo         repository.UserRepository
+	notificationRepo repository.NotificationRepository
+	preferenceRepo   repository.UserPreferenceRepository
+	notifiers        []Notifier
+	queue            chan *Notification
+}
+
+func NewNotificationUsecase(
+	userRepo repository.UserRepository,
+	notificationRepo repository.NotificationRepository,
+	preferenceRepo repository.UserPreferenceRepository,
+	notifiers ...Notifier,
+) *NotificationUsecase {
+	return &NotificationUsecase{
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
+		notifiers:        notifiers,
+		queue:            make(chan *Notification, notificationQueueSize),
+	}
+}
+
+// Publish enqueues a notification without blocking. Notifications are
+// best-effort: if the queue is full the notification is dropped and logged.
+func (uc *NotificationUsecase) Publish(n *Notification) {
+	select {
+	case uc.queue <- n:
+	default:
+		log.Printf("notification: queue full, dropping %s notification for user %s", n.Kind, n.UserID)
+	}
+}
+
+// PublishToInbox stores n in the recipient's inbox, once per key, and then
+// publishes it for push. Unlike Publish, the notification is not lost when
+// the queue is full; the returned error means it was not stored.
+func (uc *NotificationUsecase) PublishToInbox(ctx context.Context, key string, n *Notification) error {
+	inbox := entity.NewInboxNotification(n.UserID, string(n.Kind), n.Title, n.Body, n.URL, &key)
+	if err := uc.notificationRepo.SaveInbox(ctx, inbox); err != nil {
+		return err
+	}
+	uc.Publish(n)
+	return nil
+}
+
+// InboxNotificationResponse is one notification in the user's inbox
+type InboxNotificationResponse struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	URL       string `json:"url"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ListInbox returns the user's inbox, newest first
+func (uc *NotificationUsecase) ListInbox(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*InboxNotificationResponse, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	notifications, err := uc.notificationRepo.FindInbox(ctx, userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*InboxNotificationResponse, 0, len(notifications))
+	for _, n := range notifications {
+		responses = append(responses, &InboxNotificationResponse{
+			ID:        n.ID.String(),
+			Kind:      n.Kind,
+			Title:     n.Title,
+			Body:      n.Body,
+			URL:       n.URL,
+			CreatedAt: n.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return responses, nil
+}
+
+// Run processes queued notifications until ctx is cancelled.
+func (uc *NotificationUsecase) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-uc.queue:
+			if err := uc.dispatch(ctx, n); err != nil {
+				log.Printf("notification: %v", err)
+			}
+		}
+	}
+}
+
+// AnnounceRitualStart notifies every user who opted in to ritual notifications.
+// The broadcast is claimed in the database first so that it is sent once per
+// ritual even when several replicas run the scheduler.
+func (uc *NotificationUsecase) AnnounceRitualStart(ctx context.Context, startTime time.Time) error {
+	claimed, err := uc.notificationRepo.ClaimBroadcast(ctx, "ritual_start:"+startTime.Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	n := Notification{
+		Kind:  NotificationKindRitualStart,
+		Title: "丑三つ時の儀式が始まりました",
+		Body:  "今宵の呪いに参加しましょう。儀式は1時間で終わります。",
+		URL:   "/ritual",
+	}
+
+	for offset := 0; ; offset += notificationBatchSize {
+		userIDs, err := uc.userRepo.FindIDsForRitualNotification(ctx, offset, notificationBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to find ritual notification recipients: %w", err)
+		}
+
+		for _, userID := range userIDs {
+			recipient := n
+			recipient.UserID = userID
+			if err := uc.deliver(ctx, &recipient); err != nil {
+				log.Printf("notification: %v", err)
+			}
+		}
+
+		if len(userIDs) < notificationBatchSize {
+			return nil
+		}
+	}
+}
+
+func (uc *NotificationUsecase) dispatch(ctx context.Context, n *Notification) error {
+	user, err := uc.userRepo.FindByID(ctx, n.UserID)
+	if err != nil {
+		if err == errors.ErrUserNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to find notification recipient: %w", err)
+	}
+
+	if !wantsNotification(user, n.Kind) {
+		return nil
+	}
+
+	return uc.deliver(ctx, n)
+}
+
+// deliver fans n out unless the recipient is in their quiet hours. Notifications
+// are best-effort, so one arriving during quiet hours is dropped.
+func (uc *NotificationUsecase) deliver(ctx context.Context, n *Notification) error {
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, n.UserID)
+	if err != nil {
+		return err
+	}
+	if preferences.InQuietHours(time.Now()) {
+		return nil
+	}
+
+	uc.fanOut(ctx, n)
+	return nil
+}
+
+func (uc *NotificationUsecase) fanOut(ctx context.Context, n *Notification) {
+	for _, notifier := range uc.notifiers {
+		if err := notifier.Notify(ctx, n); err != nil {
+			log.Printf("notification: failed to deliver %s notification to user %s: %v", n.Kind, n.UserID, err)
+		}
+	}
+}
+
+func wantsNotification(user *entity.User, kind NotificationKind) bool {
+	switch kind {
+	case NotificationKindCurse:
+		return user.NotifyCurse
+	case NotificationKindRitualStart:
+		return user.NotifyRitual
+	default:
+		return true
+	}
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+	"noroi/internal/gateway"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"noroi/pkg/token"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// passwordResetRequestTimeout bounds the background work of a forgot-password request
+	passwordResetRequestTimeout = 30 * time.Second
+	// passwordResetQueueSize is the number of forgot-password requests buffered in memory
+	passwordResetQueueSize = 256
+)
+
+type PasswordResetUsecase struct {
+	userRepo    repository.UserRepository
+	resetRepo   repository.PasswordResetRepository
+	sessionRepo repository.SessionRepository
+	mails       *MailUsecase
+	requests    chan value.Email
+}
+
+func NewPasswordResetUsecase(
+	userRepo repository.UserRepository,
+	resetRepo repository.PasswordResetRepository,
+	sessionRepo repository.SessionRepository,
+	mails *MailUsecase,
+) *PasswordResetUsecase {
+	return &PasswordResetUsecase{
+		userRepo:    userRepo,
+		resetRepo:   resetRepo,
+		sessionRepo: sessionRepo,
+		mails:       mails,
+		requests:    make(chan value.Email, passwordResetQueueSize),
+	}
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ForgotPassword sends a reset link if the email belongs to an account.
+// The lookup and mail happen on the background worker (Run) and every outcome
+// looks the same to the caller, so neither the response nor its timing reveals
+// whether the email is registered. When the queue is full the request is
+// dropped and logged; the user can ask again.
+func (uc *PasswordResetUsecase) ForgotPassword(ctx context.Context, input ForgotPasswordInput) error {
+	email, err := value.NewEmail(input.Email)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case uc.requests <- email:
+	default:
+		log.Printf("password reset: queue full, dropping request")
+	}
+
+	return nil
+}
+
+// Run processes queued forgot-password requests one at a time until ctx is cancelled.
+func (uc *PasswordResetUsecase) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case email := <-uc.requests:
+			requestCtx, cancel := context.WithTimeout(ctx, passwordResetRequestTimeout)
+			if err := uc.sendResetMail(requestCtx, email); err != nil {
+				log.Printf("failed to issue password reset: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+func (uc *PasswordResetUsecase) sendResetMail(ctx context.Context, email value.Email) error {
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if err == errors.ErrUserNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	issued, err := uc.resetRepo.CountSince(ctx, user.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if issued >= entity.PasswordResetMaxPerHour {
+		log.Printf("password reset throttled for user %s", user.ID)
+		return nil
+	}
+
+	plain, hash, err := token.Generate()
+	if err != nil {
+		return err
+	}
+	if err := uc.resetRepo.Create(ctx, entity.NewPasswordResetToken(user.ID, hash)); err != nil {
+		return err
+	}
+
+	data := struct {
+		Username         string
+		Token            string
+		ExpiresInMinutes int
+	}{
+		Username:         user.Username,
+		Token:            plain,
+		ExpiresInMinutes: int(entity.PasswordResetTokenLifetime / time.Minute),
+	}
+	return uc.mails.EnqueueToUser(ctx, user, gateway.MailTemplatePasswordReset, data)
+}
+
+// ResetPassword sets a new password using a reset token. The token can be used
+// once; afterwards every session of the user is revoked.
+func (uc *PasswordResetUsecase) ResetPassword(ctx context.Context, input ResetPasswordInput) error {
+	resetToken, err := uc.resetRepo.FindByHash(ctx, token.Hash(input.Token))
+	if err != nil {
+		return err
+	}
+	if !resetToken.IsUsable(time.Now()) {
+		return errors.ErrInvalidToken
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, resetToken.UserID)
+	if err != nil {
+		if err == errors.ErrUserNotFound {
+			return errors.ErrInvalidToken
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	// The token is left untouched when the new password is rejected
+	password, err := value.NewPassword(input.Password, user.Email.String(), user.Username, user.Handle.String())
+	if err != nil {
+		return err
+	}
+
+	user.ChangePassword(password)
+	consumed, err := uc.resetRepo.Consume(ctx, resetToken, user)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return errors.ErrInvalidToken
+	}
+
+	if err := uc.sessionRepo.RevokeAllByUserID(ctx, user.ID, uuid.Nil, entity.SessionRevokeReasonPasswordReset); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
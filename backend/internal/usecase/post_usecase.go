@@ -3,9 +3,11 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"noroi/internal/domain/domain_service"
 	"noroi/internal/domain/entity"
 	"noroi/internal/domain/value"
 	"noroi/internal/repository"
+	"noroi/pkg/errors"
 	"sync"
 
 	"github.com/google/uuid"
@@ -17,6 +19,7 @@ type PostUsecase struct {
 	curseRepo      repository.CurseRepository
 	userRepo       repository.UserRepository
 	curseStyleRepo repository.CurseStyleRepository
+	participation  domain_service.ParticipationPolicy
 }
 
 func NewPostUsecase(
@@ -36,6 +39,7 @@ func NewPostUsecase(
 type CreatePostInput struct {
 	Content     string `json:"content"`
 	IsAnonymous bool   `json:"is_anonymous"`
+	PostType    string `json:"post_type"` // "normal"（省略時）または "ritual"
 }
 
 type UpdatePostInput struct {
@@ -46,6 +50,7 @@ type PostResponse struct {
 	ID           string `json:"id"`
 	UserID       string `json:"user_id"`
 	Username     string `json:"username"`
+	Handle       string `json:"handle,omitempty"`
 	Content      string `json:"content"`
 	PostType     string `json:"post_type"`
 	IsAnonymous  bool   `json:"is_anonymous"`
@@ -132,10 +137,8 @@ func (uc *PostUsecase) GetTimeline(ctx context.Context, currentUserID uuid.UUID,
 	// ========================================
 	responses := make([]*PostResponse, 0, len(postsWithUser))
 	for _, pwu := range postsWithUser {
-		username := pwu.User.Username
-		if pwu.Post.IsAnonymous {
-			username = "匿名"
-		}
+		// 匿名投稿・削除されたユーザーの投稿は名前を伏せる
+		username := pwu.Post.AuthorDisplayName(pwu.User)
 
 		// 呪癖スタイル情報を取得
 		style := styleMap[pwu.User.CurseStyleID]
@@ -144,6 +147,7 @@ func (uc *PostUsecase) GetTimeline(ctx context.Context, currentUserID uuid.UUID,
 			ID:           pwu.Post.ID.String(),
 			UserID:       pwu.Post.UserID.String(),
 			Username:     username,
+			Handle:       pwu.Post.AuthorHandle(pwu.User),
 			Content:      pwu.Post.Content.String(),
 			PostType:     string(pwu.Post.PostType),
 			IsAnonymous:  pwu.Post.IsAnonymous,
@@ -173,10 +177,24 @@ func (uc *PostUsecase) CreatePost(ctx context.Context, userID uuid.UUID, input C
 		return nil, err
 	}
 
+	// Ritual posts are how users join the ritual; only verified, named users may join
+	postType := entity.PostTypeNormal
+	if input.PostType != "" {
+		postType = entity.PostType(input.PostType)
+	}
+	if postType == entity.PostTypeRitual {
+		if err := uc.participation.CanJoinRitual(user); err != nil {
+			return nil, err
+		}
+		if input.IsAnonymous {
+			return nil, errors.ErrAnonymousCannotJoin
+		}
+	}
+
 	// Create post entity with username
-	post, err := entity.NewPost(userID, user.Username, content, entity.PostTypeNormal, input.IsAnonymous)
+	post, err := entity.NewPost(userID, user.Username, content, postType, input.IsAnonymous)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create post entity: %w", err)
+		return nil, err
 	}
 
 	// Save to database
@@ -185,15 +203,13 @@ func (uc *PostUsecase) CreatePost(ctx context.Context, userID uuid.UUID, input C
 	}
 
 	// Display username based on anonymity setting
-	displayUsername := user.Username
-	if input.IsAnonymous {
-		displayUsername = "匿名"
-	}
+	displayUsername := post.AuthorDisplayName(user)
 
 	return &PostResponse{
 		ID:           post.ID.String(),
 		UserID:       post.UserID.String(),
 		Username:     displayUsername,
+		Handle:       post.AuthorHandle(user),
 		Content:      post.Content.String(),
 		PostType:     string(post.PostType),
 		IsAnonymous:  post.IsAnonymous,
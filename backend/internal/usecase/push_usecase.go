@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"noroi/internal/domain/entity"
+	"noroi/internal/gateway"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// pushMaxAttempts is the number of send attempts per subscription for temporary failures
+	pushMaxAttempts = 3
+	// pushRetryBaseDelay is the first retry delay; it doubles on each attempt
+	pushRetryBaseDelay = 500 * time.Millisecond
+	// pushDefaultTTL is how long the push service keeps an undelivered message
+	pushDefaultTTL = 6 * time.Hour
+)
+
+type PushUsecase struct {
+	subRepo repository.PushSubscriptionRepository
+	sender  gateway.PushSender // nil when VAPID keys are not configured
+}
+
+func NewPushUsecase(
+	subRepo repository.PushSubscriptionRepository,
+	sender gateway.PushSender,
+) *PushUsecase {
+	return &PushUsecase{
+		subRepo: subRepo,
+		sender:  sender,
+	}
+}
+
+type PushSubscriptionInput struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
+}
+
+type PushSubscriptionResponse struct {
+	ID        string `json:"id"`
+	Endpoint  string `json:"endpoint"`
+	CreatedAt string `json:"created_at"`
+}
+
+// pushPayload is the JSON document handed to the service worker's push event
+type pushPayload struct {
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+	Tag   string `json:"tag,omitempty"`
+}
+
+func (uc *PushUsecase) PublicKey() (string, error) {
+	if uc.sender == nil {
+		return "", errors.ErrPushDisabled
+	}
+	return uc.sender.PublicKey(), nil
+}
+
+func (uc *PushUsecase) Subscribe(ctx context.Context, userID uuid.UUID, input PushSubscriptionInput, userAgent string) (*PushSubscriptionResponse, error) {
+	if uc.sender == nil {
+		return nil, errors.ErrPushDisabled
+	}
+
+	sub, err := entity.NewPushSubscription(userID, input.Endpoint, input.Keys.P256dh, input.Keys.Auth, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.sender.ValidateEndpoint(sub.Endpoint); err != nil {
+		return nil, err
+	}
+
+	if err := uc.subRepo.Upsert(ctx, sub); err != nil {
+		return nil, fmt.Errorf("failed to save push subscription: %w", err)
+	}
+
+	return &PushSubscriptionResponse{
+		ID:        sub.ID.String(),
+		Endpoint:  sub.Endpoint,
+		CreatedAt: sub.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (uc *PushUsecase) Unsubscribe(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	if err := uc.subRepo.DeleteByUserAndEndpoint(ctx, userID, endpoint); err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	return nil
+}
+
+// Notify implements Notifier by pushing the notification to every browser the user subscribed.
+func (uc *PushUsecase) Notify(ctx context.Context, n *Notification) error {
+	if uc.sender == nil {
+		return nil
+	}
+
+	subs, err := uc.subRepo.FindByUserID(ctx, n.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to find push subscriptions: %w", err)
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	tag := n.Tag
+	if tag == "" {
+		tag = string(n.Kind)
+	}
+
+	payload, err := json.Marshal(pushPayload{
+		Kind:  string(n.Kind),
+		Title: n.Title,
+		Body:  n.Body,
+		URL:   n.URL,
+		Tag:   tag,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode push payload: %w", err)
+	}
+
+	msg := &gateway.PushMessage{
+		Payload: payload,
+		TTL:     pushDefaultTTL,
+		Urgency: gateway.PushUrgencyNormal,
+		Topic:   tag,
+	}
+	if n.Kind == NotificationKindRitualStart {
+		// The ritual only lasts an hour; wake the device and drop the message once it is over.
+		msg.Urgency = gateway.PushUrgencyHigh
+		msg.TTL = time.Hour
+	}
+
+	for _, sub := range subs {
+		uc.deliver(ctx, sub, msg)
+	}
+
+	return nil
+}
+
+// deliver sends msg to one subscription, retrying temporary failures with
+// exponential backoff and pruning subscriptions the push service reports as gone.
+func (uc *PushUsecase) deliver(ctx context.Context, sub *entity.PushSubscription, msg *gateway.PushMessage) {
+	delay := pushRetryBaseDelay
+
+	var err error
+	for attempt := 1; attempt <= pushMaxAttempts; attempt++ {
+		err = uc.sender.Send(ctx, sub, msg)
+		if err == nil || !errors.Is(err, errors.ErrPushTemporaryFailure) || attempt == pushMaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	switch {
+	case err == nil:
+		if err := uc.subRepo.RecordDelivery(ctx, sub.ID, true); err != nil {
+			log.Printf("push: %v", err)
+		}
+	case errors.Is(err, errors.ErrPushSubscriptionExpired), errors.Is(err, errors.ErrInvalidPushSubscription):
+		if err := uc.subRepo.Delete(ctx, sub.ID); err != nil {
+			log.Printf("push: failed to prune subscription %s: %v", sub.ID, err)
+		}
+	default:
+		log.Printf("push: delivery to subscription %s failed: %v", sub.ID, err)
+		if err := uc.subRepo.RecordDelivery(ctx, sub.ID, false); err != nil {
+			log.Printf("push: %v", err)
+		}
+	}
+}
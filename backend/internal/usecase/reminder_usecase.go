@@ -0,0 +1,406 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"noroi/internal/domain/domain_service"
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+	"noroi/internal/gateway"
+	"noroi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// reminderClaimBatchSize is the number of due reminders claimed per dispatch
+	// round. A batch is delivered one by one, so reminderClaimBatchSize ×
+	// reminderDeliverTimeout must stay below entity.ReminderLease or the end of
+	// the batch could be claimed again by another worker.
+	reminderClaimBatchSize = 10
+	// reminderDeliverTimeout bounds a single delivery attempt
+	reminderDeliverTimeout = 10 * time.Second
+	// maxRemindersPerApplication bounds how many reminders one application can hold
+	maxRemindersPerApplication = 20
+)
+
+// ReminderDelivery is one firing of a reminder handed to its channel. Key is
+// the same for every attempt of the firing, so channels can drop duplicates.
+type ReminderDelivery struct {
+	Key           string
+	ReminderID    uuid.UUID
+	ApplicationID uuid.UUID
+	UserID        uuid.UUID
+	Email         string
+	CompanyName   string
+	Message       string
+	TargetAt      time.Time
+	Preferences   *entity.UserPreferences
+}
+
+// ReminderChannel delivers fired reminders for one value.ReminderChannel.
+type ReminderChannel interface {
+	Deliver(ctx context.Context, d *ReminderDelivery) error
+}
+
+// ReminderUsecase manages the reminders of an application and fires them from
+// a background worker. Several replicas can run the worker; each firing is
+// claimed once and its channels deduplicate by ReminderDelivery.Key.
+type ReminderUsecase struct {
+	appRepo        repository.ApplicationRepository
+	reminderRepo   repository.ReminderRepository
+	preferenceRepo repository.UserPreferenceRepository
+	channels       map[value.ReminderChannel]ReminderChannel
+	builder        domain_service.ReminderService
+}
+
+func NewReminderUsecase(
+	appRepo repository.ApplicationRepository,
+	reminderRepo repository.ReminderRepository,
+	preferenceRepo repository.UserPreferenceRepository,
+	channels map[value.ReminderChannel]ReminderChannel,
+) *ReminderUsecase {
+	return &ReminderUsecase{
+		appRepo:        appRepo,
+		reminderRepo:   reminderRepo,
+		preferenceRepo: preferenceRepo,
+		channels:       channels,
+	}
+}
+
+type CreateReminderInput struct {
+	TargetAt string // RFC3339, must be in the future
+	Channel  string // in_app (default) or email
+	Message  string
+}
+
+// SnoozeReminderInput sets either Minutes from now or an RFC3339 Until
+type SnoozeReminderInput struct {
+	Minutes int
+	Until   string
+}
+
+type ReminderResponse struct {
+	ID             string  `json:"id"`
+	ApplicationID  string  `json:"application_id"`
+	TargetAt       string  `json:"target_at"`
+	Channel        string  `json:"channel"`
+	Message        string  `json:"message"`
+	Status         string  `json:"status"`
+	NextFireAt     *string `json:"next_fire_at,omitempty"`
+	Attempts       int     `json:"attempts"`
+	FireCount      int     `json:"fire_count"`
+	LastError      *string `json:"last_error,omitempty"`
+	DeliveredAt    *string `json:"delivered_at,omitempty"`
+	AcknowledgedAt *string `json:"acknowledged_at,omitempty"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
+}
+
+func (uc *ReminderUsecase) Create(ctx context.Context, userID, appID uuid.UUID, input CreateReminderInput) (*ReminderResponse, error) {
+	app, err := uc.findApplication(ctx, userID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	targetAt, err := time.Parse(time.RFC3339, input.TargetAt)
+	if err != nil {
+		return nil, errors.New("invalid target_at format")
+	}
+	if !targetAt.After(time.Now()) {
+		return nil, errors.New("invalid target_at: must be in the future")
+	}
+
+	channel := value.ReminderChannelInApp
+	if input.Channel != "" {
+		channel = value.ReminderChannel(strings.ToLower(input.Channel))
+		if err := channel.Validate(); err != nil {
+			return nil, errors.New("invalid channel")
+		}
+	}
+
+	message := strings.TrimSpace(input.Message)
+	if err := entity.ValidateReminderMessage(message); err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.reminderRepo.FindByApplicationID(ctx, app.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxRemindersPerApplication {
+		return nil, errors.New("invalid reminder: too many reminders")
+	}
+
+	reminder := uc.builder.Build(*app, channel, message, targetAt.UTC())
+	if err := uc.reminderRepo.Create(ctx, &reminder); err != nil {
+		return nil, err
+	}
+
+	return toReminderResponse(&reminder), nil
+}
+
+func (uc *ReminderUsecase) List(ctx context.Context, userID, appID uuid.UUID) ([]*ReminderResponse, error) {
+	app, err := uc.findApplication(ctx, userID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders, err := uc.reminderRepo.FindByApplicationID(ctx, app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*ReminderResponse, 0, len(reminders))
+	for _, reminder := range reminders {
+		responses = append(responses, toReminderResponse(reminder))
+	}
+	return responses, nil
+}
+
+func (uc *ReminderUsecase) Delete(ctx context.Context, userID, appID, reminderID uuid.UUID) error {
+	reminder, err := uc.findReminder(ctx, userID, appID, reminderID)
+	if err != nil {
+		return err
+	}
+	return uc.reminderRepo.Delete(ctx, reminder.ID)
+}
+
+// Snooze fires the reminder again later. A reminder that has not fired yet is postponed.
+func (uc *ReminderUsecase) Snooze(ctx context.Context, userID, appID, reminderID uuid.UUID, input SnoozeReminderInput) (*ReminderResponse, error) {
+	reminder, err := uc.findReminder(ctx, userID, appID, reminderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var until time.Time
+	switch {
+	case input.Until != "" && input.Minutes != 0:
+		return nil, errors.New("invalid snooze: set either minutes or until")
+	case input.Until != "":
+		until, err = time.Parse(time.RFC3339, input.Until)
+		if err != nil {
+			return nil, errors.New("invalid until format")
+		}
+	case input.Minutes > 0:
+		until = now.Add(time.Duration(input.Minutes) * time.Minute)
+	default:
+		return nil, errors.New("invalid snooze: set either minutes or until")
+	}
+	if !until.After(now) || until.Sub(now) > entity.ReminderMaxSnooze {
+		return nil, errors.New("invalid snooze: must be in the future and within 7 days")
+	}
+
+	if err := reminder.Snooze(until); err != nil {
+		return nil, err
+	}
+	if err := uc.reminderRepo.Update(ctx, reminder); err != nil {
+		return nil, err
+	}
+
+	return toReminderResponse(reminder), nil
+}
+
+// Acknowledge marks the reminder as seen. A reminder that has not fired yet will not fire.
+func (uc *ReminderUsecase) Acknowledge(ctx context.Context, userID, appID, reminderID uuid.UUID) (*ReminderResponse, error) {
+	reminder, err := uc.findReminder(ctx, userID, appID, reminderID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminder.Acknowledge()
+	if err := uc.reminderRepo.Update(ctx, reminder); err != nil {
+		return nil, err
+	}
+
+	return toReminderResponse(reminder), nil
+}
+
+// DeliverDue fires one batch of due reminders and returns how many were claimed.
+// A reminder due during the user's quiet hours is deferred until they end.
+func (uc *ReminderUsecase) DeliverDue(ctx context.Context) (int, error) {
+	claimed, err := uc.reminderRepo.ClaimDue(ctx, reminderClaimBatchSize, entity.ReminderLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, due := range claimed {
+		reminder := due.Reminder
+		if err := uc.fire(ctx, due); err != nil {
+			log.Printf("reminder: attempt %d for %s (%s) failed: %v", reminder.Attempts, reminder.ID, reminder.Channel, err)
+			reminder.MarkFailed(err.Error())
+		}
+
+		// The claim is fenced: if the reminder was acknowledged, snoozed or
+		// claimed again meanwhile, that newer state wins
+		updated, err := uc.reminderRepo.UpdateClaimed(ctx, due)
+		if err != nil {
+			log.Printf("reminder: %v", err)
+		} else if !updated {
+			log.Printf("reminder: %s changed while it was being delivered; keeping the newer state", reminder.ID)
+		}
+	}
+
+	return len(claimed), nil
+}
+
+// fire delivers a claimed reminder, or defers it while the user is in quiet hours
+func (uc *ReminderUsecase) fire(ctx context.Context, due *repository.DueReminder) error {
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, due.UserID)
+	if err != nil {
+		return err
+	}
+	if now := time.Now(); preferences.InQuietHours(now) {
+		due.Reminder.Defer(preferences.QuietHoursEnd(now))
+		return nil
+	}
+
+	if err := uc.deliver(ctx, due, preferences); err != nil {
+		return err
+	}
+	due.Reminder.MarkDelivered()
+	return nil
+}
+
+func (uc *ReminderUsecase) deliver(ctx context.Context, due *repository.DueReminder, preferences *entity.UserPreferences) error {
+	channel, ok := uc.channels[due.Reminder.Channel]
+	if !ok {
+		return fmt.Errorf("no channel for %q", due.Reminder.Channel)
+	}
+
+	deliverCtx, cancel := context.WithTimeout(ctx, reminderDeliverTimeout)
+	defer cancel()
+
+	return channel.Deliver(deliverCtx, &ReminderDelivery{
+		Key:           due.Reminder.DeliveryKey(),
+		ReminderID:    due.Reminder.ID,
+		ApplicationID: due.Reminder.ApplicationID,
+		UserID:        due.UserID,
+		Email:         due.Email,
+		CompanyName:   due.CompanyName,
+		Message:       due.Reminder.Message,
+		TargetAt:      due.Reminder.TargetAt,
+		Preferences:   preferences,
+	})
+}
+
+func (uc *ReminderUsecase) findApplication(ctx context.Context, userID, appID uuid.UUID) (*entity.Application, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("user id is required")
+	}
+
+	app, err := uc.appRepo.FindByID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, errors.New("application not found")
+	}
+	if app.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+	return app, nil
+}
+
+func (uc *ReminderUsecase) findReminder(ctx context.Context, userID, appID, reminderID uuid.UUID) (*entity.Reminder, error) {
+	app, err := uc.findApplication(ctx, userID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminder, err := uc.reminderRepo.FindByID(ctx, reminderID)
+	if err != nil {
+		return nil, err
+	}
+	if reminder.ApplicationID != app.ID {
+		return nil, errors.New("reminder not found")
+	}
+	return reminder, nil
+}
+
+func toReminderResponse(reminder *entity.Reminder) *ReminderResponse {
+	response := &ReminderResponse{
+		ID:            reminder.ID.String(),
+		ApplicationID: reminder.ApplicationID.String(),
+		TargetAt:      reminder.TargetAt.UTC().Format(time.RFC3339),
+		Channel:       string(reminder.Channel),
+		Message:       reminder.Message,
+		Status:        string(reminder.Status),
+		Attempts:      reminder.Attempts,
+		FireCount:     reminder.FireCount,
+		LastError:     reminder.LastError,
+		CreatedAt:     reminder.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     reminder.UpdatedAt.Format(time.RFC3339),
+	}
+	if reminder.Status == entity.ReminderStatusPending {
+		next := reminder.NextAttemptAt.UTC().Format(time.RFC3339)
+		response.NextFireAt = &next
+	}
+	if reminder.DeliveredAt != nil {
+		deliveredAt := reminder.DeliveredAt.UTC().Format(time.RFC3339)
+		response.DeliveredAt = &deliveredAt
+	}
+	if reminder.AcknowledgedAt != nil {
+		acknowledgedAt := reminder.AcknowledgedAt.UTC().Format(time.RFC3339)
+		response.AcknowledgedAt = &acknowledgedAt
+	}
+	return response
+}
+
+// inAppReminderChannel stores the reminder in the user's inbox, once per
+// delivery key, and sends it as a push notification. The push tag is the
+// reminder ID, so a repeated attempt replaces the earlier notification instead
+// of stacking up.
+type inAppReminderChannel struct {
+	notifications *NotificationUsecase
+}
+
+func NewInAppReminderChannel(notifications *NotificationUsecase) ReminderChannel {
+	return &inAppReminderChannel{notifications: notifications}
+}
+
+func (c *inAppReminderChannel) Deliver(ctx context.Context, d *ReminderDelivery) error {
+	return c.notifications.PublishToInbox(ctx, d.Key, &Notification{
+		UserID: d.UserID,
+		Kind:   NotificationKindReminder,
+		Title:  d.CompanyName,
+		Body:   d.Message,
+		URL:    "/applications/" + d.ApplicationID.String(),
+		Tag:    strings.ReplaceAll(d.ReminderID.String(), "-", ""),
+	})
+}
+
+// emailReminderChannel queues the reminder mail; the outbox keeps one mail per delivery key.
+type emailReminderChannel struct {
+	mails *MailUsecase
+}
+
+func NewEmailReminderChannel(mails *MailUsecase) ReminderChannel {
+	return &emailReminderChannel{mails: mails}
+}
+
+func (c *emailReminderChannel) Deliver(ctx context.Context, d *ReminderDelivery) error {
+	to, err := value.NewEmail(d.Email)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	data := struct {
+		CompanyName   string
+		Message       string
+		TargetAt      string
+		ApplicationID string
+	}{
+		CompanyName:   d.CompanyName,
+		Message:       d.Message,
+		TargetAt:      d.TargetAt.In(d.Preferences.Location()).Format("2006-01-02 15:04 MST"),
+		ApplicationID: d.ApplicationID.String(),
+	}
+	return c.mails.EnqueueOnce(ctx, d.Key, to, d.Preferences.Locale, gateway.MailTemplateReminder, data)
+}
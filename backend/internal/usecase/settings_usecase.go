@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
+	"noroi/internal/repository"
+	"noroi/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+type SettingsUsecase struct {
+	userRepo       repository.UserRepository
+	preferenceRepo repository.UserPreferenceRepository
+}
+
+func NewSettingsUsecase(userRepo repository.UserRepository, preferenceRepo repository.UserPreferenceRepository) *SettingsUsecase {
+	return &SettingsUsecase{
+		userRepo:       userRepo,
+		preferenceRepo: preferenceRepo,
+	}
+}
+
+// QuietHoursInput is a daily "HH:MM"-"HH:MM" window in the user's time zone
+type QuietHoursInput struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// OptionalQuietHours distinguishes an omitted quiet_hours field from an
+// explicit null, which turns quiet hours off.
+type OptionalQuietHours struct {
+	Set   bool
+	Value *QuietHoursInput
+}
+
+func (o *OptionalQuietHours) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+// UpdateSettingsInput is a partial update: omitted fields are left unchanged
+type UpdateSettingsInput struct {
+	ProfilePublic   *bool              `json:"profile_public"`
+	NotifyCurse     *bool              `json:"notify_curse"`
+	NotifyRitual    *bool              `json:"notify_ritual"`
+	DigestFrequency *string            `json:"digest_frequency"`
+	QuietHours      OptionalQuietHours `json:"quiet_hours"`
+	Locale          *string            `json:"locale"`
+	Timezone        *string            `json:"timezone"`
+}
+
+type SettingsResponse struct {
+	ProfilePublic   bool              `json:"profile_public"`
+	NotifyCurse     bool              `json:"notify_curse"`
+	NotifyRitual    bool              `json:"notify_ritual"`
+	DigestFrequency string            `json:"digest_frequency"`
+	QuietHours      *value.QuietHours `json:"quiet_hours"`
+	Locale          string            `json:"locale"`
+	Timezone        string            `json:"timezone"`
+}
+
+func (uc *SettingsUsecase) GetSettings(ctx context.Context, userID uuid.UUID) (*SettingsResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSettingsResponse(user, preferences), nil
+}
+
+// UpdateSettings applies the fields present in input. Every field is
+// validated before anything is saved.
+func (uc *SettingsUsecase) UpdateSettings(ctx context.Context, userID uuid.UUID, input UpdateSettingsInput) (*SettingsResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Preferences
+	if input.DigestFrequency != nil {
+		frequency, err := value.NewDigestFrequency(*input.DigestFrequency)
+		if err != nil {
+			return nil, err
+		}
+		preferences.DigestFrequency = frequency
+	}
+	if input.QuietHours.Set {
+		if input.QuietHours.Value == nil {
+			preferences.QuietHours = nil
+		} else {
+			quietHours, err := value.NewQuietHours(input.QuietHours.Value.Start, input.QuietHours.Value.End)
+			if err != nil {
+				return nil, err
+			}
+			preferences.QuietHours = &quietHours
+		}
+	}
+	if input.Locale != nil {
+		locale := value.Locale(*input.Locale)
+		if err := locale.Validate(); err != nil {
+			return nil, errors.ErrInvalidLocale
+		}
+		preferences.Locale = locale
+	}
+	if input.Timezone != nil {
+		timezone, err := value.NewTimezone(*input.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		preferences.Timezone = timezone
+	}
+
+	// Columns on users
+	if input.ProfilePublic != nil {
+		user.SetProfilePublic(*input.ProfilePublic)
+	}
+	if input.NotifyCurse != nil || input.NotifyRitual != nil {
+		notifyCurse, notifyRitual := user.NotifyCurse, user.NotifyRitual
+		if input.NotifyCurse != nil {
+			notifyCurse = *input.NotifyCurse
+		}
+		if input.NotifyRitual != nil {
+			notifyRitual = *input.NotifyRitual
+		}
+		user.UpdateNotifications(notifyCurse, notifyRitual)
+	}
+
+	// Both stores are written together so a failure leaves nothing half-saved
+	if err := uc.preferenceRepo.SaveSettings(ctx, user, preferences); err != nil {
+		return nil, err
+	}
+
+	return toSettingsResponse(user, preferences), nil
+}
+
+func toSettingsResponse(user *entity.User, preferences *entity.UserPreferences) *SettingsResponse {
+	return &SettingsResponse{
+		ProfilePublic:   user.ProfilePublic,
+		NotifyCurse:     user.NotifyCurse,
+		NotifyRitual:    user.NotifyRitual,
+		DigestFrequency: string(preferences.DigestFrequency),
+		QuietHours:      preferences.QuietHours,
+		Locale:          string(preferences.Locale),
+		Timezone:        preferences.Timezone,
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"fmt"
 	"noroi/internal/domain/entity"
+	"noroi/internal/domain/value"
 	"noroi/internal/repository"
+	"noroi/pkg/errors"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -13,17 +17,20 @@ type UserUsecase struct {
 	userRepo       repository.UserRepository
 	curseStyleRepo repository.CurseStyleRepository
 	postRepo       repository.PostRepository
+	sessionRepo    repository.SessionRepository
 }
 
 func NewUserUsecase(
 	userRepo repository.UserRepository,
 	curseStyleRepo repository.CurseStyleRepository,
 	postRepo repository.PostRepository,
+	sessionRepo repository.SessionRepository,
 ) *UserUsecase {
 	return &UserUsecase{
 		userRepo:       userRepo,
 		curseStyleRepo: curseStyleRepo,
 		postRepo:       postRepo,
+		sessionRepo:    sessionRepo,
 	}
 }
 
@@ -34,6 +41,19 @@ type UpdateProfileInput struct {
 	CurseStyleID string `json:"curse_style_id"`
 }
 
+type ChangeHandleInput struct {
+	Handle string `json:"handle" binding:"required"`
+}
+
+type HandleResponse struct {
+	Handle       string  `json:"handle"`
+	NextChangeAt *string `json:"next_change_at,omitempty"`
+}
+
+type DeleteAccountInput struct {
+	Password string `json:"password" binding:"required"`
+}
+
 type CurseStyleResponse struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
@@ -57,15 +77,31 @@ type UserPostResponse struct {
 }
 
 type UserProfileResponse struct {
-	ID         string              `json:"id"`
-	Email      string              `json:"email"`
-	Username   string              `json:"username"`
-	Age        int                 `json:"age"`
-	Gender     string              `json:"gender"`
-	CurseStyle *CurseStyleResponse `json:"curse_style"`
-	Points     int                 `json:"points"`
-	Stats      *UserStatsResponse  `json:"stats"`
-	CreatedAt  string              `json:"created_at"`
+	ID            string              `json:"id"`
+	Email         string              `json:"email"`
+	Username      string              `json:"username"`
+	Handle        string              `json:"handle"`
+	Age           int                 `json:"age"`
+	Gender        string              `json:"gender"`
+	CurseStyle    *CurseStyleResponse `json:"curse_style"`
+	Points        int                 `json:"points"`
+	EmailVerified bool                `json:"email_verified"`
+	Role          string              `json:"role"`
+	ProfilePublic bool                `json:"profile_public"`
+	Stats         *UserStatsResponse  `json:"stats"`
+	CreatedAt     string              `json:"created_at"`
+}
+
+// PublicProfileResponse is a profile as seen by other users. For a private
+// profile only ID, Username and ProfilePublic are set.
+type PublicProfileResponse struct {
+	ID            string              `json:"id"`
+	Username      string              `json:"username"`
+	Handle        string              `json:"handle"`
+	ProfilePublic bool                `json:"profile_public"`
+	CurseStyle    *CurseStyleResponse `json:"curse_style,omitempty"`
+	Stats         *UserStatsResponse  `json:"stats,omitempty"`
+	CreatedAt     string              `json:"created_at,omitempty"`
 }
 
 func (uc *UserUsecase) GetProfile(ctx context.Context, userID uuid.UUID) (*UserProfileResponse, error) {
@@ -90,6 +126,7 @@ func (uc *UserUsecase) GetProfile(ctx context.Context, userID uuid.UUID) (*UserP
 		ID:       user.ID.String(),
 		Email:    user.Email.String(),
 		Username: user.Username,
+		Handle:   user.Handle.String(),
 		Age:      user.Age,
 		Gender:   string(user.Gender),
 		CurseStyle: &CurseStyleResponse{
@@ -98,7 +135,10 @@ func (uc *UserUsecase) GetProfile(ctx context.Context, userID uuid.UUID) (*UserP
 			NameEn:      curseStyle.NameEn,
 			Description: curseStyle.Description,
 		},
-		Points: user.Points,
+		Points:        user.Points,
+		EmailVerified: user.IsEmailVerified(),
+		Role:          user.Role.String(),
+		ProfilePublic: user.ProfilePublic,
 		Stats: &UserStatsResponse{
 			Posts:  posts,
 			Curses: curses,
@@ -146,16 +186,189 @@ func (uc *UserUsecase) UpdateProfile(ctx context.Context, userID uuid.UUID, inpu
 	}
 
 	return &UserResponse{
-		ID:           user.ID.String(),
-		Email:        user.Email.String(),
-		Username:     user.Username,
-		Age:          user.Age,
-		Gender:       string(user.Gender),
-		CurseStyleID: user.CurseStyleID.String(),
-		Points:       user.Points,
+		ID:            user.ID.String(),
+		Email:         user.Email.String(),
+		Username:      user.Username,
+		Handle:        user.Handle.String(),
+		Age:           user.Age,
+		Gender:        string(user.Gender),
+		CurseStyleID:  user.CurseStyleID.String(),
+		Points:        user.Points,
+		EmailVerified: user.IsEmailVerified(),
+		Role:          user.Role.String(),
 	}, nil
 }
 
+// GetPublicProfile returns another user's profile. Stats only count
+// non-anonymous posts so that anonymous posts cannot be attributed to the user.
+// Users always see their own profile in full.
+func (uc *UserUsecase) GetPublicProfile(ctx context.Context, viewerID, userID uuid.UUID) (*PublicProfileResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.publicProfile(ctx, viewerID, user)
+}
+
+// GetPublicProfileByHandle is GetPublicProfile looked up by @handle
+func (uc *UserUsecase) GetPublicProfileByHandle(ctx context.Context, viewerID uuid.UUID, rawHandle string) (*PublicProfileResponse, error) {
+	handle, err := value.NewHandle(rawHandle)
+	if err != nil {
+		// Generated and reserved handles are not accepted as input, but may still exist
+		handle = value.Handle(strings.ToLower(strings.TrimPrefix(rawHandle, "@")))
+	}
+	user, err := uc.userRepo.FindByHandle(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+	return uc.publicProfile(ctx, viewerID, user)
+}
+
+func (uc *UserUsecase) publicProfile(ctx context.Context, viewerID uuid.UUID, user *entity.User) (*PublicProfileResponse, error) {
+	response := &PublicProfileResponse{
+		ID:            user.ID.String(),
+		Username:      user.Username,
+		Handle:        user.Handle.String(),
+		ProfilePublic: user.ProfilePublic,
+	}
+	if !canViewProfile(user, viewerID) {
+		return response, nil
+	}
+
+	curseStyle, err := uc.curseStyleRepo.FindByID(ctx, user.CurseStyleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get curse style: %w", err)
+	}
+
+	posts, curses, days, err := uc.userRepo.GetPublicUserStats(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user stats: %w", err)
+	}
+
+	response.CurseStyle = &CurseStyleResponse{
+		ID:          curseStyle.ID.String(),
+		Name:        curseStyle.Name,
+		NameEn:      curseStyle.NameEn,
+		Description: curseStyle.Description,
+	}
+	response.Stats = &UserStatsResponse{
+		Posts:  posts,
+		Curses: curses,
+		Days:   days,
+	}
+	response.CreatedAt = user.CreatedAt.Format("2006-01-02T15:04:05Z07:00")
+	return response, nil
+}
+
+// GetPublicPosts returns the non-anonymous posts of a user whose profile the viewer may see
+func (uc *UserUsecase) GetPublicPosts(ctx context.Context, viewerID, userID uuid.UUID, offset, limit int) ([]*UserPostResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canViewProfile(user, viewerID) {
+		return nil, errors.ErrProfilePrivate
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	posts, err := uc.postRepo.FindPublicByUserID(ctx, user.ID, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user posts: %w", err)
+	}
+
+	responses := make([]*UserPostResponse, 0, len(posts))
+	for _, post := range posts {
+		responses = append(responses, &UserPostResponse{
+			ID:         post.ID.String(),
+			Content:    post.Content.String(),
+			CurseCount: post.CurseCount,
+			CreatedAt:  post.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	return responses, nil
+}
+
+func canViewProfile(user *entity.User, viewerID uuid.UUID) bool {
+	return user.ProfilePublic || user.ID == viewerID
+}
+
+// ChangeHandle renames the user's @handle, subject to the rename cooldown
+func (uc *UserUsecase) ChangeHandle(ctx context.Context, userID uuid.UUID, input ChangeHandleInput) (*HandleResponse, error) {
+	handle, err := value.NewHandle(input.Handle)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if handle == user.Handle {
+		return toHandleResponse(user), nil
+	}
+
+	// Check first for a clear error; the unique index still decides races
+	if owner, err := uc.userRepo.FindByHandle(ctx, handle); err == nil && owner.ID != user.ID {
+		return nil, errors.ErrHandleTaken
+	} else if err != nil && err != errors.ErrUserNotFound {
+		return nil, fmt.Errorf("failed to check handle: %w", err)
+	}
+
+	if err := user.ChangeHandle(handle); err != nil {
+		return nil, err
+	}
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		if err == errors.ErrHandleTaken {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return toHandleResponse(user), nil
+}
+
+func toHandleResponse(user *entity.User) *HandleResponse {
+	response := &HandleResponse{Handle: user.Handle.String()}
+	if next := user.NextHandleChangeAt(); next != nil {
+		nextChangeAt := next.Format(time.RFC3339)
+		response.NextChangeAt = &nextChangeAt
+	}
+	return response
+}
+
+// DeleteAccount deletes the user's account after re-confirming the password.
+// Posts remain and are shown as 「削除されたユーザー」; private data is purged
+// and every session is revoked.
+func (uc *UserUsecase) DeleteAccount(ctx context.Context, userID uuid.UUID, input DeleteAccountInput) error {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.Password.Compare(input.Password) {
+		return errors.ErrInvalidCredentials
+	}
+
+	user.Delete()
+	if err := uc.userRepo.Delete(ctx, user); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	// The deleted user can no longer refresh, but revoke right away so that
+	// the session list and audit trail reflect the deletion
+	if err := uc.sessionRepo.RevokeAllByUserID(ctx, user.ID, uuid.Nil, entity.SessionRevokeReasonAccountDelete); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
 func (uc *UserUsecase) GetCurseStyles(ctx context.Context) ([]*CurseStyleResponse, error) {
 	styles, err := uc.curseStyleRepo.FindAll(ctx)
 	if err != nil {
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"noroi/internal/usecase"
+)
+
+const (
+	// exportPollInterval is how often pending exports are checked when there were none.
+	exportPollInterval = 10 * time.Second
+	// exportPurgeInterval is how often expired archives are deleted.
+	exportPurgeInterval = 10 * time.Minute
+)
+
+// DataExporter builds requested data exports and deletes expired archives.
+// Jobs are claimed with FOR UPDATE SKIP LOCKED, so several replicas can run it.
+type DataExporter struct {
+	exports *usecase.DataExportUsecase
+}
+
+func NewDataExporter(exports *usecase.DataExportUsecase) *DataExporter {
+	return &DataExporter{exports: exports}
+}
+
+// Run blocks until ctx is cancelled.
+func (e *DataExporter) Run(ctx context.Context) {
+	var lastPurge time.Time
+	for {
+		if time.Since(lastPurge) >= exportPurgeInterval {
+			if _, err := e.exports.PurgeExpired(ctx); err != nil {
+				log.Printf("data exporter: purge: %v", err)
+			}
+			lastPurge = time.Now()
+		}
+
+		claimed, err := e.exports.ProcessNext(ctx)
+		if err != nil {
+			log.Printf("data exporter: %v", err)
+		}
+
+		// Keep going while jobs are queued
+		if claimed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(exportPollInterval):
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"noroi/internal/usecase"
+)
+
+// mailPollInterval is how often the outbox is checked when it was empty.
+const mailPollInterval = 5 * time.Second
+
+// MailDispatcher drains the mail outbox. Several replicas can run it at the
+// same time; rows are claimed with FOR UPDATE SKIP LOCKED.
+type MailDispatcher struct {
+	mails *usecase.MailUsecase
+}
+
+func NewMailDispatcher(mails *usecase.MailUsecase) *MailDispatcher {
+	return &MailDispatcher{mails: mails}
+}
+
+// Run blocks until ctx is cancelled.
+func (d *MailDispatcher) Run(ctx context.Context) {
+	for {
+		claimed, err := d.mails.DeliverDue(ctx)
+		if err != nil {
+			log.Printf("mail dispatcher: %v", err)
+		}
+
+		// Keep draining while there is a backlog
+		if claimed > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(mailPollInterval):
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"noroi/internal/usecase"
+)
+
+// reminderPollInterval is how often due reminders are checked when none were due.
+const reminderPollInterval = 15 * time.Second
+
+// ReminderDispatcher fires due reminders. Several replicas can run it at the
+// same time; rows are claimed with FOR UPDATE SKIP LOCKED.
+type ReminderDispatcher struct {
+	reminders *usecase.ReminderUsecase
+}
+
+func NewReminderDispatcher(reminders *usecase.ReminderUsecase) *ReminderDispatcher {
+	return &ReminderDispatcher{reminders: reminders}
+}
+
+// Run blocks until ctx is cancelled.
+func (d *ReminderDispatcher) Run(ctx context.Context) {
+	for {
+		claimed, err := d.reminders.DeliverDue(ctx)
+		if err != nil {
+			log.Printf("reminder dispatcher: %v", err)
+		}
+
+		// Keep draining while there is a backlog
+		if claimed > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reminderPollInterval):
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"noroi/internal/usecase"
+)
+
+// RitualAnnouncer sends the "ritual has started" notification every night at
+// RITUAL_START_HOUR (default 2:00) in Japan time.
+type RitualAnnouncer struct {
+	notifications *usecase.NotificationUsecase
+	startHour     int
+	location      *time.Location
+}
+
+func NewRitualAnnouncer(notifications *usecase.NotificationUsecase) *RitualAnnouncer {
+	startHour := 2
+	if v, err := strconv.Atoi(os.Getenv("RITUAL_START_HOUR")); err == nil && v >= 0 && v < 24 {
+		startHour = v
+	}
+
+	location, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		log.Printf("ritual announcer: failed to load Asia/Tokyo, using local time: %v", err)
+		location = time.Local
+	}
+
+	return &RitualAnnouncer{
+		notifications: notifications,
+		startHour:     startHour,
+		location:      location,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (a *RitualAnnouncer) Run(ctx context.Context) {
+	for {
+		next := a.nextStart(time.Now())
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if err := a.notifications.AnnounceRitualStart(ctx, next); err != nil {
+				log.Printf("ritual announcer: %v", err)
+			}
+		}
+	}
+}
+
+func (a *RitualAnnouncer) nextStart(now time.Time) time.Time {
+	now = now.In(a.location)
+	next := time.Date(now.Year(), now.Month(), now.Day(), a.startHour, 0, 0, 0, a.location)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,25 @@
+-- Web Push subscriptions（ブラウザごとの購読情報）
+CREATE TABLE push_subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh TEXT NOT NULL,
+    auth TEXT NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    failure_count INT NOT NULL DEFAULT 0,
+    last_success_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_push_subscriptions_user ON push_subscriptions(user_id);
+
+-- 一斉通知の重複送信防止（複数レプリカで同じ通知を1回だけ送る）
+CREATE TABLE notification_broadcasts (
+    key VARCHAR(100) PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_push_subscriptions_updated_at
+    BEFORE UPDATE ON push_subscriptions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,23 @@
+-- Mail outbox（送信待ちメールの永続キュー）
+CREATE TABLE mail_outbox (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    to_address VARCHAR(255) NOT NULL,
+    template VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    html_body TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP
+);
+
+-- Used by the dispatcher to claim due messages
+CREATE INDEX idx_mail_outbox_pending ON mail_outbox(next_attempt_at) WHERE status = 'pending';
+
+CREATE TRIGGER update_mail_outbox_updated_at
+    BEFORE UPDATE ON mail_outbox
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
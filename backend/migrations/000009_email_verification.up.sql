@@ -0,0 +1,17 @@
+-- メールアドレス確認
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+-- 既存ユーザーは確認済みとして扱う（確認フロー導入前に登録されたため）
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+-- Email verification tokens（ハッシュのみ保存）
+CREATE TABLE email_verification_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX idx_email_verification_tokens_user ON email_verification_tokens(user_id, created_at DESC);
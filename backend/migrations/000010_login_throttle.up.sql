@@ -0,0 +1,23 @@
+-- ログイン失敗カウンター（アカウント単位 / IP 単位）。複数レプリカで共有するため DB に保存する
+CREATE TABLE login_attempt_counters (
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('account', 'ip')),
+    throttle_key VARCHAR(255) NOT NULL,
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    PRIMARY KEY (scope, throttle_key)
+);
+
+-- ログイン履歴（ユーザーが自分のログインを確認できる）
+CREATE TABLE login_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(30),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_events_user ON login_events(user_id, created_at DESC);
@@ -0,0 +1,5 @@
+-- ロールベースのアクセス制御（既存ユーザーは一般ユーザー）
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'moderator', 'admin'));
+
+CREATE INDEX idx_users_role ON users(role) WHERE role <> 'user';
@@ -0,0 +1,26 @@
+-- 個人データのエクスポート（テイクアウト）。ZIP はローカルストレージに保存し、期限付きリンクで配布する
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'processing', 'ready', 'failed', 'expired')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    file_name TEXT,
+    size_bytes BIGINT,
+    download_token_hash CHAR(64) UNIQUE,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX idx_data_exports_user ON data_exports(user_id, created_at DESC);
+-- Used by the worker to claim due jobs
+CREATE INDEX idx_data_exports_due ON data_exports(next_attempt_at) WHERE status IN ('pending', 'processing');
+-- Used by the worker to delete expired archives
+CREATE INDEX idx_data_exports_expires ON data_exports(expires_at) WHERE status = 'ready';
+
+CREATE TRIGGER update_data_exports_updated_at
+    BEFORE UPDATE ON data_exports
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,8 @@
+-- ユーザーごとの設定（キーごとに 1 行、値は JSON）。新しい設定のたびに users へ列を足さないためのストア
+CREATE TABLE user_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL,
+    value JSONB NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, key)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_handle_required;
+DROP INDEX IF EXISTS idx_users_handle;
+ALTER TABLE users DROP COLUMN IF EXISTS handle_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS handle;
@@ -0,0 +1,40 @@
+-- 一意なハンドル（@handle）。表示名の username は重複してよいが、ハンドルでなりすましを防ぐ
+-- 削除済みユーザーはハンドルを解放する（NULL）
+ALTER TABLE users ADD COLUMN handle VARCHAR(20);
+ALTER TABLE users ADD COLUMN handle_changed_at TIMESTAMP;
+
+-- 既存ユーザーへの割り当て: ユーザー名がそのまま使えれば最古のユーザーに、それ以外は ID から生成する
+WITH candidates AS (
+    SELECT
+        id,
+        lower(username) AS candidate,
+        ROW_NUMBER() OVER (PARTITION BY lower(username) ORDER BY created_at, id) AS rn
+    FROM users
+    WHERE is_deleted = FALSE
+)
+UPDATE users u
+SET handle = CASE
+    WHEN c.rn = 1
+        AND c.candidate ~ '^[a-z0-9_]{3,20}$'
+        AND c.candidate ~ '[a-z]'
+        AND c.candidate !~ '^user_[0-9a-f]{12}$'
+        AND c.candidate !~ '(admin|noroi|official|moderator)'
+        AND c.candidate NOT IN (
+            'admin', 'administrator', 'root', 'system', 'support', 'help', 'info', 'contact',
+            'noroi', 'official', 'staff', 'moderator', 'mod', 'security', 'api', 'www', 'mail',
+            'me', 'settings', 'anonymous', 'deleted', 'null', 'undefined', 'everyone', 'here'
+        )
+        THEN c.candidate
+    ELSE 'user_' || substr(replace(u.id::text, '-', ''), 1, 12)
+END
+FROM candidates c
+WHERE c.id = u.id;
+
+CREATE UNIQUE INDEX idx_users_handle ON users(handle) WHERE handle IS NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_handle_required CHECK (is_deleted OR handle IS NOT NULL);
+
+-- 改名が反映されていなかった投稿のユーザー名を揃える
+UPDATE posts p
+SET username = u.username
+FROM users u
+WHERE p.user_id = u.id AND u.is_deleted = FALSE AND p.username <> u.username;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_applications_user_scheduled;
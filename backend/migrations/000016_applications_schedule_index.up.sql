@@ -0,0 +1,4 @@
+-- 応募一覧用の複合インデックス (user_id, scheduled_at)
+-- application_repository_impl.go の List（date / from / to での絞り込みと scheduled_at 順の並び替え）で使う
+CREATE INDEX IF NOT EXISTS idx_applications_user_scheduled
+ON applications(user_id, scheduled_at);
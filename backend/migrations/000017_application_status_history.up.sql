@@ -0,0 +1,14 @@
+-- 応募ステータスの遷移履歴（タイムライン）。from_status が NULL の行は作成時の初期ステータス
+CREATE TABLE application_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    application_id UUID NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) CHECK (from_status IN ('todo', 'scheduled', 'in_progress', 'done', 'withdrawn')),
+    to_status VARCHAR(20) NOT NULL CHECK (to_status IN ('todo', 'scheduled', 'in_progress', 'done', 'withdrawn')),
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_application_status_history_app ON application_status_history(application_id, changed_at);
+
+-- 既存の応募は遷移前の履歴が分からないため、現在のステータスを作成時点の初期ステータスとして記録する
+INSERT INTO application_status_history (application_id, from_status, to_status, changed_at)
+SELECT id, NULL, status, created_at FROM applications;
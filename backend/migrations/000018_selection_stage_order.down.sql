@@ -0,0 +1,3 @@
+ALTER TABLE applications DROP COLUMN IF EXISTS status_from_stages;
+DROP INDEX IF EXISTS idx_selection_stages_app_position;
+ALTER TABLE selection_stages DROP COLUMN IF EXISTS position;
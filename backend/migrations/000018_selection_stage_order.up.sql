@@ -0,0 +1,15 @@
+-- 選考ステップの表示順（ユーザーが並べ替え可能）。既存データは予定日時・作成日時の順で採番する
+ALTER TABLE selection_stages ADD COLUMN position INT NOT NULL DEFAULT 0;
+
+UPDATE selection_stages s
+SET position = ordered.rn - 1
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY application_id ORDER BY scheduled_at NULLS LAST, created_at, id) AS rn
+    FROM selection_stages
+) ordered
+WHERE s.id = ordered.id;
+
+CREATE INDEX idx_selection_stages_app_position ON selection_stages(application_id, position);
+
+-- 選考ステップの結果から応募ステータスを自動で決めるかどうか（応募ごとのオプション）
+ALTER TABLE applications ADD COLUMN status_from_stages BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,12 @@
+ALTER TABLE mail_outbox DROP COLUMN IF EXISTS dedup_key;
+
+DROP INDEX IF EXISTS idx_reminders_pending;
+
+ALTER TABLE reminders
+    DROP COLUMN IF EXISTS acknowledged_at,
+    DROP COLUMN IF EXISTS delivered_at,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS fire_count,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,19 @@
+-- リマインダーの配信状態。next_attempt_at は次に発火する時刻（予定時刻・スヌーズ・再試行・ワーカーのリース期限）
+ALTER TABLE reminders
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'delivered', 'acknowledged', 'failed')),
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN next_attempt_at TIMESTAMP,
+    ADD COLUMN fire_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_error TEXT,
+    ADD COLUMN delivered_at TIMESTAMP,
+    ADD COLUMN acknowledged_at TIMESTAMP;
+
+UPDATE reminders SET next_attempt_at = target_at;
+ALTER TABLE reminders ALTER COLUMN next_attempt_at SET NOT NULL;
+
+-- Used by the reminder dispatcher to claim due reminders
+CREATE INDEX idx_reminders_pending ON reminders(next_attempt_at) WHERE status = 'pending';
+
+-- 同じ発火でメールを二重に積まないための冪等キー（NULL は重複扱いしない）
+ALTER TABLE mail_outbox ADD COLUMN dedup_key VARCHAR(100) UNIQUE;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_selection_stages_app_scheduled;
+
+ALTER TABLE selection_stages
+    DROP COLUMN IF EXISTS travel_buffer_minutes,
+    DROP COLUMN IF EXISTS duration_minutes;
+
+ALTER TABLE applications
+    DROP COLUMN IF EXISTS travel_buffer_minutes,
+    DROP COLUMN IF EXISTS duration_minutes;
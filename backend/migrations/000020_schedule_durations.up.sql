@@ -0,0 +1,11 @@
+-- 予定の所要時間と前後の移動時間（分）。所要時間が未設定の予定は 1 時間とみなして衝突を検出する
+ALTER TABLE applications
+    ADD COLUMN duration_minutes INT CHECK (duration_minutes BETWEEN 1 AND 1440),
+    ADD COLUMN travel_buffer_minutes INT NOT NULL DEFAULT 0 CHECK (travel_buffer_minutes BETWEEN 0 AND 240);
+
+ALTER TABLE selection_stages
+    ADD COLUMN duration_minutes INT CHECK (duration_minutes BETWEEN 1 AND 1440),
+    ADD COLUMN travel_buffer_minutes INT NOT NULL DEFAULT 0 CHECK (travel_buffer_minutes BETWEEN 0 AND 240);
+
+-- Used by GET /schedule/conflicts to find upcoming stages
+CREATE INDEX idx_selection_stages_app_scheduled ON selection_stages(application_id, scheduled_at) WHERE status = 'pending';
@@ -0,0 +1,7 @@
+-- iCalendar 購読フィードの秘密トークン（ユーザーごとに 1 つ）。URL がトークンを兼ねるため、ハッシュのみ保存する
+CREATE TABLE calendar_feeds (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_accessed_at TIMESTAMP
+);
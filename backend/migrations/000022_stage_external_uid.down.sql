@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_selection_stages_external_uid;
+
+ALTER TABLE selection_stages DROP COLUMN IF EXISTS external_uid;
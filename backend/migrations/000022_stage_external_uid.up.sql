@@ -0,0 +1,4 @@
+-- 取り込み元の予定の UID（.ics の UID）。同じファイルを再度取り込んでも選考ステップを重複させない
+ALTER TABLE selection_stages ADD COLUMN external_uid VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_selection_stages_external_uid ON selection_stages(application_id, external_uid) WHERE external_uid IS NOT NULL;
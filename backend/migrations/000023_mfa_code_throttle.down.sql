@@ -0,0 +1,4 @@
+DELETE FROM login_attempt_counters WHERE scope = 'mfa';
+
+ALTER TABLE login_attempt_counters DROP CONSTRAINT login_attempt_counters_scope_check;
+ALTER TABLE login_attempt_counters ADD CONSTRAINT login_attempt_counters_scope_check CHECK (scope IN ('account', 'ip'));
@@ -0,0 +1,3 @@
+-- 二要素認証コードの入力回数もユーザー単位で数える（scope = 'mfa', key = ユーザー ID）
+ALTER TABLE login_attempt_counters DROP CONSTRAINT login_attempt_counters_scope_check;
+ALTER TABLE login_attempt_counters ADD CONSTRAINT login_attempt_counters_scope_check CHECK (scope IN ('account', 'ip', 'mfa'));
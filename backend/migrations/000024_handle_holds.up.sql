@@ -0,0 +1,5 @@
+-- 退会したユーザーのハンドルは一定期間（HandleChangeCooldown）予約し、直後に取得してなりすますことを防ぐ
+CREATE TABLE handle_holds (
+    handle VARCHAR(20) PRIMARY KEY,
+    held_until TIMESTAMP NOT NULL
+);
@@ -0,0 +1,2 @@
+-- 応募の版数。更新のたびに 1 増やし、読み込んだ版と一致するときだけ保存する（楽観的排他制御）
+ALTER TABLE applications ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
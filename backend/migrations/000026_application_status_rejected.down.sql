@@ -0,0 +1,14 @@
+UPDATE applications SET status = 'withdrawn' WHERE status = 'rejected';
+UPDATE application_status_history SET to_status = 'withdrawn' WHERE to_status = 'rejected';
+UPDATE application_status_history SET from_status = 'withdrawn' WHERE from_status = 'rejected';
+
+ALTER TABLE applications DROP CONSTRAINT applications_status_check;
+ALTER TABLE applications ADD CONSTRAINT applications_status_check
+    CHECK (status IN ('todo', 'scheduled', 'in_progress', 'done', 'withdrawn'));
+
+ALTER TABLE application_status_history DROP CONSTRAINT application_status_history_from_status_check;
+ALTER TABLE application_status_history ADD CONSTRAINT application_status_history_from_status_check
+    CHECK (from_status IN ('todo', 'scheduled', 'in_progress', 'done', 'withdrawn'));
+ALTER TABLE application_status_history DROP CONSTRAINT application_status_history_to_status_check;
+ALTER TABLE application_status_history ADD CONSTRAINT application_status_history_to_status_check
+    CHECK (to_status IN ('todo', 'scheduled', 'in_progress', 'done', 'withdrawn'));
@@ -0,0 +1,26 @@
+-- 不合格で終わった応募を辞退（withdrawn）と区別する
+ALTER TABLE applications DROP CONSTRAINT applications_status_check;
+ALTER TABLE applications ADD CONSTRAINT applications_status_check
+    CHECK (status IN ('todo', 'scheduled', 'in_progress', 'done', 'withdrawn', 'rejected'));
+
+ALTER TABLE application_status_history DROP CONSTRAINT application_status_history_from_status_check;
+ALTER TABLE application_status_history ADD CONSTRAINT application_status_history_from_status_check
+    CHECK (from_status IN ('todo', 'scheduled', 'in_progress', 'done', 'withdrawn', 'rejected'));
+ALTER TABLE application_status_history DROP CONSTRAINT application_status_history_to_status_check;
+ALTER TABLE application_status_history ADD CONSTRAINT application_status_history_to_status_check
+    CHECK (to_status IN ('todo', 'scheduled', 'in_progress', 'done', 'withdrawn', 'rejected'));
+
+-- 選考ステップに failed がある withdrawn は、ステップから導かれた不合格として付け替える
+-- withdrawn は終端のため、履歴の withdrawn への遷移は応募ごとに 1 件だけ
+UPDATE application_status_history h
+SET to_status = 'rejected'
+FROM applications a
+WHERE h.application_id = a.id
+    AND h.to_status = 'withdrawn'
+    AND a.status = 'withdrawn'
+    AND EXISTS (SELECT 1 FROM selection_stages s WHERE s.application_id = a.id AND s.status = 'failed');
+
+UPDATE applications a
+SET status = 'rejected'
+WHERE a.status = 'withdrawn'
+    AND EXISTS (SELECT 1 FROM selection_stages s WHERE s.application_id = a.id AND s.status = 'failed');
@@ -0,0 +1,14 @@
+-- アプリ内通知の受信箱。プッシュ通知はベストエフォートのため、アプリ内で見るべき通知はここに残す
+-- dedup_key はリマインダーの発火ごとの冪等キー（再試行やワーカーの重複実行で二重に積まない）
+CREATE TABLE notification_inbox (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    dedup_key VARCHAR(100) UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notification_inbox_user ON notification_inbox(user_id, created_at DESC);
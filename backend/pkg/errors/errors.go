@@ -10,6 +10,12 @@ var (
 	ErrInvalidUsername  = errors.New("invalid username format")
 	ErrUsernameTooLong  = errors.New("username must be 50 characters or less")
 
+	// Password strength errors
+	ErrPasswordTooLong              = errors.New("password must be 128 characters or less")
+	ErrPasswordTooSimple            = errors.New("password must combine at least two of letters, digits and symbols and must not be a repeated or sequential pattern")
+	ErrPasswordTooCommon            = errors.New("password is too common")
+	ErrPasswordContainsPersonalInfo = errors.New("password must not contain your email address or username")
+
 	// Post errors
 	ErrPostTooShort     = errors.New("post content must be at least 10 characters")
 	ErrPostTooLong      = errors.New("post content must be 300 characters or less")
@@ -32,6 +38,50 @@ var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrTokenExpired = errors.New("token expired")
 
+	// Email verification errors
+	ErrEmailNotVerified     = errors.New("email address is not verified")
+	ErrEmailAlreadyVerified = errors.New("email address is already verified")
+	ErrTooManyRequests      = errors.New("too many requests")
+
+	// MFA errors
+	ErrMFANotEnrolled    = errors.New("two-factor authentication enrolment not started")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrInvalidMFACode    = errors.New("invalid two-factor authentication code")
+
+	// Role errors
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrCannotChangeOwnRole = errors.New("cannot change own role")
+
+	// Profile errors
+	ErrProfilePrivate      = errors.New("profile is private")
+	ErrInvalidHandle       = errors.New("handle must be 3-20 characters of a-z, 0-9 and _ with at least one letter")
+	ErrHandleReserved      = errors.New("handle is reserved")
+	ErrHandleTaken         = errors.New("handle is already taken")
+	ErrHandleChangeTooSoon = errors.New("handle was changed recently")
+
+	// Settings errors
+	ErrInvalidDigestFrequency = errors.New("invalid digest frequency")
+	ErrInvalidQuietHours      = errors.New("invalid quiet hours, expected start and end as HH:MM")
+	ErrInvalidLocale          = errors.New("invalid locale")
+	ErrInvalidTimezone        = errors.New("invalid timezone")
+
+	// Application list errors
+	ErrInvalidApplicationCategory = errors.New("invalid category")
+	ErrInvalidApplicationStatus   = errors.New("invalid status")
+	ErrInvalidSort                = errors.New("invalid sort")
+	ErrInvalidDate                = errors.New("invalid date")
+	ErrInvalidFrom                = errors.New("invalid from")
+	ErrInvalidTo                  = errors.New("invalid to")
+	ErrInvalidDateRange           = errors.New("invalid range: from must be before to")
+
+	// Application errors
+	ErrApplicationConflict = errors.New("application was changed by another request; reload and try again")
+
+	// Session errors
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+
 	// Repository errors
 	ErrNotFound           = errors.New("not found")
 	ErrAlreadyExists      = errors.New("already exists")
@@ -41,4 +91,20 @@ var (
 	ErrCurseStyleNotFound = errors.New("curse style not found")
 	ErrEmailAlreadyExists = errors.New("email already exists")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// Push notification errors
+	ErrInvalidPushSubscription = errors.New("invalid push subscription")
+	ErrPushSubscriptionExpired = errors.New("push subscription expired")
+	ErrPushTemporaryFailure    = errors.New("push service temporarily unavailable")
+	ErrPushDisabled            = errors.New("push notifications are not configured")
 )
+
+// Is reports whether any error in err's chain matches target.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target.
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
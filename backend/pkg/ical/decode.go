@@ -0,0 +1,307 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotCalendar is returned when the input has no VCALENDAR object
+var ErrNotCalendar = errors.New("not an iCalendar file")
+
+const (
+	// maxContentLine bounds one unfolded content line
+	maxContentLine = 64 * 1024
+
+	dateLayout = "20060102"
+)
+
+// Decode reads the VEVENTs of an iCalendar stream. Times without a zone
+// ("floating") are read in loc, as are times whose TZID is neither a known
+// zone nor described by a VTIMEZONE in the file. An event without a usable
+// DTSTART is returned with a zero Start for the caller to report.
+func Decode(r io.Reader, loc *time.Location) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoder{loc: loc, offsets: make(map[string]*time.Location)}
+	d.readTimezones(lines)
+
+	calendar := &Calendar{}
+	found := false
+	var (
+		event    *Event
+		duration string
+		depth    int // components nested in the current VEVENT (VALARM)
+	)
+	for _, line := range lines {
+		name, params, value := parseLine(line)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCALENDAR"):
+			found = true
+		case name == "BEGIN" && event == nil && strings.EqualFold(value, "VEVENT"):
+			event = &Event{}
+			duration = ""
+		case name == "BEGIN" && event != nil:
+			depth++
+		case name == "END" && event != nil && depth > 0:
+			depth--
+		case name == "END" && event != nil && strings.EqualFold(value, "VEVENT"):
+			if event.End.IsZero() && !event.Start.IsZero() {
+				event.End = event.Start
+				if d, ok := parseDuration(duration); ok {
+					event.End = event.Start.Add(d)
+				} else if event.AllDay {
+					event.End = event.Start.AddDate(0, 0, 1)
+				}
+			}
+			calendar.Events = append(calendar.Events, *event)
+			event = nil
+		case event != nil && depth == 0:
+			d.property(event, name, params, value, &duration)
+		case name == "X-WR-CALNAME" && event == nil:
+			calendar.Name = unescapeText(value)
+		}
+	}
+
+	if !found {
+		return nil, ErrNotCalendar
+	}
+	return calendar, nil
+}
+
+type decoder struct {
+	loc *time.Location
+	// offsets holds the zones described by VTIMEZONE components, by TZID
+	offsets map[string]*time.Location
+}
+
+func (d *decoder) property(event *Event, name string, params map[string]string, value string, duration *string) {
+	switch name {
+	case "UID":
+		event.UID = value
+	case "SUMMARY":
+		event.Summary = unescapeText(value)
+	case "DESCRIPTION":
+		event.Description = unescapeText(value)
+	case "LOCATION":
+		event.Location = unescapeText(value)
+	case "URL":
+		event.URL = value
+	case "STATUS":
+		event.Status = strings.ToUpper(value)
+	case "ORGANIZER":
+		event.Organizer = params["CN"]
+	case "DTSTART":
+		event.Start, event.AllDay = d.parseTime(params, value)
+	case "DTEND":
+		event.End, _ = d.parseTime(params, value)
+	case "DURATION":
+		*duration = value
+	case "LAST-MODIFIED":
+		event.LastModified, _ = d.parseTime(params, value)
+	}
+}
+
+// parseTime parses a DATE or DATE-TIME value; a zero time means it was not understood
+func (d *decoder) parseTime(params map[string]string, value string) (time.Time, bool) {
+	if params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, d.loc)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t, true
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcLayout, value)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t, false
+	}
+
+	loc := d.loc
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		} else if l, ok := d.offsets[tzid]; ok {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(localLayout, value, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, false
+}
+
+// readTimezones records the standard offset of each VTIMEZONE, so that zones
+// named in other ways than the tz database (e.g. "Tokyo Standard Time" from
+// Outlook) still resolve. Daylight saving rules are not evaluated.
+func (d *decoder) readTimezones(lines []string) {
+	var (
+		tzid     string
+		inZone   bool
+		standard bool
+	)
+	for _, line := range lines {
+		name, _, value := parseLine(line)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VTIMEZONE"):
+			inZone, tzid = true, ""
+		case name == "END" && strings.EqualFold(value, "VTIMEZONE"):
+			inZone = false
+		case !inZone:
+		case name == "TZID":
+			tzid = value
+		case name == "BEGIN":
+			standard = strings.EqualFold(value, "STANDARD")
+		case name == "TZOFFSETTO" && tzid != "":
+			offset, ok := parseOffset(value)
+			if !ok {
+				continue
+			}
+			if _, seen := d.offsets[tzid]; !seen || standard {
+				d.offsets[tzid] = time.FixedZone(tzid, offset)
+			}
+		}
+	}
+}
+
+// unfold reads content lines, joining folded continuation lines (RFC 5545 3.1)
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxContentLine)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read iCalendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseLine splits "NAME;PARAM=x;PARAM="y":value". Parameter names and the
+// property name are upper-cased; quoted parameter values may contain ':' and ';'.
+func parseLine(line string) (name string, params map[string]string, value string) {
+	inQuote := false
+	end := len(line)
+	for i, r := range line {
+		if r == '"' {
+			inQuote = !inQuote
+		}
+		if r == ':' && !inQuote {
+			end = i
+			break
+		}
+	}
+	head := line[:end]
+	if end < len(line) {
+		value = line[end+1:]
+	}
+
+	parts := splitUnquoted(head, ';')
+	name = strings.ToUpper(parts[0])
+	params = make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		key, val, _ := strings.Cut(part, "=")
+		params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+	return name, params, value
+}
+
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	inQuote := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == sep && !inQuote:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
+
+// parseDuration parses a DURATION value such as "PT1H30M" or "P1D"
+func parseDuration(s string) (time.Duration, bool) {
+	s = strings.TrimPrefix(strings.ToUpper(s), "+")
+	if s == "" || strings.HasPrefix(s, "-") {
+		return 0, false
+	}
+	s, ok := strings.CutPrefix(s, "P")
+	if !ok {
+		return 0, false
+	}
+
+	units := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour,
+		'H': time.Hour, 'M': time.Minute, 'S': time.Second,
+	}
+	var total time.Duration
+	number := ""
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 'T':
+		case c >= '0' && c <= '9':
+			number += string(c)
+		default:
+			unit, known := units[c]
+			n, err := strconv.Atoi(number)
+			if !known || err != nil {
+				return 0, false
+			}
+			total += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	return total, number == "" && total > 0
+}
+
+// parseOffset parses a UTC offset such as "+0900" or "-0330" into seconds
+func parseOffset(s string) (int, bool) {
+	if len(s) != 5 && len(s) != 7 {
+		return 0, false
+	}
+	sign := 1
+	switch s[0] {
+	case '+':
+	case '-':
+		sign = -1
+	default:
+		return 0, false
+	}
+	hours, err1 := strconv.Atoi(s[1:3])
+	minutes, err2 := strconv.Atoi(s[3:5])
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	return sign * (hours*3600 + minutes*60), true
+}
@@ -0,0 +1,240 @@
+// Package ical writes iCalendar (RFC 5545) calendars with the subset of
+// components calendar apps need to subscribe to a feed: VEVENT, VALARM and
+// the VTIMEZONE describing the times written with a TZID. Decode reads the
+// events back from files such as interview invitations.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxLineOctets is the longest content line before folding (RFC 5545 3.1)
+	maxLineOctets = 75
+
+	utcLayout   = "20060102T150405Z"
+	localLayout = "20060102T150405"
+
+	// maxTimezoneTransitions bounds the VTIMEZONE of a zone that changes offset often
+	maxTimezoneTransitions = 200
+)
+
+// Event status values
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Calendar is one VCALENDAR object
+type Calendar struct {
+	ProdID string
+	Name   string // X-WR-CALNAME, shown by most apps as the subscription name
+	// Location is the zone events are written in; nil or UTC writes UTC times
+	Location *time.Location
+	Events   []Event
+}
+
+// Event is a VEVENT with a start and end time
+type Event struct {
+	UID          string // must stay the same across feed refreshes
+	Start        time.Time
+	End          time.Time
+	AllDay       bool // Start and End are dates (midnight in the calendar's zone)
+	Summary      string
+	Description  string
+	Location     string
+	Organizer    string // common name of the ORGANIZER; only read by Decode
+	URL          string
+	Status       string
+	LastModified time.Time // also used as DTSTAMP, so unchanged events serialise identically
+	Alarms       []Alarm
+}
+
+// Alarm is a display VALARM firing at an absolute time
+type Alarm struct {
+	Trigger     time.Time
+	Description string
+}
+
+// Encode writes the calendar to w with CRLF line endings
+func (c *Calendar) Encode(w io.Writer) error {
+	e := &encoder{w: bufio.NewWriter(w), loc: c.Location}
+	if e.loc == time.UTC {
+		e.loc = nil
+	}
+
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", c.ProdID)
+	e.line("CALSCALE", "GREGORIAN")
+	e.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		e.line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	if e.loc != nil {
+		e.line("X-WR-TIMEZONE", e.loc.String())
+		if from, until, ok := c.span(); ok {
+			e.timezone(from, until)
+		}
+	}
+	for i := range c.Events {
+		e.event(&c.Events[i])
+	}
+	e.line("END", "VCALENDAR")
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// span returns the earliest start and latest end of the events
+func (c *Calendar) span() (from, until time.Time, ok bool) {
+	for i, event := range c.Events {
+		if i == 0 || event.Start.Before(from) {
+			from = event.Start
+		}
+		if i == 0 || event.End.After(until) {
+			until = event.End
+		}
+	}
+	return from, until, len(c.Events) > 0
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	loc *time.Location
+	err error
+}
+
+func (e *encoder) event(event *Event) {
+	e.line("BEGIN", "VEVENT")
+	e.line("UID", event.UID)
+	e.line("DTSTAMP", event.LastModified.UTC().Format(utcLayout))
+	if event.AllDay {
+		e.line("DTSTART;VALUE=DATE", event.Start.Format(dateLayout))
+		e.line("DTEND;VALUE=DATE", event.End.Format(dateLayout))
+	} else {
+		e.time("DTSTART", event.Start)
+		e.time("DTEND", event.End)
+	}
+	e.line("SUMMARY", escapeText(event.Summary))
+	if event.Description != "" {
+		e.line("DESCRIPTION", escapeText(event.Description))
+	}
+	if event.Location != "" {
+		e.line("LOCATION", escapeText(event.Location))
+	}
+	if event.URL != "" {
+		e.line("URL", event.URL)
+	}
+	if event.Status != "" {
+		e.line("STATUS", event.Status)
+	}
+	e.line("LAST-MODIFIED", event.LastModified.UTC().Format(utcLayout))
+	for _, alarm := range event.Alarms {
+		e.line("BEGIN", "VALARM")
+		e.line("ACTION", "DISPLAY")
+		e.line("TRIGGER;VALUE=DATE-TIME", alarm.Trigger.UTC().Format(utcLayout))
+		e.line("DESCRIPTION", escapeText(alarm.Description))
+		e.line("END", "VALARM")
+	}
+	e.line("END", "VEVENT")
+}
+
+// time writes a DATE-TIME in the calendar's zone, or in UTC without one
+func (e *encoder) time(name string, t time.Time) {
+	if e.loc == nil {
+		e.line(name, t.UTC().Format(utcLayout))
+		return
+	}
+	e.line(name+";TZID="+e.loc.String(), t.In(e.loc).Format(localLayout))
+}
+
+// timezone writes a VTIMEZONE with one observance per offset period between
+// from and until. Each onset is written without RRULE, which RFC 5545 allows
+// and which avoids guessing the zone's rules from the tz database.
+func (e *encoder) timezone(from, until time.Time) {
+	e.line("BEGIN", "VTIMEZONE")
+	e.line("TZID", e.loc.String())
+
+	t := from.In(e.loc)
+	for i := 0; i < maxTimezoneTransitions; i++ {
+		name, offset := t.Zone()
+		start, end := t.ZoneBounds()
+
+		prevOffset := offset
+		onset := "19700101T000000"
+		if !start.IsZero() {
+			_, prevOffset = start.Add(-time.Second).Zone()
+			onset = start.In(time.FixedZone("", prevOffset)).Format(localLayout)
+		}
+
+		kind := "STANDARD"
+		if t.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		e.line("BEGIN", kind)
+		e.line("DTSTART", onset)
+		e.line("TZOFFSETFROM", formatOffset(prevOffset))
+		e.line("TZOFFSETTO", formatOffset(offset))
+		e.line("TZNAME", escapeText(name))
+		e.line("END", kind)
+
+		if end.IsZero() || !end.Before(until) {
+			break
+		}
+		t = end.In(e.loc)
+	}
+
+	e.line("END", "VTIMEZONE")
+}
+
+// line writes a content line, folding it after 75 octets without splitting a character
+func (e *encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+	s := name + ":" + value
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		size := utf8.RuneLen(r)
+		if width+size > maxLineOctets {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+	_, e.err = e.w.WriteString(b.String())
+}
+
+// escapeText escapes a TEXT value (RFC 5545 3.3.11)
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+}
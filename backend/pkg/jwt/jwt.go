@@ -2,7 +2,6 @@ package jwt
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -10,68 +9,98 @@ import (
 )
 
 const (
-	// AccessTokenDuration is the expiration time for access tokens (24 hours)
-	AccessTokenDuration = 24 * time.Hour
-	// RefreshTokenDuration is the expiration time for refresh tokens (7 days)
-	RefreshTokenDuration = 7 * 24 * time.Hour
+	// AccessTokenDuration is the expiration time for access tokens (15 minutes).
+	// Access tokens are stateless, so this bounds how long a revoked session keeps working.
+	AccessTokenDuration = 15 * time.Minute
+)
+
+// TokenType distinguishes tokens signed by the same keyset, so that a token
+// issued for one purpose is never accepted for another.
+type TokenType string
+
+const (
+	TokenTypeAccess TokenType = "access"
+	// TokenTypeMFAChallenge proves the password step of a two-step login
+	TokenTypeMFAChallenge TokenType = "mfa_challenge"
 )
 
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	SessionID uuid.UUID `json:"sid"`
+	Type      TokenType `json:"typ"`
+	// Role and Permissions are set on access tokens only. They are a snapshot
+	// taken when the token was issued and are refreshed with the token.
+	Role        string   `json:"role,omitempty"`
+	Permissions []string `json:"perms,omitempty"`
 	jwt.RegisteredClaims
 }
 
-type TokenPair struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-}
-
 type Manager struct {
-	secretKey string
-}
-
-func NewManager() *Manager {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "noroi-secret-key-change-in-production" // Default for development
-	}
-	return &Manager{
-		secretKey: secret,
-	}
+	issuer   string
+	audience string
+	keys     []*key // keys[0] signs; all keys verify
+	byID     map[string]*key
 }
 
-// GenerateTokenPair generates both access and refresh tokens for a user
-func (m *Manager) GenerateTokenPair(userID uuid.UUID) (*TokenPair, error) {
-	accessToken, err := m.GenerateToken(userID, AccessTokenDuration)
+// NewManager loads the keyset described by cfg. It fails when no usable
+// signing key is configured, including the default secret outside dev mode.
+func NewManager(cfg *Config) (*Manager, error) {
+	keys, err := loadKeys(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		return nil, err
 	}
 
-	refreshToken, err := m.GenerateToken(userID, RefreshTokenDuration)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	byID := make(map[string]*key, len(keys))
+	for _, k := range keys {
+		byID[k.id] = k
 	}
 
-	return &TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+	return &Manager{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		keys:     keys,
+		byID:     byID,
 	}, nil
 }
 
-// GenerateToken generates a JWT token for the given user ID and duration
-func (m *Manager) GenerateToken(userID uuid.UUID, duration time.Duration) (string, error) {
+// GenerateAccessToken generates an access token bound to a login session,
+// carrying the user's role and permissions.
+// Refresh tokens are opaque and managed by the session store, not by this package.
+func (m *Manager) GenerateAccessToken(userID, sessionID uuid.UUID, role string, permissions []string) (string, error) {
+	claims := m.newClaims(TokenTypeAccess, userID, sessionID, AccessTokenDuration)
+	claims.Role = role
+	claims.Permissions = permissions
+	return m.sign(claims)
+}
+
+// GenerateToken generates a JWT of the given type for the user, session and duration
+func (m *Manager) GenerateToken(typ TokenType, userID, sessionID uuid.UUID, duration time.Duration) (string, error) {
+	return m.sign(m.newClaims(typ, userID, sessionID, duration))
+}
+
+func (m *Manager) newClaims(typ TokenType, userID, sessionID uuid.UUID, duration time.Duration) *Claims {
 	now := time.Now()
-	claims := &Claims{
-		UserID: userID,
+	return &Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		Type:      typ,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    m.issuer,
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{m.audience},
 			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(m.secretKey))
+func (m *Manager) sign(claims *Claims) (string, error) {
+	signing := m.keys[0]
+	token := jwt.NewWithClaims(signing.method, claims)
+	token.Header["kid"] = signing.id
+	signedToken, err := token.SignedString(signing.signKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -79,15 +108,30 @@ func (m *Manager) GenerateToken(userID uuid.UUID, duration time.Duration) (strin
 	return signedToken, nil
 }
 
-// ValidateToken validates the given token and returns the claims
-func (m *Manager) ValidateToken(tokenString string) (*Claims, error) {
+// ValidateAccessToken validates an access token and returns its claims
+func (m *Manager) ValidateAccessToken(tokenString string) (*Claims, error) {
+	return m.ValidateToken(tokenString, TokenTypeAccess)
+}
+
+// ValidateToken validates the given token, requiring it to be of type typ,
+// and returns the claims
+func (m *Manager) ValidateToken(tokenString string, typ TokenType) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		k, ok := m.byID[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id: %q", kid)
+		}
+		// Verify the signing method matches the key, preventing algorithm confusion
+		if token.Method.Alg() != k.method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(m.secretKey), nil
-	})
+		return k.verifyKey, nil
+	},
+		jwt.WithIssuer(m.issuer),
+		jwt.WithAudience(m.audience),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -97,22 +141,21 @@ func (m *Manager) ValidateToken(tokenString string) (*Claims, error) {
 	if !ok || !token.Valid {
 		return nil, fmt.Errorf("invalid token")
 	}
+	if claims.Type != typ {
+		return nil, fmt.Errorf("unexpected token type: %q", claims.Type)
+	}
 
 	return claims, nil
 }
 
-// RefreshAccessToken validates the refresh token and generates a new access token
-func (m *Manager) RefreshAccessToken(refreshToken string) (string, error) {
-	claims, err := m.ValidateToken(refreshToken)
-	if err != nil {
-		return "", fmt.Errorf("invalid refresh token: %w", err)
-	}
-
-	// Generate a new access token
-	accessToken, err := m.GenerateToken(claims.UserID, AccessTokenDuration)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate new access token: %w", err)
+// JWKS returns the public keys that verify tokens. It is empty for HS256,
+// whose keys are shared secrets.
+func (m *Manager) JWKS() JWKS {
+	var set JWKS
+	for _, k := range m.keys {
+		if k.jwk != nil {
+			set.Keys = append(set.Keys, *k.jwk)
+		}
 	}
-
-	return accessToken, nil
+	return set
 }
@@ -0,0 +1,277 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+)
+
+// minSecretLength is the shortest HS256 secret accepted outside dev mode
+const minSecretLength = 32
+
+// defaultSecrets are placeholder values that must never sign production tokens
+var defaultSecrets = map[string]bool{
+	"noroi-secret-key-change-in-production": true,
+	"your-secret-key-change-in-production":  true,
+}
+
+// Config describes the signing keyset.
+//
+// Rotation: move the current secret (or public key) into the Previous* list and
+// configure a new current key. Tokens signed with a previous key keep validating
+// until they expire, while new tokens are signed with the current key.
+type Config struct {
+	Algorithm string
+	Issuer    string
+	Audience  string
+	DevMode   bool
+
+	// HS256
+	Secret          string
+	PreviousSecrets []string
+
+	// EdDSA / RS256 (PEM files)
+	PrivateKeyFile         string
+	PreviousPublicKeyFiles []string
+}
+
+func NewConfig() *Config {
+	env := strings.ToLower(os.Getenv("ENV"))
+	return &Config{
+		Algorithm:              getEnv("JWT_ALGORITHM", AlgorithmHS256),
+		Issuer:                 getEnv("JWT_ISSUER", "noroi"),
+		Audience:               getEnv("JWT_AUDIENCE", "noroi-api"),
+		DevMode:                env == "development" || env == "dev",
+		Secret:                 os.Getenv("JWT_SECRET"),
+		PreviousSecrets:        splitList(os.Getenv("JWT_PREVIOUS_SECRETS")),
+		PrivateKeyFile:         os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		PreviousPublicKeyFiles: splitList(os.Getenv("JWT_PREVIOUS_PUBLIC_KEY_FILES")),
+	}
+}
+
+// key is a single entry of the keyset, identified by its kid
+type key struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{} // nil for verification-only keys
+	verifyKey interface{}
+	jwk       *JWK // nil for symmetric keys, which are never published
+}
+
+// loadKeys builds the keyset. The first key is the current signing key.
+func loadKeys(cfg *Config) ([]*key, error) {
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		return loadHMACKeys(cfg)
+	case AlgorithmEdDSA, AlgorithmRS256:
+		return loadAsymmetricKeys(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q", cfg.Algorithm)
+	}
+}
+
+func loadHMACKeys(cfg *Config) ([]*key, error) {
+	secret := cfg.Secret
+	if secret == "" || defaultSecrets[secret] {
+		if !cfg.DevMode {
+			return nil, fmt.Errorf("JWT_SECRET is not set or uses the default value; refusing to start outside dev mode")
+		}
+		secret = "noroi-secret-key-change-in-production" // Default for development
+	} else if len(secret) < minSecretLength && !cfg.DevMode {
+		return nil, fmt.Errorf("JWT_SECRET must be at least %d bytes", minSecretLength)
+	}
+
+	secrets := append([]string{secret}, cfg.PreviousSecrets...)
+	keys := make([]*key, 0, len(secrets))
+	for i, s := range secrets {
+		k := &key{
+			id:        hmacKeyID(s),
+			method:    jwt.SigningMethodHS256,
+			verifyKey: []byte(s),
+		}
+		if i == 0 {
+			k.signKey = []byte(s)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func loadAsymmetricKeys(cfg *Config) ([]*key, error) {
+	if cfg.PrivateKeyFile == "" {
+		return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE is required for %s", cfg.Algorithm)
+	}
+	pem, err := os.ReadFile(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+	}
+
+	var current *key
+	switch cfg.Algorithm {
+	case AlgorithmEdDSA:
+		priv, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT private key: %w", err)
+		}
+		signer, ok := priv.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("JWT private key is not an Ed25519 key")
+		}
+		current, err = publicKey(cfg.Algorithm, signer.Public())
+		if err != nil {
+			return nil, err
+		}
+		current.signKey = signer
+	case AlgorithmRS256:
+		priv, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT private key: %w", err)
+		}
+		current, err = publicKey(cfg.Algorithm, &priv.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		current.signKey = priv
+	}
+
+	keys := []*key{current}
+	for _, path := range cfg.PreviousPublicKeyFiles {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT public key %s: %w", path, err)
+		}
+
+		var pub crypto.PublicKey
+		switch cfg.Algorithm {
+		case AlgorithmEdDSA:
+			pub, err = jwt.ParseEdPublicKeyFromPEM(pem)
+		case AlgorithmRS256:
+			pub, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT public key %s: %w", path, err)
+		}
+
+		k, err := publicKey(cfg.Algorithm, pub)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// publicKey builds a verification key and its JWK. The kid is the RFC 7638 thumbprint.
+func publicKey(algorithm string, pub crypto.PublicKey) (*key, error) {
+	var (
+		method     jwt.SigningMethod
+		jwk        *JWK
+		thumbprint string
+	)
+	switch p := pub.(type) {
+	case ed25519.PublicKey:
+		if algorithm != AlgorithmEdDSA {
+			return nil, fmt.Errorf("Ed25519 key cannot be used with %s", algorithm)
+		}
+		x := base64.RawURLEncoding.EncodeToString(p)
+		method = jwt.SigningMethodEdDSA
+		jwk = &JWK{KeyType: "OKP", Curve: "Ed25519", X: x}
+		thumbprint = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, x)
+	case *rsa.PublicKey:
+		if algorithm != AlgorithmRS256 {
+			return nil, fmt.Errorf("RSA key cannot be used with %s", algorithm)
+		}
+		if p.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA key must be at least 2048 bits")
+		}
+		n := base64.RawURLEncoding.EncodeToString(p.N.Bytes())
+		e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.E)).Bytes())
+		method = jwt.SigningMethodRS256
+		jwk = &JWK{KeyType: "RSA", N: n, E: e}
+		thumbprint = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, e, n)
+	default:
+		return nil, fmt.Errorf("unsupported JWT public key type %T", pub)
+	}
+
+	sum := sha256.Sum256([]byte(thumbprint))
+	jwk.KeyID = base64.RawURLEncoding.EncodeToString(sum[:])
+	jwk.Use = "sig"
+	jwk.Algorithm = algorithm
+
+	return &key{
+		id:        jwk.KeyID,
+		method:    method,
+		verifyKey: pub,
+		jwk:       jwk,
+	}, nil
+}
+
+// hmacKeyID derives a stable kid from a secret without revealing it
+func hmacKeyID(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("noroi-jwt-kid"))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// MarshalJSON keeps "keys" an array even when the set is empty
+func (s JWKS) MarshalJSON() ([]byte, error) {
+	keys := s.Keys
+	if keys == nil {
+		keys = []JWK{}
+	}
+	return json.Marshal(struct {
+		Keys []JWK `json:"keys"`
+	}{keys})
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,206 @@
+// Package passwordhash hashes passwords with a versioned scheme. The scheme is
+// identified by the prefix of the encoded hash, so hashes created by older
+// schemes keep verifying while new hashes use the current default (Argon2id).
+package passwordhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Scheme names a hashing algorithm
+type Scheme string
+
+const (
+	SchemeArgon2id Scheme = "argon2id"
+	SchemeBcrypt   Scheme = "bcrypt" // legacy; verified but never used for new hashes
+)
+
+// Argon2Params are the Argon2id cost parameters. Defaults follow the OWASP
+// recommendation (19 MiB, 2 iterations, 1 lane).
+type Argon2Params struct {
+	MemoryKiB   uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+var DefaultArgon2Params = Argon2Params{
+	MemoryKiB:   19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+type Config struct {
+	Argon2 Argon2Params
+}
+
+// NewConfig reads PASSWORD_ARGON2_MEMORY_KIB, PASSWORD_ARGON2_ITERATIONS and
+// PASSWORD_ARGON2_PARALLELISM, falling back to DefaultArgon2Params.
+func NewConfig() (*Config, error) {
+	params := DefaultArgon2Params
+
+	if v := os.Getenv("PASSWORD_ARGON2_MEMORY_KIB"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || n < 8*1024 {
+			return nil, fmt.Errorf("PASSWORD_ARGON2_MEMORY_KIB must be an integer >= 8192")
+		}
+		params.MemoryKiB = uint32(n)
+	}
+	if v := os.Getenv("PASSWORD_ARGON2_ITERATIONS"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("PASSWORD_ARGON2_ITERATIONS must be a positive integer")
+		}
+		params.Iterations = uint32(n)
+	}
+	if v := os.Getenv("PASSWORD_ARGON2_PARALLELISM"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 8)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("PASSWORD_ARGON2_PARALLELISM must be an integer between 1 and 255")
+		}
+		params.Parallelism = uint8(n)
+	}
+
+	return &Config{Argon2: params}, nil
+}
+
+var (
+	mu      sync.RWMutex
+	current = DefaultArgon2Params
+)
+
+// Configure sets the parameters used for new hashes. Existing hashes created
+// with other parameters keep verifying and are reported by NeedsRehash.
+func Configure(cfg *Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = cfg.Argon2
+}
+
+func params() Argon2Params {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Hash hashes plain with the current default scheme, in PHC string format:
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+func Hash(plain string) (string, error) {
+	p := params()
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(plain), salt, p.Iterations, p.MemoryKiB, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.MemoryKiB, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify reports whether plain matches encoded, whichever scheme created it
+func Verify(encoded, plain string) bool {
+	switch schemeOf(encoded) {
+	case SchemeArgon2id:
+		h, err := parseArgon2(encoded)
+		if err != nil {
+			return false
+		}
+		key := argon2.IDKey([]byte(plain), h.salt, h.params.Iterations, h.params.MemoryKiB, h.params.Parallelism, uint32(len(h.key)))
+		return subtle.ConstantTimeCompare(key, h.key) == 1
+	case SchemeBcrypt:
+		return bcrypt.CompareHashAndPassword([]byte(encoded), []byte(plain)) == nil
+	default:
+		return false
+	}
+}
+
+// VerifyDummy spends the same time as verifying against a current hash. Call
+// it when no account matches so that timing does not reveal which accounts exist.
+func VerifyDummy(plain string) {
+	p := params()
+	salt := make([]byte, p.SaltLength)
+	_ = argon2.IDKey([]byte(plain), salt, p.Iterations, p.MemoryKiB, p.Parallelism, p.KeyLength)
+}
+
+// NeedsRehash reports whether encoded was created by a legacy scheme or with
+// parameters other than the current ones
+func NeedsRehash(encoded string) bool {
+	if schemeOf(encoded) != SchemeArgon2id {
+		return true
+	}
+	h, err := parseArgon2(encoded)
+	if err != nil {
+		return true
+	}
+	p := params()
+	return h.params.MemoryKiB != p.MemoryKiB ||
+		h.params.Iterations != p.Iterations ||
+		h.params.Parallelism != p.Parallelism ||
+		uint32(len(h.key)) != p.KeyLength
+}
+
+func schemeOf(encoded string) Scheme {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return SchemeArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return SchemeBcrypt
+	default:
+		return ""
+	}
+}
+
+type argon2Hash struct {
+	params Argon2Params
+	salt   []byte
+	key    []byte
+}
+
+func parseArgon2(encoded string) (*argon2Hash, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2 version")
+	}
+
+	var h argon2Hash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.params.MemoryKiB, &h.params.Iterations, &h.params.Parallelism); err != nil {
+		return nil, fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("malformed argon2id salt: %w", err)
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, fmt.Errorf("malformed argon2id key: %w", err)
+	}
+	if len(h.key) == 0 || h.params.Parallelism == 0 {
+		return nil, fmt.Errorf("malformed argon2id hash")
+	}
+	h.params.SaltLength = uint32(len(h.salt))
+	h.params.KeyLength = uint32(len(h.key))
+
+	return &h, nil
+}
@@ -0,0 +1,54 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// secretSize is the number of random bytes in a generated token (256 bits)
+const secretSize = 32
+
+// Generate returns a random URL-safe opaque token and its hash.
+// Only the hash should be persisted; the plain token is handed to the client once.
+func Generate() (plain string, hash string, err error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	plain = base64.RawURLEncoding.EncodeToString(b)
+	return plain, Hash(plain), nil
+}
+
+// Hash returns the hex-encoded SHA-256 of a token. Tokens carry 256 bits of
+// entropy, so a fast unsalted hash is sufficient for lookup by hash.
+func Hash(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// recoveryCodeSize is the number of random bytes in a recovery code (80 bits)
+const recoveryCodeSize = 10
+
+// GenerateRecoveryCode returns a human-typeable one-time code such as
+// "abcd-efgh-ijkl-mnop" and its hash.
+func GenerateRecoveryCode() (plain string, hash string, err error) {
+	b := make([]byte, recoveryCodeSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	encoded := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	plain = encoded[0:4] + "-" + encoded[4:8] + "-" + encoded[8:12] + "-" + encoded[12:16]
+	return plain, HashRecoveryCode(plain), nil
+}
+
+// HashRecoveryCode hashes a recovery code, ignoring case, spaces and hyphens
+// so that users can type it however it was displayed.
+func HashRecoveryCode(plain string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(plain))
+	return Hash(normalized)
+}